@@ -1,5 +1,6 @@
 import { createContext, useContext, useReducer, useEffect, useRef } from 'react'
 import type { ReactNode } from 'react'
+import { getParticipantToken } from '@/lib/api'
 import type { Draft, Participant, Pick, WebSocketMessage, WebSocketMessageData } from '@/lib/api'
 
 interface DraftState {
@@ -32,6 +33,13 @@ interface PendingPick {
   reject: (error: Error) => void
 }
 
+// Tracks the room sequence so missed broadcasts can be noticed and replayed
+interface RoomSync {
+  draftCode: string
+  seq: number
+  synced: boolean // a full draftState has been applied
+}
+
 const initialState: DraftState = {
   isConnected: false,
   ws: null,
@@ -63,6 +71,39 @@ function draftReducer(state: DraftState, action: DraftAction): DraftState {
         currentPicker: currentPicker !== undefined ? currentPicker : state.currentPicker,
         isAdmin: participants?.find((p: Participant) => p.name === state.participantName)?.isAdmin || false
       }
+    case 'APPLY_PICK_MADE': {
+      const { pick, participant, draft, currentPicker } = action.payload as WebSocketMessageData
+      if (!pick) return state
+      // A replayed pick may already be in the list
+      const picks = state.picks.some((p: Pick) => p.id === pick.id)
+        ? state.picks
+        : [...state.picks, pick].sort((a, b) => a.overallPickNumber - b.overallPickNumber)
+      return {
+        ...state,
+        draft: draft || state.draft,
+        participants: participant
+          ? state.participants.map((p: Participant) => p.id === participant.id ? participant : p)
+          : state.participants,
+        picks,
+        currentPicker: currentPicker !== undefined ? currentPicker : state.currentPicker,
+      }
+    }
+    case 'APPLY_PARTICIPANT_JOINED': {
+      const { participant, draft } = action.payload as WebSocketMessageData
+      if (!participant) return state
+      const participants = state.participants.some((p: Participant) => p.id === participant.id)
+        ? state.participants.map((p: Participant) => p.id === participant.id ? participant : p)
+        : [...state.participants, participant].sort((a, b) => a.draftOrder - b.draftOrder)
+      return {
+        ...state,
+        draft: draft || state.draft,
+        participants,
+      }
+    }
+    case 'APPLY_STATUS_CHANGED': {
+      const { draft } = action.payload as WebSocketMessageData
+      return { ...state, draft: draft || state.draft }
+    }
     case 'UPDATE_TOURNAMENT_STATE':
       return {
         ...state,
@@ -83,14 +124,62 @@ const DraftContext = createContext<{
 export function DraftProvider({ children }: { children: ReactNode }) {
   const [state, dispatch] = useReducer(draftReducer, initialState)
   const pendingPickRef = useRef<PendingPick | null>(null)
+  const wsRef = useRef<WebSocket | null>(null)
+  const syncRef = useRef<RoomSync | null>(null)
+  // The last join message sent, resent to resync after a gap
+  const identityRef = useRef<{ type: string, data: Record<string, unknown> } | null>(null)
+
+  // resolvePendingPick settles the pending pick once its player shows up
+  const resolvePendingPick = (picks: Pick[]) => {
+    if (!pendingPickRef.current) return
+    const { playerId } = pendingPickRef.current
+    if (picks.some((pick: Pick) => pick.playerId === playerId)) {
+      // Clear the ref first, then resolve to prevent race conditions
+      const currentPendingPick = pendingPickRef.current
+      pendingPickRef.current = null
+      currentPendingPick.resolve()
+    }
+  }
+
+  // trackSeq follows the room sequence and asks for the full state again if
+  // broadcasts were missed
+  const trackSeq = (ws: WebSocket, message: WebSocketMessage) => {
+    const sync = syncRef.current
+    if (!sync) return
+
+    if (message.type === 'joined') {
+      // A resuming client keeps its own sequence to replay from
+      if (!sync.synced && message.data.seq !== undefined) {
+        sync.seq = message.data.seq
+      }
+      return
+    }
+    if (message.type === 'draftState') {
+      sync.synced = true
+      return
+    }
+    if (message.seq === undefined) return
+
+    if (sync.synced && message.seq > sync.seq + 1 && identityRef.current && ws.readyState === WebSocket.OPEN) {
+      console.log(`Missed room messages ${sync.seq + 1}-${message.seq - 1}, resyncing`)
+      sync.synced = false
+      ws.send(JSON.stringify(identityRef.current))
+    }
+    sync.seq = Math.max(sync.seq, message.seq)
+  }
 
   const connectWebSocket = (draftCode: string) => {
-    if (state.ws) {
-      state.ws.close()
+    if (syncRef.current?.draftCode !== draftCode) {
+      syncRef.current = { draftCode, seq: 0, synced: false }
     }
-  
+
+    const previous = wsRef.current
     const wsBaseUrl = import.meta.env.VITE_WS_BASE_URL || 'ws://localhost:8080'
     const ws = new WebSocket(`${wsBaseUrl}/ws/drafts/${draftCode}`)
+    wsRef.current = ws
+    if (previous) {
+      previous.close()
+    }
     
     ws.onopen = () => {
       console.log('WebSocket connected')
@@ -100,34 +189,32 @@ export function DraftProvider({ children }: { children: ReactNode }) {
     ws.onmessage = (event) => {
       const message: WebSocketMessage = JSON.parse(event.data)
       console.log('WebSocket message:', message)
+      trackSeq(ws, message)
       
       if (message.type === 'draftState') {
         dispatch({ type: 'UPDATE_DRAFT_STATE', payload: message.data })
         
         // If we have a pending pick and the draft state updated, 
         // check if the pick was successful
-        if (pendingPickRef.current) {
-          const { playerId } = pendingPickRef.current
-          
-          // Check if the player was picked (appears in the picks)
-          const picks = message.data.picks || []
-          // Check if the player was picked (appears in the picks)
-          const wasPickSuccessful = picks.some((pick: Pick) => {
-            // The player ID is stored in the playerId field of the pick object
-            return (pick as any).playerId === playerId
-          })
-          
-          if (wasPickSuccessful) {
-            // Clear the ref first, then resolve to prevent race conditions
-            const currentPendingPick = pendingPickRef.current
-            pendingPickRef.current = null
-            currentPendingPick.resolve()
-          }
+        resolvePendingPick(message.data.picks || [])
+      } else if (message.type === 'pickMade') {
+        dispatch({ type: 'APPLY_PICK_MADE', payload: message.data })
+        if (message.data.pick) {
+          resolvePendingPick([message.data.pick])
         }
+      } else if (message.type === 'participantJoined') {
+        dispatch({ type: 'APPLY_PARTICIPANT_JOINED', payload: message.data })
+      } else if (message.type === 'statusChanged') {
+        dispatch({ type: 'APPLY_STATUS_CHANGED', payload: message.data })
       } else if (message.type === 'tournamentState') {
         dispatch({ type: 'UPDATE_TOURNAMENT_STATE', payload: message.data })
       } else if (message.type === 'joined') {
         console.log('Successfully joined draft room')
+      } else if (message.type === 'joinError') {
+        // Without a valid token the draft can still be watched
+        console.error('Join error:', message.data)
+        identityRef.current = { type: 'spectate', data: {} }
+        ws.send(JSON.stringify(identityRef.current))
       } else if (message.type === 'pickError') {
         console.error('Pick error:', message.data)
         
@@ -142,6 +229,9 @@ export function DraftProvider({ children }: { children: ReactNode }) {
     
     ws.onclose = () => {
       console.log('WebSocket disconnected')
+      
+      // A connection that was replaced or closed on purpose stays closed
+      if (wsRef.current !== ws) return
       dispatch({ type: 'SET_CONNECTED', payload: false })
       
       // Reject any pending pick
@@ -149,6 +239,13 @@ export function DraftProvider({ children }: { children: ReactNode }) {
         pendingPickRef.current.reject(new Error('Connection lost'))
         pendingPickRef.current = null
       }
+
+      // Reconnect, joining again resumes from the last sequence seen
+      setTimeout(() => {
+        if (wsRef.current === ws) {
+          connectWebSocket(draftCode)
+        }
+      }, 2000)
     }
     
     dispatch({ type: 'SET_WEBSOCKET', payload: ws })
@@ -159,11 +256,27 @@ export function DraftProvider({ children }: { children: ReactNode }) {
     dispatch({ type: 'SET_PARTICIPANT_NAME', payload: participantName })
     
     if (state.ws && state.ws.readyState === WebSocket.OPEN) {
-      console.log('Sending join message for:', participantName)
+      // The room only binds the connection to a name with its token, so a
+      // browser that never joined under that name watches as a spectator
+      const sync = syncRef.current
+      const token = sync ? getParticipantToken(sync.draftCode, participantName) : null
+      if (token) {
+        console.log('Sending join message for:', participantName)
+        identityRef.current = {
+          type: 'join',
+          data: { participantName, token }
+        }
+      } else {
+        console.log('No participant token for', participantName, 'spectating instead')
+        identityRef.current = { type: 'spectate', data: {} }
+      }
       console.log('WebSocket state:', state.ws.readyState)
+
+      // After a reconnect, ask for just the broadcasts missed meanwhile
+      const lastSeq = sync?.synced ? sync.seq : undefined
       state.ws.send(JSON.stringify({
-        type: 'join',
-        data: { participantName }
+        ...identityRef.current,
+        data: { ...identityRef.current.data, lastSeq }
       }))
     } else {
       console.log('WebSocket not ready:', state.ws?.readyState)
@@ -187,7 +300,7 @@ export function DraftProvider({ children }: { children: ReactNode }) {
         if (pendingPickRef.current && pendingPickRef.current.playerId === playerId) {
           // Check if the pick was actually successful before timing out
           const picks = state.picks || []
-          const wasPickSuccessful = picks.some((pick: Pick) => (pick as any).playerId === playerId)
+          const wasPickSuccessful = picks.some((pick: Pick) => pick.playerId === playerId)
           
           if (wasPickSuccessful) {
             // Pick was successful, just resolve without error
@@ -230,6 +343,9 @@ export function DraftProvider({ children }: { children: ReactNode }) {
   useEffect(() => {
     return () => {
       if (state.ws) {
+        if (wsRef.current === state.ws) {
+          wsRef.current = null
+        }
         state.ws.close()
       }
       // Clean up any pending pick
@@ -94,6 +94,7 @@ export interface Player {
 
 export interface Pick {
   id: string
+  playerId: number
   overallPickNumber: number
   roundNumber: number
   pickInRound: number
@@ -131,6 +132,7 @@ export interface CreateDraftRequest {
 
 export interface JoinDraftRequest {
   name: string
+  token?: string // rejoins under an existing name
 }
 
 export interface StartDraftRequest {
@@ -152,10 +154,14 @@ export interface StartTournamentRequest {
 // Response Types
 export interface CreateDraftResponse {
   draft: Draft
+  participant: Participant
+  token: string
 }
 
 export interface JoinDraftResponse {
-  message: string
+  draft: Draft
+  participant: Participant
+  token: string
 }
 
 export interface StartDraftResponse {
@@ -213,18 +219,26 @@ export interface StartTournamentResponse {
 
 // WebSocket Message Types
 export interface WebSocketMessage {
-  type: 'draftState' | 'joined' | 'pickError' | 'tournamentState'
+  type: 'draftState' | 'joined' | 'pickError' | 'tournamentState' | 'pickMade' | 'participantJoined' | 'statusChanged' | 'joinError'
   data: WebSocketMessageData
+  // Room broadcasts are numbered, a gap means some were missed
+  seq?: number
 }
 
 export interface WebSocketMessageData {
   draft?: Draft
   participants?: Participant[]
   picks?: Pick[]
-  currentPicker?: number
+  currentPicker?: number | null
   error?: string
   standings?: TeamStanding[]
   matches?: Match[]
+
+  // Deltas sent after the initial draftState
+  pick?: Pick
+  participant?: Participant
+  status?: Draft['status']
+  seq?: number
 }
 
 // Base request function
@@ -234,14 +248,12 @@ async function apiRequest<T>(
 ): Promise<T> {
   const url = `${API_BASE_URL}${endpoint}`
   
-  const defaultOptions: RequestInit = {
-    headers: {
-      'Content-Type': 'application/json',
-      ...options.headers,
-    },
+  const headers: HeadersInit = {
+    'Content-Type': 'application/json',
+    ...options.headers,
   }
 
-  const response = await fetch(url, { ...defaultOptions, ...options })
+  const response = await fetch(url, { ...options, headers })
 
   if (!response.ok) {
     const errorText = await response.text()
@@ -255,24 +267,52 @@ async function apiRequest<T>(
   return response.json()
 }
 
+// Participant tokens prove to the server who is picking. They are kept per
+// draft and name, so a refresh or a later visit rejoins as the same person.
+function participantTokenKey(code: string, name: string) {
+  return `participantToken:${code}:${name}`
+}
+
+export function getParticipantToken(code: string, name: string): string | null {
+  return localStorage.getItem(participantTokenKey(code, name))
+}
+
+function saveParticipantToken(code: string, name: string, token: string) {
+  if (token) {
+    localStorage.setItem(participantTokenKey(code, name), token)
+  }
+}
+
+// participantHeaders authenticates a request made as the named participant,
+// which admin actions and match reports need
+function participantHeaders(code: string, name: string): Record<string, string> {
+  const token = getParticipantToken(code, name)
+  return token ? { 'X-Participant-Token': token } : {}
+}
+
 // Draft API functions
 export async function createDraft(data: CreateDraftRequest): Promise<CreateDraftResponse> {
-  return apiRequest('/drafts', {
+  const response = await apiRequest<CreateDraftResponse>('/drafts', {
     method: 'POST',
     body: JSON.stringify(data),
   })
+  saveParticipantToken(response.draft.code, data.adminName, response.token)
+  return response
 }
 
 export async function joinDraft(code: string, data: JoinDraftRequest): Promise<JoinDraftResponse> {
-  return apiRequest(`/drafts/${code}`, {
+  const response = await apiRequest<JoinDraftResponse>(`/drafts/${code}`, {
     method: 'POST',
-    body: JSON.stringify(data),
+    body: JSON.stringify({ ...data, token: data.token ?? getParticipantToken(code, data.name) ?? undefined }),
   })
+  saveParticipantToken(code, data.name, response.token)
+  return response
 }
 
 export async function startDraft(code: string, data: { adminName: string }) {
   return apiRequest(`/drafts/${code}`, {
     method: 'PUT',
+    headers: participantHeaders(code, data.adminName),
     body: JSON.stringify(data),
   })
 }
@@ -316,6 +356,7 @@ export async function getTournamentData(code: string): Promise<TournamentRespons
 export async function recordMatch(code: string, data: RecordMatchRequest): Promise<RecordMatchResponse> {
   return apiRequest(`/drafts/${code}/matches`, {
     method: 'POST',
+    headers: participantHeaders(code, data.recordedBy),
     body: JSON.stringify(data),
   })
 }
@@ -323,6 +364,7 @@ export async function recordMatch(code: string, data: RecordMatchRequest): Promi
 export async function startTournament(code: string, data: StartTournamentRequest): Promise<StartTournamentResponse> {
   return apiRequest(`/drafts/${code}/tournament`, {
     method: 'POST',
+    headers: participantHeaders(code, data.adminName),
     body: JSON.stringify(data),
   })
 }
\ No newline at end of file
@@ -6,13 +6,15 @@ Fetches all player data from EA's API and exports to CSV
 import asyncio
 import csv
 import json
+import sys
 from typing import Dict, List, Any, Optional
 import httpx
 from pathlib import Path
 
 
 class EAFCPlayerScraper:
-    def __init__(self):
+    def __init__(self, gender: int = 0):
+        self.gender = gender  # 0 for men's football, 1 for women's
         self.base_url = "https://drop-api.ea.com/rating/ea-sports-fc"
         self.headers = {
             'accept': '*/*',
@@ -37,7 +39,7 @@ class EAFCPlayerScraper:
         params = {
             'locale': 'en',
             'limit': limit,
-            'gender': 0,  # Men's football
+            'gender': self.gender,
             'offset': offset
         }
         
@@ -66,6 +68,10 @@ class EAFCPlayerScraper:
             'league_name': player.get('leagueName'),
             'avatar_url': player.get('avatarUrl'),
             'shield_url': player.get('shieldUrl'),
+            'birthdate': player.get('birthdate'),
+            'height': player.get('height'),
+            'weight': player.get('weight'),
+            'gender': 'women' if self.gender == 1 else 'men',
         }
 
         # Extract alternate positions
@@ -172,11 +178,13 @@ class EAFCPlayerScraper:
     async def run(self):
         """Main execution method"""
         await self.fetch_all_players()
-        self.save_to_csv()
+        self.save_to_csv("eafc_players_women.csv" if self.gender == 1 else "eafc_players.csv")
 
 
 async def main():
-    scraper = EAFCPlayerScraper()
+    # Pass "women" to scrape women's football instead
+    gender = 1 if len(sys.argv) > 1 and sys.argv[1] == "women" else 0
+    scraper = EAFCPlayerScraper(gender)
     await scraper.run()
 
 
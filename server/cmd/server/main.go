@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"eafc-draft-server/internal/api"
 	"eafc-draft-server/internal/config"
@@ -18,6 +23,10 @@ func broadcastDraftState(db *sqlx.DB, draftCode string) {
 	api.BroadcastDraftStateToRoom(db, draftCode)
 }
 
+// shutdownTimeout is how long in-flight requests and picks get to finish
+// once the server is asked to stop
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	cfg := config.Load()
 
@@ -27,14 +36,61 @@ func main() {
 	}
 	defer db.Close()
 
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
 	handler := api.NewHandler(db, cfg)
 
+	if err := handler.WarmPlayerCache(); err != nil {
+		log.Printf("Failed to load player enums, they will load on first request: %v", err)
+	}
+
+	handler.StartPlayerSync()
+	handler.StartPriceSync()
+	handler.StartRedisBroadcasts()
+	handler.ResumePickTimers()
+
 	// Set the broadcast function to avoid circular imports
 	handler.SetBroadcastFunc(broadcastDraftState)
 
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
 
-	log.Printf("Server starting on %s", cfg.ServerAddress)
-	log.Fatal(http.ListenAndServe(cfg.ServerAddress, mux))
+	server := &http.Server{
+		Addr:    cfg.ServerAddress,
+		Handler: api.CompressResponses(mux),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("Server starting on %s", cfg.ServerAddress)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server error: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Printf("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Once the listener has closed, let picks commit and close the websockets
+	// and event streams, which the HTTP server would otherwise wait on
+	streamsClosed := make(chan struct{})
+	server.RegisterOnShutdown(func() {
+		defer close(streamsClosed)
+		if err := handler.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Websocket shutdown error: %v", err)
+		}
+	})
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server shutdown error: %v", err)
+	}
+	<-streamsClosed
+	log.Printf("Server stopped")
 }
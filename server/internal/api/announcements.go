@@ -0,0 +1,120 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"eafc-draft-server/internal/database"
+)
+
+const maxAnnouncementLength = 280
+
+type AnnounceMessage struct {
+	Message string `json:"message"`
+}
+
+// Announcement is the admin's highlighted message to the room. An empty
+// message clears the previous one.
+type Announcement struct {
+	Message     string    `json:"message"`
+	AnnouncedBy string    `json:"announcedBy"`
+	AnnouncedAt time.Time `json:"announcedAt"`
+}
+
+// handleAnnounce broadcasts the admin's announcement, such as a break, and
+// records it in the draft's timeline so clients joining later see it too
+func (h *Handler) handleAnnounce(client *DraftClient, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Announce marshal error: %v", err)
+		return fmt.Errorf("invalid announce message")
+	}
+
+	var announceMsg AnnounceMessage
+	if err := json.Unmarshal(dataBytes, &announceMsg); err != nil {
+		log.Printf("Announce unmarshal error: %v", err)
+		return fmt.Errorf("invalid announce message")
+	}
+
+	announcement, err := h.announce(client, strings.TrimSpace(announceMsg.Message))
+	if err != nil {
+		client.sendMessage("announceError", map[string]string{"error": err.Error()})
+		return err
+	}
+
+	broadcastMessage(client.Room.DraftCode, "announcement", announcement)
+	return nil
+}
+
+func (h *Handler) announce(client *DraftClient, text string) (Announcement, error) {
+	announcement := Announcement{
+		Message:     text,
+		AnnouncedBy: client.ParticipantName,
+		AnnouncedAt: time.Now(),
+	}
+
+	if client.ParticipantName == "" {
+		return announcement, fmt.Errorf("join the room before announcing")
+	}
+	if len(text) > maxAnnouncementLength {
+		return announcement, fmt.Errorf("announcement is longer than %d characters", maxAnnouncementLength)
+	}
+
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, client.Room.DraftCode)
+	if err != nil {
+		log.Printf("Get draft for announcement error: %v", err)
+		return announcement, fmt.Errorf("draft not found")
+	}
+
+	if draft.AdminName != client.ParticipantName {
+		return announcement, fmt.Errorf("only the admin can make announcements")
+	}
+	if err := verifyParticipantToken(h.db, client.Room.DraftCode, client.ParticipantName, client.token); err != nil {
+		return announcement, err
+	}
+
+	if err := recordDraftEvent(h.db, draft.ID, EventAnnouncement, client.ParticipantName, announcement); err != nil {
+		log.Printf("Record announcement error: %v", err)
+		return announcement, fmt.Errorf("failed to save announcement")
+	}
+
+	log.Printf("Announcement in draft %s by %s: %q", draft.Code, client.ParticipantName, text)
+	return announcement, nil
+}
+
+// sendLatestAnnouncement shows a joining client the announcement still up,
+// if the admin made one and hasn't cleared it
+func (h *Handler) sendLatestAnnouncement(client *DraftClient) {
+	var payload []byte
+	err := h.db.Get(&payload, `
+		SELECT e.payload FROM draft_events e
+		JOIN drafts d ON e.draft_id = d.id
+		WHERE d.code = $1 AND e.event_type = $2
+		ORDER BY e.id DESC LIMIT 1
+	`, client.Room.DraftCode, EventAnnouncement)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		log.Printf("Get latest announcement error: %v", err)
+		return
+	}
+
+	var announcement Announcement
+	if err := json.Unmarshal(payload, &announcement); err != nil {
+		log.Printf("Unmarshal announcement error: %v", err)
+		return
+	}
+	if announcement.Message != "" {
+		client.sendMessage("announcement", announcement)
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// participantTokenHeader carries the caller's participant token on HTTP
+// requests that act on behalf of a participant
+const participantTokenHeader = "X-Participant-Token"
+
+// generateParticipantToken creates the secret a participant uses to prove who
+// they are when picking, rejoining or running admin operations
+func generateParticipantToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// participantToken returns the participant token sent with the request
+func participantToken(r *http.Request) string {
+	return r.Header.Get(participantTokenHeader)
+}
+
+// verifyParticipantToken checks that token belongs to the named participant of
+// the draft. Bots never hand out their token, so they cannot be impersonated.
+func verifyParticipantToken(q sqlx.Queryer, draftCode, name, token string) error {
+	if token == "" {
+		return newStatusError(http.StatusUnauthorized, "Participant token is required")
+	}
+
+	var expected *string
+	err := sqlx.Get(q, &expected, `
+		SELECT p.token
+		FROM draft_participants p
+		JOIN drafts d ON d.id = p.draft_id
+		WHERE d.code = $1 AND p.name = $2
+	`, draftCode, name)
+	if err == sql.ErrNoRows {
+		return newStatusError(http.StatusUnauthorized, "Invalid participant token")
+	}
+	if err != nil {
+		log.Printf("Verify participant token error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Database error")
+	}
+
+	if expected == nil {
+		return newStatusError(http.StatusUnauthorized, "Rejoin the draft to claim a participant token")
+	}
+	if subtle.ConstantTimeCompare([]byte(*expected), []byte(token)) != 1 {
+		return newStatusError(http.StatusUnauthorized, "Invalid participant token")
+	}
+	return nil
+}
+
+// claimParticipantToken issues a token to a participant who joined before
+// tokens existed and so never received one. Whoever rejoins under the name
+// first gets it; bots always have a token, so they can't be claimed.
+func claimParticipantToken(tx *sqlx.Tx, draftID int, name string) (string, bool, error) {
+	token, err := generateParticipantToken()
+	if err != nil {
+		return "", false, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE draft_participants SET token = $1
+		WHERE draft_id = $2 AND name = $3 AND token IS NULL AND NOT is_bot
+	`, token, draftID, name)
+	if err != nil {
+		return "", false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return "", false, err
+	}
+	return token, claimed == 1, nil
+}
+
+// resetParticipantToken clears a participant's token so they can claim a new
+// one on their next join. Databases that first added the token column with
+// NOT NULL gave rows from before tokens a random default that was never
+// handed out and can't be told apart from real ones, which leaves those
+// participants locked out until an operator resets them here:
+//
+//	DELETE /api/admin/drafts/{code}/participants/{name}/token
+func (h *Handler) resetParticipantToken(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s", r.Method, r.URL.Path)
+
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/drafts/"), "/"), "/")
+	if len(parts) != 4 || parts[1] != "participants" || parts[3] != "token" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	code, name := parts[0], parts[2]
+
+	result, err := h.db.Exec(`
+		UPDATE draft_participants p SET token = NULL
+		FROM drafts d
+		WHERE d.id = p.draft_id AND d.code = $1 AND p.name = $2 AND NOT p.is_bot
+	`, code, name)
+	if err != nil {
+		log.Printf("Reset participant token error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if reset, err := result.RowsAffected(); err != nil || reset == 0 {
+		http.Error(w, "Participant not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("Reset the token of %s in draft %s", name, code)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// hashJoinPassword salts and hashes a draft join password as "salt$hash"
+func hashJoinPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	saltHex := hex.EncodeToString(salt)
+	sum := sha256.Sum256([]byte(saltHex + password))
+	return saltHex + "$" + hex.EncodeToString(sum[:]), nil
+}
+
+// joinPasswordMatches checks a password against a hash from hashJoinPassword
+func joinPasswordMatches(stored, password string) bool {
+	saltHex, hashHex, ok := strings.Cut(stored, "$")
+	if !ok {
+		return false
+	}
+	sum := sha256.Sum256([]byte(saltHex + password))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(hashHex)) == 1
+}
+
+// verifyJoinPassword checks the join password of a private draft. Public
+// drafts accept any password.
+func verifyJoinPassword(q sqlx.Queryer, draftCode, password string) error {
+	var stored *string
+	err := sqlx.Get(q, &stored, "SELECT join_password_hash FROM drafts WHERE code = $1", draftCode)
+	if err == sql.ErrNoRows {
+		return newStatusError(http.StatusNotFound, "Draft not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if stored == nil {
+		return nil
+	}
+	if password == "" {
+		return newStatusError(http.StatusUnauthorized, "This draft requires a join password")
+	}
+	if !joinPasswordMatches(*stored, password) {
+		return newStatusError(http.StatusUnauthorized, "Incorrect join password")
+	}
+	return nil
+}
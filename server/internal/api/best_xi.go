@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+)
+
+// formations lists the positions of each supported starting XI
+var formations = []struct {
+	Name      string
+	Positions []string
+}{
+	{"4-3-3", []string{"GK", "LB", "CB", "CB", "RB", "CM", "CM", "CM", "LW", "ST", "RW"}},
+	{"4-4-2", []string{"GK", "LB", "CB", "CB", "RB", "LM", "CM", "CM", "RM", "ST", "ST"}},
+	{"4-2-3-1", []string{"GK", "LB", "CB", "CB", "RB", "CDM", "CDM", "LM", "CAM", "RM", "ST"}},
+	{"4-1-2-1-2", []string{"GK", "LB", "CB", "CB", "RB", "CDM", "CM", "CM", "CAM", "ST", "ST"}},
+	{"3-5-2", []string{"GK", "CB", "CB", "CB", "LM", "CDM", "CDM", "RM", "CAM", "ST", "ST"}},
+	{"5-3-2", []string{"GK", "LWB", "CB", "CB", "CB", "RWB", "CM", "CM", "CM", "ST", "ST"}},
+}
+
+type BestXIPlayer struct {
+	PlayerID      int     `json:"playerId"`
+	PlayerName    string  `json:"playerName"`
+	OverallRating *int    `json:"overallRating"`
+	Position      *string `json:"position"` // the player's natural position
+}
+
+type BestXISlot struct {
+	Position string        `json:"position"`
+	Player   *BestXIPlayer `json:"player"` // nil when nobody in the squad can play there
+}
+
+type FormationXI struct {
+	Formation     string         `json:"formation"`
+	Lineup        []BestXISlot   `json:"lineup"`
+	FilledSlots   int            `json:"filledSlots"`
+	TotalRating   int            `json:"totalRating"`
+	AverageRating *float64       `json:"averageRating"`
+	Bench         []BestXIPlayer `json:"bench"`
+}
+
+type BestXIResponse struct {
+	ParticipantName string        `json:"participantName"`
+	BestFormation   string        `json:"bestFormation"`
+	Formations      []FormationXI `json:"formations"`
+}
+
+// getBestXI picks the strongest starting XI a participant can field in each
+// formation, using players' alternate positions as well as their main one
+func (h *Handler) getBestXI(w http.ResponseWriter, r *http.Request, code, participantName string) {
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for best XI error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	var participant database.DraftParticipant
+	err = h.db.Get(&participant, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 AND name = $2
+	`, draft.ID, participantName)
+	if err != nil {
+		http.Error(w, "Participant not found", http.StatusNotFound)
+		return
+	}
+
+	squads, err := loadSquadPlayers(h.db, draft.ID)
+	if err != nil {
+		log.Printf("Get squad for best XI error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	squad := squads[participant.ID]
+
+	formation := r.URL.Query().Get("formation")
+
+	response := BestXIResponse{
+		ParticipantName: participant.Name,
+		Formations:      []FormationXI{},
+	}
+	for _, f := range formations {
+		if formation != "" && f.Name != formation {
+			continue
+		}
+		xi := bestXIForFormation(squad, f.Positions)
+		xi.Formation = f.Name
+		response.Formations = append(response.Formations, xi)
+	}
+
+	if len(response.Formations) == 0 {
+		http.Error(w, "Unknown formation", http.StatusBadRequest)
+		return
+	}
+
+	best := response.Formations[0]
+	for _, xi := range response.Formations[1:] {
+		if xi.FilledSlots > best.FilledSlots || (xi.FilledSlots == best.FilledSlots && xi.TotalRating > best.TotalRating) {
+			best = xi
+		}
+	}
+	response.BestFormation = best.Formation
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// bestXIForFormation assigns players to slots to fill as many slots as
+// possible, then maximize total rating. Squads are small, so it runs an exact
+// DP over the set of filled slots, one player at a time.
+func bestXIForFormation(squad []squadPlayer, slots []string) FormationXI {
+	type state struct {
+		filled, rating int
+		assignment     []int // player index per slot, -1 if empty
+	}
+	better := func(a, b state) bool {
+		return a.filled > b.filled || (a.filled == b.filled && a.rating > b.rating)
+	}
+
+	empty := make([]int, len(slots))
+	for i := range empty {
+		empty[i] = -1
+	}
+	states := map[int]state{0: {assignment: empty}}
+
+	for p, player := range squad {
+		positions := playablePositions(player)
+		rating := 0
+		if player.OverallRating != nil {
+			rating = *player.OverallRating
+		}
+
+		next := make(map[int]state, len(states))
+		for mask, s := range states {
+			// The player can sit on the bench
+			if current, ok := next[mask]; !ok || better(s, current) {
+				next[mask] = s
+			}
+			for slot, position := range slots {
+				if mask&(1<<slot) != 0 || !containsString(positions, position) {
+					continue
+				}
+				assignment := append([]int(nil), s.assignment...)
+				assignment[slot] = p
+				candidate := state{filled: s.filled + 1, rating: s.rating + rating, assignment: assignment}
+				newMask := mask | 1<<slot
+				if current, ok := next[newMask]; !ok || better(candidate, current) {
+					next[newMask] = candidate
+				}
+			}
+		}
+		states = next
+	}
+
+	// Walk the masks in order so ties resolve the same way every time
+	best := states[0]
+	for mask := 1; mask < 1<<len(slots); mask++ {
+		if s, ok := states[mask]; ok && better(s, best) {
+			best = s
+		}
+	}
+
+	xi := FormationXI{
+		Lineup:      make([]BestXISlot, len(slots)),
+		FilledSlots: best.filled,
+		TotalRating: best.rating,
+		Bench:       []BestXIPlayer{},
+	}
+	starting := make(map[int]bool)
+	for slot, position := range slots {
+		xi.Lineup[slot] = BestXISlot{Position: position}
+		if p := best.assignment[slot]; p >= 0 {
+			player := bestXIPlayer(squad[p])
+			xi.Lineup[slot].Player = &player
+			starting[p] = true
+		}
+	}
+	for p, player := range squad {
+		if !starting[p] {
+			xi.Bench = append(xi.Bench, bestXIPlayer(player))
+		}
+	}
+	if best.filled > 0 {
+		average := roundTo2(float64(best.rating) / float64(best.filled))
+		xi.AverageRating = &average
+	}
+	return xi
+}
+
+// playablePositions returns a player's main position and their pipe-separated
+// alternate positions
+func playablePositions(player squadPlayer) []string {
+	var positions []string
+	if player.Position != nil && *player.Position != "" {
+		positions = append(positions, *player.Position)
+	}
+	if player.AlternatePositions != nil {
+		for _, position := range strings.Split(*player.AlternatePositions, "|") {
+			if position = strings.TrimSpace(position); position != "" {
+				positions = append(positions, position)
+			}
+		}
+	}
+	return positions
+}
+
+func bestXIPlayer(player squadPlayer) BestXIPlayer {
+	return BestXIPlayer{
+		PlayerID:      player.PlayerID,
+		PlayerName:    playerDisplayName(player.FirstName, player.LastName, player.CommonName),
+		OverallRating: player.OverallRating,
+		Position:      player.Position,
+	}
+}
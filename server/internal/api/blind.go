@@ -0,0 +1,357 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// blindRoundMu serializes round resolution so a round is only revealed once
+var blindRoundMu sync.Mutex
+
+// BlindReveal is one resolved pick of a blind round
+type BlindReveal struct {
+	ParticipantName   string `json:"participantName"`
+	PlayerID          int    `json:"playerId"`
+	RequestedPlayerID *int   `json:"requestedPlayerId"` // nil if nothing was submitted
+	Conflict          bool   `json:"conflict"`          // the requested player went to someone earlier in the order
+}
+
+// BlindRejection tells a participant their submission could not be turned
+// into a pick, for a reason other than a conflict, and must be made again
+type BlindRejection struct {
+	Round    int    `json:"round"`
+	PlayerID int    `json:"playerId"`
+	Error    string `json:"error"`
+}
+
+// submitBlindPick stores a participant's secret pick for the current round.
+// Submissions can be changed until the round is revealed.
+func (h *Handler) submitBlindPick(draftCode, participantName string, playerID int, comment string) (int, error) {
+	if err := h.maintenanceError(); err != nil {
+		return 0, err
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin blind pick transaction error: %v", err)
+		return 0, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, draftCode)
+	if err != nil {
+		log.Printf("Get draft for blind pick error: %v", err)
+		return 0, fmt.Errorf("draft not found")
+	}
+
+	if draft.Status != "active" {
+		return 0, fmt.Errorf("draft is not active")
+	}
+
+	var participant database.DraftParticipant
+	err = tx.Get(&participant, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 AND name = $2
+	`, draft.ID, participantName)
+	if err != nil {
+		return 0, fmt.Errorf("participant not found")
+	}
+
+	var pickedThisRound bool
+	err = tx.Get(&pickedThisRound, "SELECT EXISTS(SELECT 1 FROM draft_picks WHERE participant_id = $1 AND round_number = $2)",
+		participant.ID, draft.CurrentRound)
+	if err != nil {
+		return 0, fmt.Errorf("database error")
+	}
+	if pickedThisRound {
+		return 0, fmt.Errorf("you already have a player for round %d", draft.CurrentRound)
+	}
+
+	var player database.Player
+	err = tx.Get(&player, "SELECT id, overall_rating, position_short_label, league_name, nationality_label, gender, card_type FROM players WHERE id = $1 AND dataset = $2", playerID, draft.Dataset)
+	if err != nil {
+		return 0, fmt.Errorf("player not found")
+	}
+
+	if player.OverallRating == nil {
+		return 0, fmt.Errorf("player has no rating")
+	}
+
+	if err := checkDraftPool(draft, player); err != nil {
+		return 0, err
+	}
+
+	var alreadyPicked bool
+	err = tx.Get(&alreadyPicked, "SELECT EXISTS(SELECT 1 FROM draft_picks WHERE draft_id = $1 AND player_id = $2)", draft.ID, playerID)
+	if err != nil {
+		return 0, fmt.Errorf("database error checking duplicates")
+	}
+	if alreadyPicked {
+		return 0, fmt.Errorf("player already picked in this draft")
+	}
+
+	ratingTier := h.getRatingTier(*player.OverallRating)
+	if ratingTier == "invalid" {
+		return 0, fmt.Errorf("cannot pick players rated 90+")
+	}
+
+	if !h.canPickFromTier(draft, participant, ratingTier) {
+		return 0, h.formatQuotaError(draft, participant, ratingTier)
+	}
+
+	if isGoalkeeper(player.PositionShortLabel) && !canPickGoalkeeper(draft, participant) {
+		return 0, goalkeeperQuotaError(draft, participant)
+	}
+	if !isGoalkeeper(player.PositionShortLabel) && mustPickGoalkeeper(draft, participant) {
+		return 0, goalkeeperMinimumError(draft, participant)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO draft_blind_submissions (draft_id, round_number, participant_id, player_id, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (draft_id, round_number, participant_id)
+		DO UPDATE SET player_id = EXCLUDED.player_id, comment = EXCLUDED.comment, submitted_at = NOW()
+	`, draft.ID, draft.CurrentRound, participant.ID, playerID, pickComment(comment))
+	if err != nil {
+		log.Printf("Save blind pick error: %v", err)
+		return 0, fmt.Errorf("failed to save pick")
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit blind pick transaction error: %v", err)
+		return 0, fmt.Errorf("failed to save pick")
+	}
+
+	log.Printf("Blind pick submitted by %s for round %d in draft %s", participantName, draft.CurrentRound, draftCode)
+
+	return draft.CurrentRound, nil
+}
+
+// pendingBlindSubmitters lists the human participants who still owe a pick
+// for the current round. Bots and participants with a keeper in the round
+// don't submit.
+func pendingBlindSubmitters(q sqlx.Queryer, draft database.Draft) ([]string, error) {
+	names := []string{}
+	err := sqlx.Select(q, &names, `
+		SELECT part.name
+		FROM draft_participants part
+		WHERE part.draft_id = $1 AND NOT part.is_bot
+		  AND NOT EXISTS (SELECT 1 FROM draft_picks dp WHERE dp.participant_id = part.id AND dp.round_number = $2)
+		  AND NOT EXISTS (SELECT 1 FROM draft_blind_submissions s
+		                  WHERE s.participant_id = part.id AND s.draft_id = $1 AND s.round_number = $2)
+		ORDER BY part.draft_order
+	`, draft.ID, draft.CurrentRound)
+	return names, err
+}
+
+// resolveBlindRoundsIfReady reveals the current round, and any following
+// rounds that need nobody's input, once every submission is in
+func (h *Handler) resolveBlindRoundsIfReady(draftCode string) {
+	for {
+		var draft database.Draft
+		err := h.db.Get(&draft, `
+			SELECT `+database.DraftColumns+`
+			FROM drafts WHERE code = $1
+		`, draftCode)
+		if err != nil {
+			log.Printf("Get draft for blind round error: %v", err)
+			return
+		}
+
+		if draft.Status != "active" || !draft.BlindMode {
+			return
+		}
+
+		pending, err := pendingBlindSubmitters(h.db, draft)
+		if err != nil {
+			log.Printf("Get pending blind submitters error: %v", err)
+			return
+		}
+		if len(pending) > 0 {
+			broadcastMessage(draftCode, "blindRoundStatus", map[string]interface{}{
+				"round":   draft.CurrentRound,
+				"waiting": pending,
+			})
+			return
+		}
+
+		if !h.resolveBlindRound(draftCode, draft.CurrentRound) {
+			return
+		}
+	}
+}
+
+// resolveBlindRound turns the round's submissions into picks in draft order.
+// Whoever is later in the order and asked for a player taken earlier in the
+// round gets the best available player instead, as does anyone who submitted
+// nothing. A submission refused for any other reason, such as maintenance,
+// stops the round: it is withdrawn and its participant asked to submit again,
+// and what was picked so far is revealed. It reports whether the round was
+// resolved.
+func (h *Handler) resolveBlindRound(draftCode string, round int) bool {
+	blindRoundMu.Lock()
+	defer blindRoundMu.Unlock()
+
+	var reveals []BlindReveal
+	taken := make(map[int]bool)
+	resolved := true
+	for {
+		var draft database.Draft
+		err := h.db.Get(&draft, `
+			SELECT `+database.DraftColumns+`
+			FROM drafts WHERE code = $1
+		`, draftCode)
+		if err != nil {
+			log.Printf("Get draft for blind resolution error: %v", err)
+			return false
+		}
+
+		if draft.Status != "active" || draft.CurrentRound != round {
+			break
+		}
+
+		currentPicker := calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+
+		var participant database.DraftParticipant
+		err = h.db.Get(&participant, `
+			SELECT `+database.ParticipantColumns+`
+			FROM draft_participants WHERE draft_id = $1 AND draft_order = $2
+		`, draft.ID, currentPicker)
+		if err != nil {
+			log.Printf("Get participant for blind resolution error: %v", err)
+			return false
+		}
+
+		reveal := BlindReveal{ParticipantName: participant.Name}
+		comment := ""
+		if !participant.IsBot {
+			var submission struct {
+				PlayerID int     `db:"player_id"`
+				Comment  *string `db:"comment"`
+			}
+			err = h.db.Get(&submission, `
+				SELECT player_id, comment FROM draft_blind_submissions
+				WHERE draft_id = $1 AND round_number = $2 AND participant_id = $3
+			`, draft.ID, round, participant.ID)
+			if err == nil {
+				reveal.RequestedPlayerID = &submission.PlayerID
+				if submission.Comment != nil {
+					comment = *submission.Comment
+				}
+			}
+
+			// Fall back to the best available player for this participant
+			strategy := BotStrategyBestAvailable
+			participant.BotStrategy = &strategy
+		}
+
+		picked := false
+		if reveal.RequestedPlayerID != nil {
+			reveal.PlayerID = *reveal.RequestedPlayerID
+			reveal.Conflict = taken[reveal.PlayerID]
+		}
+		if reveal.RequestedPlayerID != nil && !reveal.Conflict {
+			if _, err := h.processPick(draftCode, participant.Name, reveal.PlayerID, comment); err != nil {
+				log.Printf("Blind round refused %s's pick in draft %s: %v", participant.Name, draftCode, err)
+				h.rejectBlindSubmission(draftCode, draft.ID, participant, round, reveal.PlayerID, err)
+				resolved = false
+				break
+			}
+			picked = true
+		}
+
+		if !picked {
+			playerID, err := h.chooseBotPick(draft, participant)
+			if err != nil {
+				log.Printf("Blind round could not choose for %s in draft %s: %v", participant.Name, draftCode, err)
+				return false
+			}
+			if _, err := h.processPick(draftCode, participant.Name, playerID, ""); err != nil {
+				log.Printf("Blind round pick failed for %s in draft %s: %v", participant.Name, draftCode, err)
+				return false
+			}
+			reveal.PlayerID = playerID
+		}
+
+		taken[reveal.PlayerID] = true
+		reveals = append(reveals, reveal)
+	}
+
+	// A stopped round keeps the rest of its submissions for when it resumes
+	if resolved {
+		if _, err := h.db.Exec(`
+			DELETE FROM draft_blind_submissions
+			WHERE round_number = $1 AND draft_id = (SELECT id FROM drafts WHERE code = $2)
+		`, round, draftCode); err != nil {
+			log.Printf("Clear blind submissions error: %v", err)
+		}
+	}
+
+	if len(reveals) == 0 {
+		return false
+	}
+
+	log.Printf("Revealed blind round %d of draft %s", round, draftCode)
+
+	broadcastMessage(draftCode, "roundReveal", map[string]interface{}{
+		"round":    round,
+		"picks":    reveals,
+		"complete": resolved,
+	})
+	BroadcastDraftStateToRoom(h.db, draftCode)
+	return resolved
+}
+
+// rejectBlindSubmission withdraws a submission that could not be picked and
+// tells its participant why, so the round waits for them to submit again
+func (h *Handler) rejectBlindSubmission(draftCode string, draftID int, participant database.DraftParticipant, round, playerID int, pickErr error) {
+	if _, err := h.db.Exec(`
+		DELETE FROM draft_blind_submissions
+		WHERE draft_id = $1 AND round_number = $2 AND participant_id = $3
+	`, draftID, round, participant.ID); err != nil {
+		log.Printf("Withdraw blind submission error: %v", err)
+	}
+
+	sendParticipantMessage(draftCode, participant.Name, "blindPickRejected", BlindRejection{
+		Round:    round,
+		PlayerID: playerID,
+		Error:    pickErr.Error(),
+	})
+	broadcastMessage(draftCode, "blindRoundStatus", map[string]interface{}{
+		"round":   round,
+		"waiting": []string{participant.Name},
+	})
+}
+
+// isBlindDraft reports whether the draft runs in blind round mode
+func (h *Handler) isBlindDraft(draftCode string) bool {
+	var blindMode bool
+	if err := h.db.Get(&blindMode, "SELECT blind_mode FROM drafts WHERE code = $1", draftCode); err != nil {
+		return false
+	}
+	return blindMode
+}
+
+// handleBlindPick takes a makePick message in a blind draft as a submission
+// for the round instead of an immediate pick
+func (h *Handler) handleBlindPick(client *DraftClient, pickMsg MakePickMessage) error {
+	round, err := h.submitBlindPick(client.Room.DraftCode, pickMsg.ParticipantName, pickMsg.PlayerID, pickMsg.Comment)
+	if err != nil {
+		client.sendMessage("pickError", map[string]string{"error": err.Error()})
+		return err
+	}
+
+	client.sendMessage("blindPickAccepted", map[string]int{"round": round, "playerId": pickMsg.PlayerID})
+
+	h.resolveBlindRoundsIfReady(client.Room.DraftCode)
+	return nil
+}
@@ -0,0 +1,387 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Bot pick strategies
+const (
+	BotStrategyBestAvailable  = "best_available"
+	BotStrategyPositionalNeed = "positional_need"
+	BotStrategyRandom         = "random"
+)
+
+// botPickDelay gives humans a moment to see each bot pick land
+const botPickDelay = 1500 * time.Millisecond
+
+// positionGroups maps position groups to the position labels they contain
+var positionGroups = map[string][]string{
+	"GK":  {"GK"},
+	"DEF": {"CB", "LB", "RB", "LWB", "RWB"},
+	"MID": {"CDM", "CM", "CAM", "LM", "RM"},
+	"FWD": {"ST", "CF", "LW", "RW"},
+}
+
+// botSquadTargets is the squad shape the positional need strategy aims for
+var botSquadTargets = map[string]int{
+	"GK":  1,
+	"DEF": 4,
+	"MID": 3,
+	"FWD": 3,
+}
+
+type AddBotsRequest struct {
+	AdminName string `json:"adminName"`
+	Count     int    `json:"count"`
+	Strategy  string `json:"strategy"`
+}
+
+type AddBotsResponse struct {
+	Draft database.Draft              `json:"draft"`
+	Bots  []database.DraftParticipant `json:"bots"`
+}
+
+func isValidBotStrategy(strategy string) bool {
+	return strategy == BotStrategyBestAvailable || strategy == BotStrategyPositionalNeed || strategy == BotStrategyRandom
+}
+
+// addBots adds computer-controlled participants to a waiting draft
+func (h *Handler) addBots(w http.ResponseWriter, r *http.Request, code string) {
+	var req AddBotsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Add bots decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	if req.Strategy == "" {
+		req.Strategy = BotStrategyBestAvailable
+	}
+	if !isValidBotStrategy(req.Strategy) {
+		http.Error(w, "Strategy must be best_available, positional_need or random", http.StatusBadRequest)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Get draft and verify admin
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for add bots error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can add bots", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.Status != "waiting" {
+		http.Error(w, "Draft has already started", http.StatusBadRequest)
+		return
+	}
+
+	if seats := remainingSeats(draft); seats != nil && req.Count > *seats {
+		http.Error(w, fmt.Sprintf("Only %d seats left in this draft", *seats), http.StatusBadRequest)
+		return
+	}
+
+	bots, err := h.insertBots(tx, &draft, req.Count, req.Strategy)
+	if err != nil {
+		log.Printf("Insert bots error: %v", err)
+		http.Error(w, "Failed to add bots", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventBotsAdded, req.AdminName, map[string]interface{}{"count": len(bots), "strategy": req.Strategy}); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to add bots", http.StatusInternalServerError)
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to add bots", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Added %d %s bots to draft %s", len(bots), req.Strategy, code)
+
+	// Broadcast updated draft state to all WebSocket clients
+	if h.broadcastFunc != nil {
+		go h.broadcastFunc(h.db, code)
+	}
+
+	response := AddBotsResponse{
+		Draft: draft,
+		Bots:  bots,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// insertBots adds count bot participants to a locked draft and updates its
+// participant count
+func (h *Handler) insertBots(tx *sqlx.Tx, draft *database.Draft, count int, strategy string) ([]database.DraftParticipant, error) {
+	var bots []database.DraftParticipant
+	botNumber := 1
+
+	for len(bots) < count {
+		name := fmt.Sprintf("Bot %d", botNumber)
+		botNumber++
+
+		var nameExists bool
+		err := tx.Get(&nameExists, "SELECT EXISTS(SELECT 1 FROM draft_participants WHERE draft_id = $1 AND name = $2)", draft.ID, name)
+		if err != nil {
+			return nil, err
+		}
+		if nameExists {
+			continue
+		}
+
+		var bot database.DraftParticipant
+		err = tx.Get(&bot, `
+			INSERT INTO draft_participants (draft_id, name, draft_order, is_admin, is_bot, bot_strategy, is_ready) 
+			VALUES ($1, $2, $3, false, true, $4, true) 
+			RETURNING `+database.ParticipantColumns+`
+		`, draft.ID, name, draft.ParticipantCount+1, strategy)
+		if err != nil {
+			return nil, err
+		}
+
+		draft.ParticipantCount++
+		bots = append(bots, bot)
+	}
+
+	_, err := tx.Exec("UPDATE drafts SET participant_count = $1, order_locked = false WHERE id = $2", draft.ParticipantCount, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	draft.OrderLocked = false
+
+	return bots, nil
+}
+
+// runBotPicks makes picks for as long as a bot is on the clock. Mock drafts
+// pick instantly, other drafts pause briefly so each bot pick can be seen.
+func (h *Handler) runBotPicks(draftCode string) {
+	for {
+		var draft database.Draft
+		err := h.db.Get(&draft, `
+			SELECT `+database.DraftColumns+`
+			FROM drafts WHERE code = $1
+		`, draftCode)
+		if err != nil {
+			log.Printf("Get draft for bot picks error: %v", err)
+			return
+		}
+
+		if draft.Status != "active" {
+			return
+		}
+
+		// Bots choose when a blind round is revealed rather than on their turn
+		if draft.BlindMode {
+			h.resolveBlindRoundsIfReady(draftCode)
+			return
+		}
+
+		currentPicker := calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+
+		var bot database.DraftParticipant
+		err = h.db.Get(&bot, `
+			SELECT `+database.ParticipantColumns+`
+			FROM draft_participants WHERE draft_id = $1 AND draft_order = $2
+		`, draft.ID, currentPicker)
+		if err != nil {
+			log.Printf("Get current picker for bot picks error: %v", err)
+			return
+		}
+
+		if !bot.IsBot {
+			return
+		}
+
+		if !draft.IsMock {
+			time.Sleep(botPickDelay)
+		}
+
+		playerID, err := h.chooseBotPick(draft, bot)
+		if err != nil {
+			log.Printf("Bot %s could not choose a pick in draft %s: %v", bot.Name, draftCode, err)
+			return
+		}
+
+		made, err := h.processPick(draftCode, bot.Name, playerID, "")
+		if err != nil {
+			log.Printf("Bot %s pick failed in draft %s: %v", bot.Name, draftCode, err)
+			return
+		}
+
+		broadcastPickMade(h.db, draftCode, made)
+	}
+}
+
+// eligiblePlayerConditions returns the WHERE conditions matching players a
+// participant could pick right now: still available, inside the pool and
+// within their quotas. The draft ID is $1.
+func (h *Handler) eligiblePlayerConditions(draft database.Draft, participant database.DraftParticipant) ([]string, []interface{}, error) {
+	conditions := []string{
+		"overall_rating IS NOT NULL",
+		"NOT EXISTS (SELECT 1 FROM draft_picks dp WHERE dp.draft_id = $1 AND dp.player_id = players.id)",
+	}
+	args := []interface{}{draft.ID}
+
+	var tierConditions []string
+	if h.canPickFromTier(draft, participant, "85-89") {
+		tierConditions = append(tierConditions, "overall_rating BETWEEN 85 AND 89")
+	}
+	if h.canPickFromTier(draft, participant, "80-84") {
+		tierConditions = append(tierConditions, "overall_rating BETWEEN 80 AND 84")
+	}
+	if h.canPickFromTier(draft, participant, "75-79") {
+		tierConditions = append(tierConditions, "overall_rating <= 79")
+	}
+	if len(tierConditions) == 0 {
+		return nil, nil, fmt.Errorf("no tier quota left")
+	}
+	conditions = append(conditions, "("+strings.Join(tierConditions, " OR ")+")")
+
+	if !canPickGoalkeeper(draft, participant) {
+		conditions = append(conditions, "position_short_label IS DISTINCT FROM 'GK'")
+	} else if mustPickGoalkeeper(draft, participant) {
+		conditions = append(conditions, "position_short_label = 'GK'")
+	}
+
+	poolConditions, poolArgs, _ := draftPoolConditions(draft, len(args)+1)
+	conditions = append(conditions, poolConditions...)
+	args = append(args, poolArgs...)
+
+	return conditions, args, nil
+}
+
+// chooseBotPick picks a player for a bot according to its strategy, among
+// players that are still available, inside the pool and within its quotas.
+// In pack mode it chooses from the pack dealt for the turn.
+func (h *Handler) chooseBotPick(draft database.Draft, bot database.DraftParticipant) (int, error) {
+	conditions, args, err := h.eligiblePlayerConditions(draft, bot)
+	if err != nil {
+		return 0, err
+	}
+
+	if draft.PackSize != nil {
+		pack, err := h.dealPack(draft, bot)
+		if err != nil {
+			return 0, err
+		}
+		conditions = append(conditions, fmt.Sprintf("id = ANY($%d)", len(args)+1))
+		args = append(args, pq.Array(pack.PlayerIDs))
+	}
+
+	orderClause := "ORDER BY overall_rating DESC, id ASC"
+	strategy := BotStrategyBestAvailable
+	if bot.BotStrategy != nil {
+		strategy = *bot.BotStrategy
+	}
+
+	switch strategy {
+	case BotStrategyRandom:
+		orderClause = "ORDER BY random()"
+	case BotStrategyPositionalNeed:
+		if group, err := h.mostNeededPositionGroup(draft.ID, bot.ID); err != nil {
+			return 0, err
+		} else if group != "" {
+			conditions = append(conditions, fmt.Sprintf("position_short_label = ANY($%d)", len(args)+1))
+			args = append(args, positionLabelsArray(group))
+		}
+	}
+
+	query := "SELECT id FROM players WHERE " + strings.Join(conditions, " AND ") + " " + orderClause + " LIMIT 1"
+
+	var playerID int
+	err = h.db.Get(&playerID, query, args...)
+	if err != nil && strategy == BotStrategyPositionalNeed {
+		// Nothing left for the needed position, take the best available instead
+		bestAvailable := bot
+		bestAvailableStrategy := BotStrategyBestAvailable
+		bestAvailable.BotStrategy = &bestAvailableStrategy
+		return h.chooseBotPick(draft, bestAvailable)
+	}
+	return playerID, err
+}
+
+// mostNeededPositionGroup returns the position group furthest below its target
+// in a bot's squad, or "" if every target is met
+func (h *Handler) mostNeededPositionGroup(draftID, participantID int) (string, error) {
+	var positions []string
+	err := h.db.Select(&positions, `
+		SELECT COALESCE(p.position_short_label, '')
+		FROM draft_picks dp
+		JOIN drafts d ON dp.draft_id = d.id
+		JOIN players p ON dp.player_id = p.id AND p.dataset = d.dataset
+		WHERE dp.draft_id = $1 AND dp.participant_id = $2
+	`, draftID, participantID)
+	if err != nil {
+		return "", err
+	}
+
+	counts := make(map[string]int)
+	for _, position := range positions {
+		counts[positionGroupOf(position)]++
+	}
+
+	neededGroup, biggestGap := "", 0
+	for _, group := range []string{"GK", "DEF", "MID", "FWD"} {
+		if gap := botSquadTargets[group] - counts[group]; gap > biggestGap {
+			neededGroup, biggestGap = group, gap
+		}
+	}
+	return neededGroup, nil
+}
+
+// positionGroupOf returns the group a position label belongs to, or ""
+func positionGroupOf(position string) string {
+	for group, labels := range positionGroups {
+		if containsString(labels, position) {
+			return group
+		}
+	}
+	return ""
+}
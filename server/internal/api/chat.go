@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxChatMessageLength = 500
+
+type ChatMessage struct {
+	Message string `json:"message"`
+}
+
+// resultCommandPattern matches "/result <home> <homeScore>-<awayScore> <away>"
+var resultCommandPattern = regexp.MustCompile(`^/result\s+(.+?)\s+(\d+)\s*-\s*(\d+)\s+(.+)$`)
+
+func (h *Handler) handleChat(client *DraftClient, data interface{}) {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Chat marshal error: %v", err)
+		return
+	}
+
+	var chatMsg ChatMessage
+	if err := json.Unmarshal(dataBytes, &chatMsg); err != nil {
+		log.Printf("Chat unmarshal error: %v", err)
+		return
+	}
+
+	text := strings.TrimSpace(chatMsg.Message)
+	if text == "" {
+		return
+	}
+
+	if client.ParticipantName == "" {
+		client.sendMessage("chatError", map[string]string{"error": "join the room before chatting"})
+		return
+	}
+
+	if len(text) > maxChatMessageLength {
+		client.sendMessage("chatError", map[string]string{"error": fmt.Sprintf("message is longer than %d characters", maxChatMessageLength)})
+		return
+	}
+
+	if strings.HasPrefix(text, "/") {
+		h.handleChatCommand(client, text)
+		return
+	}
+
+	broadcastMessage(client.Room.DraftCode, "chat", map[string]interface{}{
+		"participantName": client.ParticipantName,
+		"message":         text,
+		"sentAt":          time.Now(),
+	})
+}
+
+// handleChatCommand runs a slash command typed into the room chat
+func (h *Handler) handleChatCommand(client *DraftClient, text string) {
+	command := strings.Fields(text)[0]
+
+	switch command {
+	case "/result":
+		req, err := parseResultCommand(text)
+		if err != nil {
+			client.sendMessage("chatError", map[string]string{"error": err.Error()})
+			return
+		}
+		req.RecordedBy = client.ParticipantName
+
+		match, err := h.processMatchResult(client.Room.DraftCode, req)
+		if err != nil {
+			client.sendMessage("chatError", map[string]string{"error": err.Error()})
+			return
+		}
+
+		verb := "recorded"
+		if match.Status == MatchPending {
+			verb = "submitted"
+		}
+		broadcastMessage(client.Room.DraftCode, "chat", map[string]interface{}{
+			"participantName": client.ParticipantName,
+			"message":         fmt.Sprintf("%s %s %d - %d %s", verb, match.HomeTeamName, match.HomeScore, match.AwayScore, match.AwayTeamName),
+			"sentAt":          time.Now(),
+			"system":          true,
+		})
+		BroadcastTournamentStateToRoom(h.db, client.Room.DraftCode)
+	default:
+		client.sendMessage("chatError", map[string]string{"error": fmt.Sprintf("unknown command %s", command)})
+	}
+}
+
+// parseResultCommand turns "/result alice 3-1 bob" into a match request
+func parseResultCommand(text string) (RecordMatchRequest, error) {
+	var req RecordMatchRequest
+
+	parts := resultCommandPattern.FindStringSubmatch(text)
+	if parts == nil {
+		return req, fmt.Errorf("usage: /result <home> <homeScore>-<awayScore> <away>")
+	}
+
+	homeScore, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return req, fmt.Errorf("invalid home score %q", parts[2])
+	}
+	awayScore, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return req, fmt.Errorf("invalid away score %q", parts[3])
+	}
+
+	req.HomeTeamName = strings.TrimSpace(parts[1])
+	req.AwayTeamName = strings.TrimSpace(parts[4])
+	req.HomeScore = homeScore
+	req.AwayScore = awayScore
+
+	return req, nil
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// maxPlayerChemistry is the most chemistry a single player can contribute
+const maxPlayerChemistry = 3
+
+// chemistryThresholds are how many squadmates (the player included) must share
+// a club, league or nation for each chemistry point it gives
+var chemistryThresholds = map[string][]int{
+	"club":   {2, 5, 7},
+	"league": {3, 5, 8},
+	"nation": {2, 5, 8},
+}
+
+type PlayerChemistry struct {
+	PlayerID int `json:"playerId"`
+	Points   int `json:"points"` // 0-3
+}
+
+// SquadChemistry scores the club, league and nation links within a squad
+type SquadChemistry struct {
+	Score    int               `json:"score"`
+	MaxScore int               `json:"maxScore"`
+	Players  []PlayerChemistry `json:"players"`
+}
+
+// calculateSquadChemistry gives each player points for the squadmates they
+// share a club, league or nation with, capped at maxPlayerChemistry
+func calculateSquadChemistry(players []squadPlayer) SquadChemistry {
+	counts := map[string]map[string]int{"club": {}, "league": {}, "nation": {}}
+	for _, player := range players {
+		for link, label := range chemistryLabels(player) {
+			counts[link][label]++
+		}
+	}
+
+	chemistry := SquadChemistry{
+		MaxScore: maxPlayerChemistry * len(players),
+		Players:  make([]PlayerChemistry, 0, len(players)),
+	}
+	for _, player := range players {
+		points := 0
+		for link, label := range chemistryLabels(player) {
+			for _, threshold := range chemistryThresholds[link] {
+				if counts[link][label] >= threshold {
+					points++
+				}
+			}
+		}
+		points = min(points, maxPlayerChemistry)
+
+		chemistry.Score += points
+		chemistry.Players = append(chemistry.Players, PlayerChemistry{PlayerID: player.PlayerID, Points: points})
+	}
+	return chemistry
+}
+
+// chemistryLabels returns the club, league and nation a player links on
+func chemistryLabels(player squadPlayer) map[string]string {
+	labels := make(map[string]string, 3)
+	if player.Club != nil && *player.Club != "" {
+		labels["club"] = *player.Club
+	}
+	if player.League != nil && *player.League != "" {
+		labels["league"] = *player.League
+	}
+	if player.Nationality != nil && *player.Nationality != "" {
+		labels["nation"] = *player.Nationality
+	}
+	return labels
+}
+
+// squadChemistryByParticipant scores every participant's squad, keyed by name
+func squadChemistryByParticipant(q sqlx.Queryer, draftID int, participants []database.DraftParticipant) (map[string]SquadChemistry, error) {
+	squads, err := loadSquadPlayers(q, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	chemistry := make(map[string]SquadChemistry, len(participants))
+	for _, participant := range participants {
+		chemistry[participant.Name] = calculateSquadChemistry(squads[participant.ID])
+	}
+	return chemistry, nil
+}
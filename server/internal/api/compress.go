@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minCompressSize is the smallest response worth gzipping, below it the
+// gzip header and CPU cost outweigh the savings
+const minCompressSize = 1024
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// CompressResponses gzips responses for clients that accept it. Player
+// listings are large, repetitive JSON, so they shrink several times over.
+// WebSocket upgrades and event streams are passed through untouched, as is
+// any response flushed before it was big enough to compress.
+func CompressResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Header.Get("Upgrade") != "" ||
+			strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, status: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptsGzip checks an Accept-Encoding header for gzip that isn't refused
+// with q=0
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		value, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		if !ok {
+			return true
+		}
+		q, err := strconv.ParseFloat(value, 64)
+		return err == nil && q > 0
+	}
+	return false
+}
+
+// compressWriter holds back the start of the body until it knows whether the
+// response is big enough to compress, then either gzips or passes it through
+type compressWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffer      bytes.Buffer
+	gz          *gzip.Writer
+	passThrough bool
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+
+	// Bodiless and already encoded responses go out as they are
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		cw.Header().Get("Content-Encoding") != "" {
+		cw.passThrough = true
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.passThrough {
+		return cw.ResponseWriter.Write(p)
+	}
+	if cw.gz != nil {
+		return cw.gz.Write(p)
+	}
+
+	cw.buffer.Write(p)
+	if cw.buffer.Len() >= minCompressSize {
+		if err := cw.startGzip(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what has been written so far. A response not yet compressing
+// is sent uncompressed from here on, as a handler that flushes early, such as
+// an event stream, wants each write to go out as it is.
+func (cw *compressWriter) Flush() {
+	if cw.gz != nil {
+		cw.gz.Flush()
+	} else if !cw.passThrough {
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+		if !cw.passThrough {
+			cw.passThrough = true
+			cw.ResponseWriter.WriteHeader(cw.status)
+			cw.ResponseWriter.Write(cw.buffer.Bytes())
+			cw.buffer.Reset()
+		}
+	}
+
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// startGzip sends the headers for a gzipped body and flushes the buffer
+// through the compressor
+func (cw *compressWriter) startGzip() error {
+	header := cw.Header()
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+	// The ETag is computed from the uncompressed body, so it only stays
+	// valid as a weak validator
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	cw.gz = gzipWriters.Get().(*gzip.Writer)
+	cw.gz.Reset(cw.ResponseWriter)
+	_, err := cw.gz.Write(cw.buffer.Bytes())
+	cw.buffer.Reset()
+	return err
+}
+
+// close finishes the response: it flushes the gzip stream, or writes out a
+// body too small to have been compressed
+func (cw *compressWriter) close() {
+	if cw.gz != nil {
+		cw.gz.Close()
+		gzipWriters.Put(cw.gz)
+		return
+	}
+	if cw.passThrough {
+		return
+	}
+	if !cw.wroteHeader && cw.buffer.Len() == 0 {
+		// The handler wrote nothing, net/http sends the default 200
+		return
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	cw.ResponseWriter.Write(cw.buffer.Bytes())
+}
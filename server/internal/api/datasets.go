@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// datasetNamePattern keeps dataset names short labels such as FC25
+var datasetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,20}$`)
+
+type PlayerDataset struct {
+	Name        string `db:"dataset" json:"name"`
+	PlayerCount int    `db:"player_count" json:"playerCount"`
+	IsDefault   bool   `db:"-" json:"isDefault"` // used when a request doesn't name a dataset
+}
+
+type GetPlayerDatasetsResponse struct {
+	Datasets []PlayerDataset `json:"datasets"`
+}
+
+func (h *Handler) getPlayerDatasets(w http.ResponseWriter, r *http.Request) {
+	log.Printf("GET /api/players/datasets")
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	datasets := []PlayerDataset{}
+	err := h.db.Select(&datasets, `
+		SELECT dataset, COUNT(*) AS player_count
+		FROM players GROUP BY dataset ORDER BY dataset
+	`)
+	if err != nil {
+		log.Printf("Get player datasets error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	for i := range datasets {
+		datasets[i].IsDefault = datasets[i].Name == h.config.PlayerDataset
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GetPlayerDatasetsResponse{Datasets: datasets})
+}
+
+// requestedDataset returns the dataset named by the ?dataset= parameter, or
+// the configured default
+func (h *Handler) requestedDataset(r *http.Request) (string, error) {
+	dataset := r.URL.Query().Get("dataset")
+	if dataset == "" {
+		return h.config.PlayerDataset, nil
+	}
+	if !datasetNamePattern.MatchString(dataset) {
+		return "", newStatusError(http.StatusBadRequest, "dataset must be 1-20 letters, digits, dashes or underscores")
+	}
+	return dataset, nil
+}
+
+// checkDatasetExists returns an error unless some players belong to the dataset
+func checkDatasetExists(q sqlx.Queryer, dataset string) error {
+	var exists bool
+	if err := sqlx.Get(q, &exists, "SELECT EXISTS(SELECT 1 FROM players WHERE dataset = $1)", dataset); err != nil {
+		return err
+	}
+	if !exists {
+		return newStatusError(http.StatusBadRequest, "Unknown player dataset "+dataset)
+	}
+	return nil
+}
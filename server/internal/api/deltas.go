@@ -0,0 +1,159 @@
+package api
+
+import (
+	"log"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Clients get the full draftState when they join and keep it current from
+// these smaller events, rather than reloading every pick after each change.
+// Like every broadcast they carry the room's sequence, so a client that
+// notices a gap can resume or rejoin.
+
+// madePick is a committed pick with the draft and picker as they stand after
+// it, built by processPick from what it already loaded
+type madePick struct {
+	Pick        PickWithPlayer
+	Participant database.DraftParticipant
+	Draft       database.Draft
+}
+
+// pickPayload joins a pick with its picker and player the way loadDraftPicks
+// and getPicks present it
+func pickPayload(pick database.DraftPick, player database.Player, participantName string) PickWithPlayer {
+	return PickWithPlayer{
+		DraftPick:       pick,
+		ParticipantName: participantName,
+		Player: PickPlayer{
+			ID:                  player.ID,
+			FirstName:           player.FirstName,
+			LastName:            player.LastName,
+			CommonName:          player.CommonName,
+			OverallRating:       player.OverallRating,
+			PositionShortLabel:  player.PositionShortLabel,
+			AlternatePositions:  player.AlternatePositions,
+			TeamLabel:           player.TeamLabel,
+			TeamImageURL:        player.TeamImageURL,
+			LeagueName:          player.LeagueName,
+			NationalityLabel:    player.NationalityLabel,
+			NationalityImageURL: player.NationalityImageURL,
+			AvatarURL:           player.AvatarURL,
+			ShieldURL:           player.ShieldURL,
+		},
+	}
+}
+
+// broadcastPickMade sends a pick the moment it commits, along with the
+// draft's new turn pointer and the picker's updated quotas. It needs no
+// queries of its own, so clients render the pick without waiting on a reload.
+func broadcastPickMade(db *sqlx.DB, draftCode string, made *madePick) {
+	draft, participant := made.Draft, made.Participant
+
+	var currentPicker *int
+	if draft.Status == "active" {
+		picker := calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+		currentPicker = &picker
+	}
+
+	broadcastMessage(draftCode, "pickMade", map[string]interface{}{
+		"pick":          made.Pick,
+		"participant":   participant,
+		"draft":         draft,
+		"currentPicker": currentPicker,
+	})
+
+	// The last pick completes the draft
+	if draft.Status == "completed" {
+		broadcastStatusChanged(draftCode, draft)
+	}
+
+	notifyQuotaFilled(draftCode, draft, participant, made.Pick.PlayerRatingTier)
+	notifyYourTurn(db, draftCode, draft)
+}
+
+// notifyQuotaFilled warns the picker privately when their pick used up the
+// last of a quota, so their client can grey out the tier before they try it
+func notifyQuotaFilled(draftCode string, draft database.Draft, participant database.DraftParticipant, tier string) {
+	if participant.IsBot {
+		return
+	}
+
+	var used, quota int
+	switch tier {
+	case "85-89":
+		used, quota = participant.Picks8589, draft.Quota8589
+	case "80-84":
+		used, quota = participant.Picks8084, draft.Quota8084
+	case "75-79":
+		used, quota = participant.Picks7579+participant.PicksUpTo74, draft.QuotaUpTo79
+	default:
+		return
+	}
+	if used >= quota {
+		sendParticipantMessage(draftCode, participant.Name, "quotaWarning", map[string]interface{}{
+			"tier":  tier,
+			"used":  used,
+			"quota": quota,
+		})
+	}
+}
+
+// notifyAutoPicked tells a participant privately that the pick timer ran out
+// and chose for them
+func notifyAutoPicked(draftCode, participantName string, playerID int) {
+	sendParticipantMessage(draftCode, participantName, "autoPicked", map[string]interface{}{
+		"playerId": playerID,
+	})
+}
+
+// notifyYourTurn tells the participant now on the clock it is their turn, so
+// their client can play a sound without diffing the state. Blind rounds have
+// everyone picking at once and bots need no telling.
+func notifyYourTurn(db *sqlx.DB, draftCode string, draft database.Draft) {
+	if draft.Status != "active" || draft.BlindMode {
+		return
+	}
+
+	currentPicker := calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+
+	var participant database.DraftParticipant
+	err := db.Get(&participant, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 AND draft_order = $2
+	`, draft.ID, currentPicker)
+	if err != nil {
+		log.Printf("Get participant on the clock in draft %s error: %v", draftCode, err)
+		return
+	}
+	if participant.IsBot {
+		return
+	}
+
+	sendParticipantMessage(draftCode, participant.Name, "yourTurn", map[string]interface{}{
+		"roundNumber":  draft.CurrentRound,
+		"pickInRound":  draft.CurrentPickInRound,
+		"pickDeadline": draft.PickDeadline,
+	})
+}
+
+// broadcastParticipantJoined announces a new participant to the lobby
+func broadcastParticipantJoined(draftCode string, draft database.Draft, participant database.DraftParticipant) {
+	broadcastMessage(draftCode, "participantJoined", map[string]interface{}{
+		"participant":    participant,
+		"draft":          draft,
+		"remainingSeats": remainingSeats(draft),
+	})
+}
+
+// broadcastStatusChanged announces that a draft moved to a new status, such
+// as starting, completing or going into its tournament
+func broadcastStatusChanged(draftCode string, draft database.Draft) {
+	roomManager.SetFinished(draftCode, draft.Status == "completed" || draft.Status == "tournament")
+	broadcastMessage(draftCode, "statusChanged", map[string]interface{}{
+		"status": draft.Status,
+		"draft":  draft,
+	})
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SetDraftOrderRequest struct {
+	AdminName string   `json:"adminName"`
+	Order     []string `json:"order"` // participant names, first pick first
+}
+
+type SetDraftOrderResponse struct {
+	Draft        database.Draft              `json:"draft"`
+	Participants []database.DraftParticipant `json:"participants"`
+}
+
+// saveDraftOrder persists the DraftOrder of every participant. Orders are
+// moved to negative values first so the swap never collides on draft_order.
+func (h *Handler) saveDraftOrder(tx *sqlx.Tx, participants []database.DraftParticipant) error {
+	for i, participant := range participants {
+		_, err := tx.Exec("UPDATE draft_participants SET draft_order = $1 WHERE id = $2", -(i + 1), participant.ID)
+		if err != nil {
+			return fmt.Errorf("set negative draft order: %w", err)
+		}
+	}
+
+	for _, participant := range participants {
+		_, err := tx.Exec("UPDATE draft_participants SET draft_order = $1 WHERE id = $2", participant.DraftOrder, participant.ID)
+		if err != nil {
+			return fmt.Errorf("set final draft order: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// setDraftOrder lets the admin choose the draft order explicitly before the
+// draft starts. startDraft then uses it instead of shuffling.
+func (h *Handler) setDraftOrder(w http.ResponseWriter, r *http.Request, code string) {
+	var req SetDraftOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Set draft order decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Get draft and verify admin
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for set order error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can set the draft order", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.Status != "waiting" {
+		http.Error(w, "Draft has already started or is completed", http.StatusBadRequest)
+		return
+	}
+
+	// Get all participants
+	var participants []database.DraftParticipant
+	err = tx.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Get participants for set order error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	// Every participant must appear exactly once
+	if len(req.Order) != len(participants) {
+		http.Error(w, fmt.Sprintf("Order must list all %d participants", len(participants)), http.StatusBadRequest)
+		return
+	}
+
+	positions := make(map[string]int, len(req.Order))
+	for i, name := range req.Order {
+		if _, duplicate := positions[name]; duplicate {
+			http.Error(w, fmt.Sprintf("Participant %s appears more than once", name), http.StatusBadRequest)
+			return
+		}
+		positions[name] = i + 1
+	}
+
+	for i := range participants {
+		position, ok := positions[participants[i].Name]
+		if !ok {
+			http.Error(w, fmt.Sprintf("Participant %s is missing from the order", participants[i].Name), http.StatusBadRequest)
+			return
+		}
+		participants[i].DraftOrder = position
+	}
+
+	if err := h.saveDraftOrder(tx, participants); err != nil {
+		log.Printf("Save draft order error: %v", err)
+		http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = tx.Exec("UPDATE drafts SET order_locked = true WHERE id = $1", draft.ID)
+	if err != nil {
+		log.Printf("Lock draft order error: %v", err)
+		http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventOrderSet, req.AdminName, map[string][]string{"order": draftOrderNames(participants)}); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+		return
+	}
+
+	// Update draft object and return participants in their new order
+	draft.OrderLocked = true
+	ordered := make([]database.DraftParticipant, len(participants))
+	for _, participant := range participants {
+		ordered[participant.DraftOrder-1] = participant
+	}
+
+	log.Printf("Admin set draft order for %s: %v", code, req.Order)
+
+	// Broadcast draft state update to all WebSocket clients
+	if h.broadcastFunc != nil {
+		go h.broadcastFunc(h.db, code)
+	}
+
+	response := SetDraftOrderResponse{
+		Draft:        draft,
+		Participants: ordered,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+type PreviewDraftOrderRequest struct {
+	AdminName string `json:"adminName"`
+}
+
+// previewDraftOrder shuffles a proposed draft order without starting the
+// draft. The admin can re-roll as often as needed and then start the draft
+// with keepOrder to lock the last preview in.
+func (h *Handler) previewDraftOrder(w http.ResponseWriter, r *http.Request, code string) {
+	var req PreviewDraftOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Preview draft order decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Get draft and verify admin
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for order preview error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can preview the draft order", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.Status != "waiting" {
+		http.Error(w, "Draft has already started or is completed", http.StatusBadRequest)
+		return
+	}
+
+	// Get all participants
+	var participants []database.DraftParticipant
+	err = tx.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Get participants for order preview error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.shuffleParticipants(participants, nil); err != nil {
+		log.Printf("Shuffle participants error: %v", err)
+		http.Error(w, "Failed to randomize draft order", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.saveDraftOrder(tx, participants); err != nil {
+		log.Printf("Save draft order error: %v", err)
+		http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+		return
+	}
+
+	// A preview replaces any manually set order
+	_, err = tx.Exec("UPDATE drafts SET order_locked = false WHERE id = $1", draft.ID)
+	if err != nil {
+		log.Printf("Unlock draft order error: %v", err)
+		http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+		return
+	}
+
+	draft.OrderLocked = false
+	ordered := make([]database.DraftParticipant, len(participants))
+	for _, participant := range participants {
+		ordered[participant.DraftOrder-1] = participant
+	}
+
+	log.Printf("Previewed draft order for %s", code)
+
+	// Let everyone in the lobby see the proposed order
+	broadcastMessage(code, "orderPreview", map[string]interface{}{
+		"participants": ordered,
+	})
+	if h.broadcastFunc != nil {
+		go h.broadcastFunc(h.db, code)
+	}
+
+	response := SetDraftOrderResponse{
+		Draft:        draft,
+		Participants: ordered,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Order modes control how the pick order changes from round to round
+const (
+	OrderModeRotation           = "rotation" // the first pick moves one seat along each round
+	OrderModeSnake              = "snake"
+	OrderModeThirdRoundReversal = "3rr" // snake, except round 3 repeats round 2
+)
+
+func isValidOrderMode(mode string) bool {
+	return mode == OrderModeRotation || mode == OrderModeSnake || mode == OrderModeThirdRoundReversal
+}
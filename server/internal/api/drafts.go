@@ -3,6 +3,8 @@ package api
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"math/big"
 	"net/http"
@@ -10,28 +12,52 @@ import (
 	"time"
 
 	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type CreateDraftRequest struct {
-	Name      string `json:"name"`
-	AdminName string `json:"adminName"`
+	Name       string `json:"name"`
+	AdminName  string `json:"adminName"`
+	TemplateID *int   `json:"templateId,omitempty"` // start from a saved settings template
+	// MaxParticipants caps how many participants (bots included) can join
+	MaxParticipants *int `json:"maxParticipants,omitempty"`
+	// JoinPassword makes the draft private, joining then requires it
+	JoinPassword string `json:"joinPassword,omitempty"`
+	// BlindMode has everyone submit their pick for a round at the same time
+	BlindMode bool `json:"blindMode"`
+	// PackSize turns on pack mode, each turn picks from this many random players
+	PackSize *int `json:"packSize,omitempty"`
+	// Dataset is the player dataset to draft from, the server default if empty
+	Dataset string `json:"dataset,omitempty"`
+	DraftSettingsInput
 }
 
 type CreateDraftResponse struct {
-	Draft database.Draft `json:"draft"`
+	Draft       database.Draft            `json:"draft"`
+	Participant database.DraftParticipant `json:"participant"`
+	Token       string                    `json:"token"`
 }
 
 type JoinDraftRequest struct {
 	Name string `json:"name"`
+	// Token lets a participant rejoin under their existing name
+	Token    string `json:"token,omitempty"`
+	Password string `json:"password,omitempty"` // join password of a private draft
 }
 
 type JoinDraftResponse struct {
 	Draft       database.Draft            `json:"draft"`
 	Participant database.DraftParticipant `json:"participant"`
+	Token       string                    `json:"token"`
 }
 
 type StartDraftRequest struct {
-	AdminName string `json:"adminName"`
+	AdminName string         `json:"adminName"`
+	Seeds     map[string]int `json:"seeds,omitempty"` // participant name -> fixed draft position
+	KeepOrder bool           `json:"keepOrder"`       // lock in the current (previewed) order instead of shuffling
+	// RequireReady refuses to start until every participant has readied up
+	RequireReady bool `json:"requireReady"`
 }
 
 type StartDraftResponse struct {
@@ -42,9 +68,16 @@ type StartDraftResponse struct {
 type RecordMatchRequest struct {
 	HomeTeamName string `json:"homeTeamName"`
 	AwayTeamName string `json:"awayTeamName"`
-	HomeScore    int    `json:"homeScore"`
+	HomeScore    int    `json:"homeScore"` // after 90 minutes
 	AwayScore    int    `json:"awayScore"`
 	RecordedBy   string `json:"recordedBy"`
+	ForfeitedBy  string `json:"forfeitedBy"` // a team that forfeits loses by the walkover score, which replaces the scores given
+
+	Goals []MatchGoalRequest `json:"goals"` // optional scorers, they can also be added later
+
+	// Extra time and penalties, for a draw that needs a winner
+	database.ExtraTimeResult
+	database.MatchCards
 }
 
 type RecordMatchResponse struct {
@@ -52,10 +85,18 @@ type RecordMatchResponse struct {
 }
 
 type TournamentData struct {
-	Draft        database.Draft              `json:"draft"`
-	Participants []database.DraftParticipant `json:"participants"`
-	Matches      []database.Match            `json:"matches"`
-	Standings    []TeamStanding              `json:"standings"`
+	Draft          database.Draft              `json:"draft"`
+	Participants   []database.DraftParticipant `json:"participants"`
+	Matches        []database.Match            `json:"matches"`
+	PendingMatches []database.Match            `json:"pendingMatches"` // submitted by a team, waiting for their opponent
+	Goals          []database.MatchGoal        `json:"goals"`          // scorers in confirmed matches
+	Fixtures       []database.Fixture          `json:"fixtures"`       // round-robin schedule, empty before the tournament starts
+	Bracket        []database.KnockoutTie      `json:"bracket"`        // knockout ties, empty for league tournaments
+	Groups         []GroupStandings            `json:"groups"`         // group tables, empty unless the format is groups
+	Standings      []TeamStanding              `json:"standings"`
+	HomeTable      []VenueStanding             `json:"homeTable"` // home matches only
+	AwayTable      []VenueStanding             `json:"awayTable"` // away matches only
+	Chemistry      map[string]SquadChemistry   `json:"chemistry"` // participant name -> squad chemistry
 }
 
 type TeamStanding struct {
@@ -66,19 +107,54 @@ type TeamStanding struct {
 	Draws          int    `json:"draws"`
 	Losses         int    `json:"losses"`
 	Points         int    `json:"points"`
+	BonusPoints    int    `json:"bonusPoints"` // included in points
 	GoalsFor       int    `json:"goalsFor"`
 	GoalsAgainst   int    `json:"goalsAgainst"`
 	GoalDifference int    `json:"goalDifference"`
+	YellowCards    int    `json:"yellowCards"`
+	RedCards       int    `json:"redCards"`
+	FairPlay       int    `json:"fairPlay"` // card points, fewer is better; the last tiebreaker
+
+	// The same record split by home and away matches
+	Home TeamRecord `json:"home"`
+	Away TeamRecord `json:"away"`
+
+	// Average league position of opponents faced and still to face
+	StrengthOfSchedule          *float64 `json:"strengthOfSchedule"`
+	RemainingStrengthOfSchedule *float64 `json:"remainingStrengthOfSchedule"`
 }
 
 type StartTournamentRequest struct {
-	AdminName string `json:"adminName"`
+	AdminName        string `json:"adminName"`
+	DoubleRoundRobin bool   `json:"doubleRoundRobin"` // play every pairing home and away
+	Format           string `json:"format"`           // league (default), knockout or groups
+	Seeding          string `json:"seeding"`          // knockout and group seeds by draft_order (default) or standings
+	GroupCount       int    `json:"groupCount"`       // groups format, default 2
+	GroupQualifiers  int    `json:"groupQualifiers"`  // groups format: teams per group going through, default 2
+
+	// Points rules, 3-1-0 when left out
+	PointsWin      *int `json:"pointsWin"`
+	PointsDraw     *int `json:"pointsDraw"`
+	PointsLoss     *int `json:"pointsLoss"`
+	BonusGoals     int  `json:"bonusGoals"`     // a bonus point for scoring at least this many, 0 for none
+	BonusCloseLoss bool `json:"bonusCloseLoss"` // a bonus point for losing by one goal
+	ForfeitGoals   *int `json:"forfeitGoals"`   // the walkover score, 3-0 when left out
 }
 
 type StartTournamentResponse struct {
 	Draft database.Draft `json:"draft"`
 }
 
+type TransferAdminRequest struct {
+	AdminName    string `json:"adminName"`
+	NewAdminName string `json:"newAdminName"`
+}
+
+type TransferAdminResponse struct {
+	Draft        database.Draft              `json:"draft"`
+	Participants []database.DraftParticipant `json:"participants"`
+}
+
 // generateDraftCode creates a random 8-character draft code
 func (h *Handler) generateDraftCode() (string, error) {
 	const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -95,10 +171,35 @@ func (h *Handler) generateDraftCode() (string, error) {
 	return string(code), nil
 }
 
+// generateUniqueDraftCode generates draft codes until it finds an unused one
+func (h *Handler) generateUniqueDraftCode() (string, error) {
+	for attempts := 0; attempts < 10; attempts++ {
+		code, err := h.generateDraftCode()
+		if err != nil {
+			return "", err
+		}
+
+		// Check if code already exists
+		var exists bool
+		err = h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM drafts WHERE code = $1)", code)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("no unique code after 10 attempts")
+}
+
 func (h *Handler) handleDrafts(w http.ResponseWriter, r *http.Request) {
 	log.Printf("%s /api/drafts", r.Method)
 
 	switch r.Method {
+	case http.MethodGet:
+		h.listParticipantDrafts(w, r)
 	case http.MethodPost:
 		h.createDraft(w, r)
 	default:
@@ -107,6 +208,11 @@ func (h *Handler) handleDrafts(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) createDraft(w http.ResponseWriter, r *http.Request) {
+	if err := h.maintenanceError(); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
 	var req CreateDraftRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("Create draft decode error: %v", err)
@@ -119,34 +225,60 @@ func (h *Handler) createDraft(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate unique draft code
-	var code string
-	var err error
-	for attempts := 0; attempts < 10; attempts++ {
-		code, err = h.generateDraftCode()
-		if err != nil {
-			log.Printf("Generate code error: %v", err)
-			http.Error(w, "Failed to generate draft code", http.StatusInternalServerError)
+	if req.MaxParticipants != nil && *req.MaxParticipants < 2 {
+		http.Error(w, "maxParticipants must be at least 2", http.StatusBadRequest)
+		return
+	}
+
+	if req.PackSize != nil {
+		if *req.PackSize < minPackSize || *req.PackSize > maxPackSize {
+			http.Error(w, fmt.Sprintf("packSize must be between %d and %d", minPackSize, maxPackSize), http.StatusBadRequest)
 			return
 		}
-
-		// Check if code already exists
-		var exists bool
-		err = h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM drafts WHERE code = $1)", code)
-		if err != nil {
-			log.Printf("Check code exists error: %v", err)
-			http.Error(w, "Database error", http.StatusInternalServerError)
+		if req.BlindMode {
+			http.Error(w, "Pack mode cannot be combined with blind mode", http.StatusBadRequest)
 			return
 		}
+	}
 
-		if !exists {
-			break
-		}
+	settings, err := h.resolveDraftSettings(req.TemplateID, req.DraftSettingsInput)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if req.Dataset == "" {
+		req.Dataset = h.config.PlayerDataset
+	}
+	if err := checkDatasetExists(h.db, req.Dataset); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	// Generate unique draft code
+	code, err := h.generateUniqueDraftCode()
+	if err != nil {
+		log.Printf("Generate unique code error: %v", err)
+		http.Error(w, "Failed to generate draft code", http.StatusInternalServerError)
+		return
+	}
 
-		if attempts == 9 {
-			http.Error(w, "Failed to generate unique code", http.StatusInternalServerError)
+	token, err := generateParticipantToken()
+	if err != nil {
+		log.Printf("Generate participant token error: %v", err)
+		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
+		return
+	}
+
+	var passwordHash *string
+	if req.JoinPassword != "" {
+		hash, err := hashJoinPassword(req.JoinPassword)
+		if err != nil {
+			log.Printf("Hash join password error: %v", err)
+			http.Error(w, "Failed to create draft", http.StatusInternalServerError)
 			return
 		}
+		passwordHash = &hash
 	}
 
 	// Start transaction
@@ -161,11 +293,16 @@ func (h *Handler) createDraft(w http.ResponseWriter, r *http.Request) {
 	// Create draft
 	var draft database.Draft
 	err = tx.Get(&draft, `
-		INSERT INTO drafts (code, name, admin_name, participant_count) 
-		VALUES ($1, $2, $3, 1) 
-		RETURNING id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		          total_rounds, participant_count, created_at, started_at, completed_at
-	`, code, req.Name, req.AdminName)
+		INSERT INTO drafts (code, name, admin_name, participant_count, total_rounds, quota_85_89, quota_80_84,
+		                    quota_up_to_79, pick_timer_seconds, pool_leagues, pool_nationalities, max_participants,
+		                    join_password_hash, blind_mode, order_mode, pack_size, quota_gk, dataset, pool_gender,
+		                    ban_special_cards, min_gk) 
+		VALUES ($1, $2, $3, 1, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20) 
+		RETURNING `+database.DraftColumns+`
+	`, code, req.Name, req.AdminName, settings.TotalRounds, settings.Quota8589, settings.Quota8084,
+		settings.QuotaUpTo79, settings.PickTimerSeconds, settings.PoolLeagues, settings.PoolNationalities,
+		req.MaxParticipants, passwordHash, req.BlindMode, settings.OrderMode, req.PackSize, settings.QuotaGK, req.Dataset,
+		settings.PoolGender, settings.BanSpecialCards, settings.MinGK)
 	if err != nil {
 		log.Printf("Create draft error: %v", err)
 		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
@@ -175,17 +312,22 @@ func (h *Handler) createDraft(w http.ResponseWriter, r *http.Request) {
 	// Add admin as first participant
 	var participant database.DraftParticipant
 	err = tx.Get(&participant, `
-		INSERT INTO draft_participants (draft_id, name, draft_order, is_admin) 
-		VALUES ($1, $2, 1, true) 
-		RETURNING id, draft_id, name, draft_order, is_admin, joined_at, 
-		          picks_85_89, picks_80_84, picks_75_79, picks_up_to_74
-	`, draft.ID, req.AdminName)
+		INSERT INTO draft_participants (draft_id, name, draft_order, is_admin, token) 
+		VALUES ($1, $2, 1, true, $3) 
+		RETURNING `+database.ParticipantColumns+`
+	`, draft.ID, req.AdminName, token)
 	if err != nil {
 		log.Printf("Create admin participant error: %v", err)
 		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
 		return
 	}
 
+	if err := recordDraftEvent(tx, draft.ID, EventDraftCreated, req.AdminName, map[string]string{"name": draft.Name}); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
+		return
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		log.Printf("Commit transaction error: %v", err)
@@ -196,41 +338,45 @@ func (h *Handler) createDraft(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Created draft: %s (%s) with admin %s", draft.Name, draft.Code, req.AdminName)
 
 	response := CreateDraftResponse{
-		Draft: draft,
+		Draft:       draft,
+		Participant: participant,
+		Token:       token,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// shuffleParticipants randomizes the draft order of participants
-func (h *Handler) shuffleParticipants(participants []database.DraftParticipant) error {
-	// Create array of available draft orders (1, 2, 3, ...)
-	orders := make([]int, len(participants))
-	for i := range orders {
-		orders[i] = i + 1
+// shuffleParticipants randomizes the draft order of participants. Participants
+// listed in seeds are pinned to the given draft position and everyone else is
+// shuffled fairly into the remaining positions.
+func (h *Handler) shuffleParticipants(participants []database.DraftParticipant, seeds map[string]int) error {
+	// Validate seeds against the participant list
+	participantNames := make(map[string]bool, len(participants))
+	for _, participant := range participants {
+		participantNames[participant.Name] = true
 	}
 
-	// Find participant named "kak" and assign them pick order 2
-	var kakIndex = -1
-	for i, participant := range participants {
-		if participant.Name == "kak" {
-			kakIndex = i
-			break
+	seededOrders := make(map[int]bool, len(seeds))
+	for name, order := range seeds {
+		if !participantNames[name] {
+			return newStatusError(http.StatusBadRequest, fmt.Sprintf("Seeded participant %s is not in this draft", name))
+		}
+		if order < 1 || order > len(participants) {
+			return newStatusError(http.StatusBadRequest, fmt.Sprintf("Seed position %d for %s is out of range", order, name))
+		}
+		if seededOrders[order] {
+			return newStatusError(http.StatusBadRequest, fmt.Sprintf("Seed position %d is assigned more than once", order))
 		}
+		seededOrders[order] = true
 	}
 
-	// If "kak" is found and there are at least 2 participants, assign order 2 to kak
-	if kakIndex != -1 && len(participants) >= 2 {
-		participants[kakIndex].DraftOrder = 2
-		// Remove order 2 from available orders for other participants
-		availableOrders := make([]int, 0, len(orders)-1)
-		for _, order := range orders {
-			if order != 2 {
-				availableOrders = append(availableOrders, order)
-			}
+	// Collect the draft orders that are not pinned by a seed
+	orders := make([]int, 0, len(participants)-len(seeds))
+	for order := 1; order <= len(participants); order++ {
+		if !seededOrders[order] {
+			orders = append(orders, order)
 		}
-		orders = availableOrders
 	}
 
 	// Fisher-Yates shuffle the remaining orders array
@@ -243,11 +389,11 @@ func (h *Handler) shuffleParticipants(participants []database.DraftParticipant)
 		orders[i], orders[j] = orders[j], orders[i]
 	}
 
-	// Assign shuffled orders to remaining participants (excluding "kak" if already assigned)
+	// Assign seeded orders, and shuffled orders to everyone else
 	orderIndex := 0
 	for i := range participants {
-		// Skip if this is "kak" and they already have order 2 assigned
-		if i == kakIndex && participants[i].DraftOrder == 2 {
+		if order, seeded := seeds[participants[i].Name]; seeded {
+			participants[i].DraftOrder = order
 			continue
 		}
 		participants[i].DraftOrder = orders[orderIndex]
@@ -282,8 +428,7 @@ func (h *Handler) startDraft(w http.ResponseWriter, r *http.Request, code string
 	// Get draft and verify admin
 	var draft database.Draft
 	err = tx.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1 FOR UPDATE
 	`, code)
 	if err != nil {
@@ -297,6 +442,11 @@ func (h *Handler) startDraft(w http.ResponseWriter, r *http.Request, code string
 		return
 	}
 
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
 	if draft.Status != "waiting" {
 		http.Error(w, "Draft has already started or is completed", http.StatusBadRequest)
 		return
@@ -310,8 +460,7 @@ func (h *Handler) startDraft(w http.ResponseWriter, r *http.Request, code string
 	// Get all participants
 	var participants []database.DraftParticipant
 	err = tx.Select(&participants, `
-		SELECT id, draft_id, name, draft_order, is_admin, joined_at, 
-		       picks_85_89, picks_80_84, picks_75_79, picks_up_to_74
+		SELECT `+database.ParticipantColumns+`
 		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
 	`, draft.ID)
 	if err != nil {
@@ -320,54 +469,93 @@ func (h *Handler) startDraft(w http.ResponseWriter, r *http.Request, code string
 		return
 	}
 
-	// Shuffle participants (randomize draft order)
-	if err := h.shuffleParticipants(participants); err != nil {
-		log.Printf("Shuffle participants error: %v", err)
-		http.Error(w, "Failed to randomize draft order", http.StatusInternalServerError)
-		return
+	if req.RequireReady {
+		if notReady := notReadyParticipants(participants); len(notReady) > 0 {
+			http.Error(w, fmt.Sprintf("Waiting for participants to ready up: %s", strings.Join(notReady, ", ")), http.StatusBadRequest)
+			return
+		}
 	}
 
-	// First, set all draft orders to negative values to avoid conflicts
-	for i, participant := range participants {
-		_, err = tx.Exec(`
-			UPDATE draft_participants 
-			SET draft_order = $1 
-			WHERE id = $2
-		`, -(i + 1), participant.ID)
-		if err != nil {
-			log.Printf("Update participant order to negative error: %v", err)
-			http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+	if draft.OrderLocked || req.KeepOrder {
+		// The admin already set or accepted the order, keep it as it is
+		if len(req.Seeds) > 0 {
+			http.Error(w, "Seeds cannot be combined with a manually set draft order", http.StatusBadRequest)
+			return
+		}
+	} else {
+		// Shuffle participants (randomize draft order around any seeds)
+		if err := h.shuffleParticipants(participants, req.Seeds); err != nil {
+			var se *statusError
+			if errors.As(err, &se) {
+				writeStatusError(w, err)
+				return
+			}
+			log.Printf("Shuffle participants error: %v", err)
+			http.Error(w, "Failed to randomize draft order", http.StatusInternalServerError)
 			return
 		}
-	}
 
-	// Then update to the final shuffled orders
-	for _, participant := range participants {
-		_, err = tx.Exec(`
-			UPDATE draft_participants 
-			SET draft_order = $1 
-			WHERE id = $2
-		`, participant.DraftOrder, participant.ID)
-		if err != nil {
-			log.Printf("Update participant final order error: %v", err)
+		if err := h.saveDraftOrder(tx, participants); err != nil {
+			log.Printf("Save draft order error: %v", err)
 			http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
 			return
 		}
+
+		if err := recordDraftEvent(tx, draft.ID, EventOrderShuffled, req.AdminName, map[string][]string{"order": draftOrderNames(participants)}); err != nil {
+			log.Printf("Record draft event error: %v", err)
+			http.Error(w, "Failed to start draft", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Turn keepers into picks now that the order is final
+	if err := h.placeKeepers(tx, draft, participants); err != nil {
+		log.Printf("Place keepers error: %v", err)
+		http.Error(w, "Failed to place keepers", http.StatusInternalServerError)
+		return
+	}
+
+	// The first slots may already be filled by keepers
+	firstRound, firstPick, err := h.nextOpenSlot(tx, draft.ID, 1, 1, draft.ParticipantCount, draft.TotalRounds)
+	if err != nil {
+		log.Printf("Find first open slot error: %v", err)
+		http.Error(w, "Failed to start draft", http.StatusInternalServerError)
+		return
 	}
 
-	// Update draft status to active
+	// Update draft status to active (or straight to completed if keepers
+	// filled every slot)
+	status := "active"
+	if firstRound > draft.TotalRounds {
+		status = "completed"
+	}
 	now := time.Now()
 	_, err = tx.Exec(`
 		UPDATE drafts 
-		SET status = 'active', started_at = $1 
-		WHERE id = $2
-	`, now, draft.ID)
+		SET status = $1, started_at = $2, current_round = $3, current_pick_in_round = $4,
+		    completed_at = CASE WHEN $1 = 'completed' THEN $2 ELSE completed_at END
+		WHERE id = $5
+	`, status, now, firstRound, firstPick, draft.ID)
 	if err != nil {
 		log.Printf("Update draft status error: %v", err)
 		http.Error(w, "Failed to start draft", http.StatusInternalServerError)
 		return
 	}
 
+	// Start the clock for the first pick
+	deadline, err := h.setPickDeadline(tx, draft, status == "active")
+	if err != nil {
+		log.Printf("Update pick deadline error: %v", err)
+		http.Error(w, "Failed to start draft", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventDraftStarted, req.AdminName, map[string]string{"status": status}); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to start draft", http.StatusInternalServerError)
+		return
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		log.Printf("Commit transaction error: %v", err)
@@ -376,15 +564,27 @@ func (h *Handler) startDraft(w http.ResponseWriter, r *http.Request, code string
 	}
 
 	// Update draft object
-	draft.Status = "active"
+	draft.Status = status
 	draft.StartedAt = &now
+	draft.CurrentRound = firstRound
+	draft.CurrentPickInRound = firstPick
+	draft.PickDeadline = deadline
+
+	if deadline != nil {
+		h.schedulePickTimer(code, firstRound, firstPick, *deadline)
+	}
 
 	log.Printf("Started draft %s with %d participants", code, len(participants))
 
 	// Broadcast draft state update to all WebSocket clients
+	broadcastStatusChanged(code, draft)
 	if h.broadcastFunc != nil {
 		go h.broadcastFunc(h.db, code)
 	}
+	notifyYourTurn(h.db, code, draft)
+
+	// Let bots pick if one of them is first on the clock
+	go h.runBotPicks(code)
 
 	response := StartDraftResponse{
 		Draft:        draft,
@@ -408,6 +608,40 @@ func (h *Handler) startTournament(w http.ResponseWriter, r *http.Request, code s
 		return
 	}
 
+	if req.Format == "" {
+		req.Format = TournamentFormatLeague
+	}
+	if req.Format != TournamentFormatLeague && req.Format != TournamentFormatKnockout && req.Format != TournamentFormatGroups {
+		http.Error(w, "format must be league, knockout or groups", http.StatusBadRequest)
+		return
+	}
+	if req.Format == TournamentFormatKnockout && req.DoubleRoundRobin {
+		http.Error(w, "doubleRoundRobin only applies to league and group tournaments", http.StatusBadRequest)
+		return
+	}
+	if req.Format == TournamentFormatGroups {
+		if req.GroupCount == 0 {
+			req.GroupCount = defaultGroupCount
+		}
+		if req.GroupQualifiers == 0 {
+			req.GroupQualifiers = defaultGroupQualifiers
+		}
+	} else {
+		req.GroupCount, req.GroupQualifiers = 0, 0
+	}
+	if req.Seeding == "" {
+		req.Seeding = SeedingDraftOrder
+	}
+	if req.Seeding != SeedingDraftOrder && req.Seeding != SeedingStandings {
+		http.Error(w, "seeding must be draft_order or standings", http.StatusBadRequest)
+		return
+	}
+	rules, err := scoringRules(req)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
 	// Start transaction
 	tx, err := h.db.Beginx()
 	if err != nil {
@@ -420,8 +654,7 @@ func (h *Handler) startTournament(w http.ResponseWriter, r *http.Request, code s
 	// Get draft and verify admin
 	var draft database.Draft
 	err = tx.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1 FOR UPDATE
 	`, code)
 	if err != nil {
@@ -435,23 +668,82 @@ func (h *Handler) startTournament(w http.ResponseWriter, r *http.Request, code s
 		return
 	}
 
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
 	if draft.Status != "completed" {
 		http.Error(w, "Draft must be completed before starting tournament", http.StatusBadRequest)
 		return
 	}
 
+	if req.Format == TournamentFormatKnockout && draft.ParticipantCount < 2 {
+		http.Error(w, "A knockout tournament needs at least 2 participants", http.StatusBadRequest)
+		return
+	}
+
+	if req.Format == TournamentFormatGroups {
+		if err := validateGroupSettings(req.GroupCount, req.GroupQualifiers, draft.ParticipantCount); err != nil {
+			writeStatusError(w, err)
+			return
+		}
+	}
+
 	// Update draft status to tournament
 	_, err = tx.Exec(`
 		UPDATE drafts 
-		SET status = 'tournament'
+		SET status = 'tournament', double_round_robin = $2, tournament_format = $3,
+		    group_count = $4, group_qualifiers = $5, points_win = $6, points_draw = $7,
+		    points_loss = $8, bonus_goals = $9, bonus_close_loss = $10, forfeit_goals = $11
 		WHERE id = $1
-	`, draft.ID)
+	`, draft.ID, req.DoubleRoundRobin, req.Format, req.GroupCount, req.GroupQualifiers,
+		rules.PointsWin, rules.PointsDraw, rules.PointsLoss, rules.BonusGoals, rules.BonusCloseLoss,
+		rules.ForfeitGoals)
 	if err != nil {
 		log.Printf("Update draft status to tournament error: %v", err)
 		http.Error(w, "Failed to start tournament", http.StatusInternalServerError)
 		return
 	}
 
+	draft.DoubleRoundRobin = req.DoubleRoundRobin
+	draft.TournamentFormat = req.Format
+	draft.GroupCount, draft.GroupQualifiers = req.GroupCount, req.GroupQualifiers
+	draft.PointsWin, draft.PointsDraw, draft.PointsLoss = rules.PointsWin, rules.PointsDraw, rules.PointsLoss
+	draft.BonusGoals, draft.BonusCloseLoss = rules.BonusGoals, rules.BonusCloseLoss
+	draft.ForfeitGoals = rules.ForfeitGoals
+	if draft.TournamentFormat == TournamentFormatKnockout {
+		seeded, err := h.seedTeams(tx, draft, req.Seeding)
+		if err == nil {
+			err = createBracket(tx, draft.ID, seeded)
+		}
+		if err != nil {
+			log.Printf("Create knockout bracket error: %v", err)
+			http.Error(w, "Failed to start tournament", http.StatusInternalServerError)
+			return
+		}
+	} else if draft.TournamentFormat == TournamentFormatGroups {
+		seeded, err := h.seedTeams(tx, draft, req.Seeding)
+		if err == nil {
+			err = createGroupStage(tx, draft, seeded)
+		}
+		if err != nil {
+			log.Printf("Create group stage error: %v", err)
+			http.Error(w, "Failed to start tournament", http.StatusInternalServerError)
+			return
+		}
+	} else if err := createFixtures(tx, draft); err != nil {
+		log.Printf("Create fixtures error: %v", err)
+		http.Error(w, "Failed to start tournament", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventTournamentStarted, req.AdminName, nil); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to start tournament", http.StatusInternalServerError)
+		return
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		log.Printf("Commit transaction error: %v", err)
@@ -465,6 +757,7 @@ func (h *Handler) startTournament(w http.ResponseWriter, r *http.Request, code s
 	log.Printf("Started tournament for draft %s", code)
 
 	// Broadcast draft state update to all WebSocket clients
+	broadcastStatusChanged(code, draft)
 	if h.broadcastFunc != nil {
 		go h.broadcastFunc(h.db, code)
 	}
@@ -520,136 +813,564 @@ func (h *Handler) handleDraftOperations(w http.ResponseWriter, r *http.Request)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	} else if len(parts) == 2 && parts[1] == "matches" {
-		// /api/drafts/{code}/matches
+	} else if len(parts) == 3 && parts[1] == "tournament" && parts[2] == "playoffs" {
+		// /api/drafts/{code}/tournament/playoffs
 		switch r.Method {
 		case http.MethodPost:
-			h.recordMatch(w, r, code)
+			h.startPlayoffs(w, r, code)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	} else {
-		http.Error(w, "Not found", http.StatusNotFound)
-	}
-}
-
-func (h *Handler) getDraft(w http.ResponseWriter, r *http.Request, code string) {
-	// Get draft
-	var draft database.Draft
-	err := h.db.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
-		FROM drafts WHERE code = $1
-	`, code)
-	if err != nil {
-		log.Printf("Get draft error: %v", err)
-		http.Error(w, "Draft not found", http.StatusNotFound)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(draft)
-}
-
-func (h *Handler) joinDraft(w http.ResponseWriter, r *http.Request, code string) {
-	var req JoinDraftRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Join draft decode error: %v", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	if req.Name == "" {
-		http.Error(w, "Name is required", http.StatusBadRequest)
-		return
-	}
-
-	// Start transaction
-	tx, err := h.db.Beginx()
-	if err != nil {
-		log.Printf("Begin transaction error: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
-	}
-	defer tx.Rollback()
-
-	// Get draft and lock it
-	var draft database.Draft
-	err = tx.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
-		FROM drafts WHERE code = $1 FOR UPDATE
-	`, code)
-	if err != nil {
-		log.Printf("Get draft for join error: %v", err)
-		http.Error(w, "Draft not found", http.StatusNotFound)
-		return
-	}
-
-	if draft.Status != "waiting" {
-		http.Error(w, "Draft has already started", http.StatusBadRequest)
-		return
-	}
-
-	// Check if name already taken
-	var nameExists bool
-	err = tx.Get(&nameExists, "SELECT EXISTS(SELECT 1 FROM draft_participants WHERE draft_id = $1 AND name = $2)", draft.ID, req.Name)
-	if err != nil {
-		log.Printf("Check name exists error: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
-	}
-
-	if nameExists {
-		http.Error(w, "Name already taken in this draft", http.StatusBadRequest)
-		return
-	}
-
-	// Get next draft order
-	nextOrder := draft.ParticipantCount + 1
-
-	// Add participant
-	var participant database.DraftParticipant
-	err = tx.Get(&participant, `
-		INSERT INTO draft_participants (draft_id, name, draft_order, is_admin) 
-		VALUES ($1, $2, $3, $4) 
-		RETURNING id, draft_id, name, draft_order, is_admin, joined_at, 
-		          picks_85_89, picks_80_84, picks_75_79, picks_up_to_74
-	`, draft.ID, req.Name, nextOrder, req.Name == draft.AdminName)
-	if err != nil {
-		log.Printf("Create participant error: %v", err)
-		http.Error(w, "Failed to join draft", http.StatusInternalServerError)
-		return
-	}
-
-	// Update draft participant count
-	_, err = tx.Exec("UPDATE drafts SET participant_count = $1 WHERE id = $2", nextOrder, draft.ID)
-	if err != nil {
-		log.Printf("Update participant count error: %v", err)
-		http.Error(w, "Failed to update draft", http.StatusInternalServerError)
-		return
-	}
+	} else if len(parts) == 3 && parts[1] == "tournament" && parts[2] == "stats" {
+		// /api/drafts/{code}/tournament/stats
+		switch r.Method {
+		case http.MethodGet:
+			h.getTournamentStats(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 3 && parts[1] == "tournament" && parts[2] == "leaders" {
+		// /api/drafts/{code}/tournament/leaders
+		switch r.Method {
+		case http.MethodGet:
+			h.getTournamentLeaders(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "league" {
+		// /api/drafts/{code}/league
+		switch r.Method {
+		case http.MethodPut:
+			h.setDraftLeague(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "order" {
+		// /api/drafts/{code}/order
+		switch r.Method {
+		case http.MethodPut:
+			h.setDraftOrder(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 3 && parts[1] == "order" && parts[2] == "preview" {
+		// /api/drafts/{code}/order/preview
+		switch r.Method {
+		case http.MethodPost:
+			h.previewDraftOrder(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "keepers" {
+		// /api/drafts/{code}/keepers
+		h.handleKeepers(w, r, code)
+	} else if len(parts) == 2 && parts[1] == "watchlist" {
+		// /api/drafts/{code}/watchlist
+		h.handleWatchlist(w, r, code)
+	} else if len(parts) == 3 && parts[1] == "watchlist" {
+		// /api/drafts/{code}/watchlist/{playerId}
+		switch r.Method {
+		case http.MethodDelete:
+			h.removeFromWatchlist(w, r, code, parts[2])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "bots" {
+		// /api/drafts/{code}/bots
+		switch r.Method {
+		case http.MethodPost:
+			h.addBots(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "admin" {
+		// /api/drafts/{code}/admin
+		switch r.Method {
+		case http.MethodPut:
+			h.transferAdmin(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "pack" {
+		// /api/drafts/{code}/pack
+		switch r.Method {
+		case http.MethodGet:
+			h.getPack(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "invite" {
+		// /api/drafts/{code}/invite
+		switch r.Method {
+		case http.MethodGet:
+			h.getInvite(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "substitute" {
+		// /api/drafts/{code}/substitute
+		switch r.Method {
+		case http.MethodPost:
+			h.substituteParticipant(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "stream" {
+		// /api/drafts/{code}/stream
+		switch r.Method {
+		case http.MethodGet:
+			h.streamDraft(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "events" {
+		// /api/drafts/{code}/events
+		switch r.Method {
+		case http.MethodGet:
+			h.getDraftEvents(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "picks" {
+		// /api/drafts/{code}/picks
+		switch r.Method {
+		case http.MethodGet:
+			h.getPicks(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "recap" {
+		// /api/drafts/{code}/recap
+		switch r.Method {
+		case http.MethodGet:
+			h.getDraftRecap(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "grades" {
+		// /api/drafts/{code}/grades
+		switch r.Method {
+		case http.MethodGet:
+			h.getDraftGrades(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 4 && parts[1] == "participants" && parts[3] == "best-xi" {
+		// /api/drafts/{code}/participants/{name}/best-xi
+		switch r.Method {
+		case http.MethodGet:
+			h.getBestXI(w, r, code, parts[2])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "restart" {
+		// /api/drafts/{code}/restart
+		switch r.Method {
+		case http.MethodPost:
+			h.restartDraft(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "rematch" {
+		// /api/drafts/{code}/rematch
+		switch r.Method {
+		case http.MethodPost:
+			h.createRematch(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 2 && parts[1] == "matches" {
+		// /api/drafts/{code}/matches
+		switch r.Method {
+		case http.MethodPost:
+			h.recordMatch(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 3 && parts[1] == "matches" && parts[2] == "pending" {
+		// /api/drafts/{code}/matches/pending
+		switch r.Method {
+		case http.MethodGet:
+			h.getPendingMatches(w, r, code)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 3 && parts[1] == "matches" {
+		// /api/drafts/{code}/matches/{id}
+		switch r.Method {
+		case http.MethodPut:
+			h.editMatch(w, r, code, parts[2])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 4 && parts[1] == "matches" && parts[3] == "dispute" {
+		// /api/drafts/{code}/matches/{id}/dispute
+		switch r.Method {
+		case http.MethodPost:
+			h.disputeMatch(w, r, code, parts[2])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 4 && parts[1] == "matches" && parts[3] == "resolve" {
+		// /api/drafts/{code}/matches/{id}/resolve
+		switch r.Method {
+		case http.MethodPost:
+			h.resolveMatch(w, r, code, parts[2])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 4 && parts[1] == "matches" && parts[3] == "goals" {
+		// /api/drafts/{code}/matches/{id}/goals
+		switch r.Method {
+		case http.MethodPut:
+			h.setMatchGoals(w, r, code, parts[2])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else if len(parts) == 4 && parts[1] == "matches" && parts[3] == "confirm" {
+		// /api/drafts/{code}/matches/{id}/confirm
+		switch r.Method {
+		case http.MethodPost:
+			h.confirmMatch(w, r, code, parts[2])
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else {
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// transferAdmin hands admin rights from the current admin to another participant
+func (h *Handler) transferAdmin(w http.ResponseWriter, r *http.Request, code string) {
+	var req TransferAdminRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Transfer admin decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" || req.NewAdminName == "" {
+		http.Error(w, "AdminName and newAdminName are required", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == req.NewAdminName {
+		http.Error(w, "New admin must be a different participant", http.StatusBadRequest)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Get draft and verify admin
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for transfer admin error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can transfer admin rights", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	// Verify the new admin is a participant
+	var newAdminExists bool
+	err = tx.Get(&newAdminExists, "SELECT EXISTS(SELECT 1 FROM draft_participants WHERE draft_id = $1 AND name = $2)", draft.ID, req.NewAdminName)
+	if err != nil {
+		log.Printf("Check new admin exists error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if !newAdminExists {
+		http.Error(w, "New admin must be a participant in this draft", http.StatusBadRequest)
+		return
+	}
+
+	// Move the admin flag to the new admin
+	_, err = tx.Exec("UPDATE draft_participants SET is_admin = (name = $1) WHERE draft_id = $2", req.NewAdminName, draft.ID)
+	if err != nil {
+		log.Printf("Update participant admin flags error: %v", err)
+		http.Error(w, "Failed to transfer admin", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = tx.Exec("UPDATE drafts SET admin_name = $1 WHERE id = $2", req.NewAdminName, draft.ID)
+	if err != nil {
+		log.Printf("Update draft admin error: %v", err)
+		http.Error(w, "Failed to transfer admin", http.StatusInternalServerError)
+		return
+	}
+
+	// Get updated participants
+	var participants []database.DraftParticipant
+	err = tx.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Get participants after transfer admin error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventAdminTransferred, req.AdminName, map[string]string{"newAdminName": req.NewAdminName}); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to transfer admin", http.StatusInternalServerError)
+		return
+	}
 
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		log.Printf("Commit transaction error: %v", err)
-		http.Error(w, "Failed to join draft", http.StatusInternalServerError)
+		http.Error(w, "Failed to transfer admin", http.StatusInternalServerError)
 		return
 	}
 
 	// Update draft object
-	draft.ParticipantCount = nextOrder
+	draft.AdminName = req.NewAdminName
 
-	log.Printf("Player %s joined draft %s (order: %d)", req.Name, code, nextOrder)
+	log.Printf("Admin of draft %s transferred from %s to %s", code, req.AdminName, req.NewAdminName)
 
 	// Broadcast updated draft state to all WebSocket clients
 	if h.broadcastFunc != nil {
-		h.broadcastFunc(h.db, code)
+		go h.broadcastFunc(h.db, code)
+	}
+
+	response := TransferAdminResponse{
+		Draft:        draft,
+		Participants: participants,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) getDraft(w http.ResponseWriter, r *http.Request, code string) {
+	// Get draft
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(draft)
+}
+
+func (h *Handler) joinDraft(w http.ResponseWriter, r *http.Request, code string) {
+	var req JoinDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Join draft decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Get draft and lock it
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for join error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	// A participant holding their token can rejoin at any point, e.g. after
+	// a browser refresh
+	if req.Token != "" {
+		if err := verifyParticipantToken(tx, code, req.Name, req.Token); err != nil {
+			writeStatusError(w, err)
+			return
+		}
+
+		var participant database.DraftParticipant
+		err = tx.Get(&participant, `
+			SELECT `+database.ParticipantColumns+`
+			FROM draft_participants WHERE draft_id = $1 AND name = $2
+		`, draft.ID, req.Name)
+		if err != nil {
+			log.Printf("Get participant for rejoin error: %v", err)
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("Player %s rejoined draft %s", req.Name, code)
+
+		response := JoinDraftResponse{
+			Draft:       draft,
+			Participant: participant,
+			Token:       req.Token,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := verifyJoinPassword(tx, code, req.Password); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	// Participants from before tokens rejoin by name once to get theirs
+	token, claimed, err := claimParticipantToken(tx, draft.ID, req.Name)
+	if err != nil {
+		log.Printf("Claim participant token error: %v", err)
+		http.Error(w, "Failed to join draft", http.StatusInternalServerError)
+		return
+	}
+	if claimed {
+		var participant database.DraftParticipant
+		err = tx.Get(&participant, `
+			SELECT `+database.ParticipantColumns+`
+			FROM draft_participants WHERE draft_id = $1 AND name = $2
+		`, draft.ID, req.Name)
+		if err != nil {
+			log.Printf("Get participant for token claim error: %v", err)
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Printf("Commit transaction error: %v", err)
+			http.Error(w, "Failed to join draft", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("Player %s claimed a token in draft %s", req.Name, code)
+
+		response := JoinDraftResponse{
+			Draft:       draft,
+			Participant: participant,
+			Token:       token,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if draft.Status != "waiting" {
+		http.Error(w, "Draft has already started", http.StatusBadRequest)
+		return
+	}
+
+	// Check if name already taken
+	var nameExists bool
+	err = tx.Get(&nameExists, "SELECT EXISTS(SELECT 1 FROM draft_participants WHERE draft_id = $1 AND name = $2)", draft.ID, req.Name)
+	if err != nil {
+		log.Printf("Check name exists error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if nameExists {
+		http.Error(w, "Name already taken in this draft", http.StatusBadRequest)
+		return
+	}
+
+	if seats := remainingSeats(draft); seats != nil && *seats == 0 {
+		http.Error(w, fmt.Sprintf("Draft is full (max %d participants)", *draft.MaxParticipants), http.StatusBadRequest)
+		return
+	}
+
+	token, err = generateParticipantToken()
+	if err != nil {
+		log.Printf("Generate participant token error: %v", err)
+		http.Error(w, "Failed to join draft", http.StatusInternalServerError)
+		return
+	}
+
+	// Get next draft order
+	nextOrder := draft.ParticipantCount + 1
+
+	// Add participant
+	var participant database.DraftParticipant
+	err = tx.Get(&participant, `
+		INSERT INTO draft_participants (draft_id, name, draft_order, is_admin, token) 
+		VALUES ($1, $2, $3, $4, $5) 
+		RETURNING `+database.ParticipantColumns+`
+	`, draft.ID, req.Name, nextOrder, req.Name == draft.AdminName, token)
+	if err != nil {
+		log.Printf("Create participant error: %v", err)
+		http.Error(w, "Failed to join draft", http.StatusInternalServerError)
+		return
+	}
+
+	// Update draft participant count. A manually set order no longer covers
+	// everyone, so it falls back to being shuffled at start.
+	_, err = tx.Exec("UPDATE drafts SET participant_count = $1, order_locked = false WHERE id = $2", nextOrder, draft.ID)
+	if err != nil {
+		log.Printf("Update participant count error: %v", err)
+		http.Error(w, "Failed to update draft", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventParticipantJoined, req.Name, map[string]int{"draftOrder": nextOrder}); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to join draft", http.StatusInternalServerError)
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to join draft", http.StatusInternalServerError)
+		return
 	}
 
+	// Update draft object
+	draft.ParticipantCount = nextOrder
+	draft.OrderLocked = false
+
+	log.Printf("Player %s joined draft %s (order: %d)", req.Name, code, nextOrder)
+
+	// Announce the newcomer to all WebSocket clients
+	broadcastParticipantJoined(code, draft, participant)
+
 	response := JoinDraftResponse{
 		Draft:       draft,
 		Participant: participant,
+		Token:       token,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -660,8 +1381,7 @@ func (h *Handler) getOptimalTransferData(w http.ResponseWriter, r *http.Request,
 	// Get draft to verify it exists and is completed
 	var draft database.Draft
 	err := h.db.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1
 	`, code)
 	if err != nil {
@@ -685,7 +1405,8 @@ func (h *Handler) getOptimalTransferData(w http.ResponseWriter, r *http.Request,
 		       p.avatar_url, p.league_name,
 		       part.name as participant_name
 		FROM draft_picks dp
-		JOIN players p ON dp.player_id = p.id
+		JOIN drafts d ON dp.draft_id = d.id
+		JOIN players p ON dp.player_id = p.id AND p.dataset = d.dataset
 		JOIN draft_participants part ON dp.participant_id = part.id
 		WHERE dp.draft_id = $1 
 		ORDER BY dp.overall_pick_number
@@ -756,8 +1477,7 @@ func (h *Handler) getTournamentData(w http.ResponseWriter, r *http.Request, code
 	// Get draft to verify it exists and is completed or in tournament mode
 	var draft database.Draft
 	err := h.db.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1
 	`, code)
 	if err != nil {
@@ -775,8 +1495,7 @@ func (h *Handler) getTournamentData(w http.ResponseWriter, r *http.Request, code
 	// Get participants
 	var participants []database.DraftParticipant
 	err = h.db.Select(&participants, `
-		SELECT id, draft_id, name, draft_order, is_admin, joined_at, 
-		       picks_85_89, picks_80_84, picks_75_79, picks_up_to_74
+		SELECT `+database.ParticipantColumns+`
 		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
 	`, draft.ID)
 	if err != nil {
@@ -788,9 +1507,8 @@ func (h *Handler) getTournamentData(w http.ResponseWriter, r *http.Request, code
 	// Get matches
 	var matches []database.Match
 	err = h.db.Select(&matches, `
-		SELECT id, draft_id, home_team_id, away_team_id, home_team_name, away_team_name,
-		       home_score, away_score, played_at, recorded_by
-		FROM matches WHERE draft_id = $1 ORDER BY played_at DESC
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE draft_id = $1 AND status = 'confirmed' ORDER BY played_at DESC
 	`, draft.ID)
 	if err != nil {
 		log.Printf("Get matches for tournament error: %v", err)
@@ -798,14 +1516,60 @@ func (h *Handler) getTournamentData(w http.ResponseWriter, r *http.Request, code
 		return
 	}
 
-	// Calculate standings
-	standings := h.calculateStandings(participants, matches)
+	pendingMatches, err := loadPendingMatches(h.db, draft.ID)
+	if err != nil {
+		log.Printf("Get pending matches for tournament error: %v", err)
+		http.Error(w, "Failed to fetch matches", http.StatusInternalServerError)
+		return
+	}
+
+	goals, err := loadMatchGoals(h.db, draft.ID, 0)
+	if err != nil {
+		log.Printf("Get match goals for tournament error: %v", err)
+		http.Error(w, "Failed to fetch matches", http.StatusInternalServerError)
+		return
+	}
+
+	fixtures, err := loadFixtures(h.db, draft.ID)
+	if err != nil {
+		log.Printf("Get fixtures for tournament error: %v", err)
+		http.Error(w, "Failed to fetch fixtures", http.StatusInternalServerError)
+		return
+	}
+
+	bracket, err := loadBracket(h.db, draft.ID)
+	if err != nil {
+		log.Printf("Get bracket for tournament error: %v", err)
+		http.Error(w, "Failed to fetch bracket", http.StatusInternalServerError)
+		return
+	}
+
+	// Calculate standings, without the matches played in the bracket
+	league := leagueMatches(matches, bracket)
+	standings := calculateStandings(participants, league, draft.TournamentSettings)
+	homeTable, awayTable := homeAwayTables(standings)
+	groups := h.groupStandings(participants, league, fixtures, draft.TournamentSettings)
+
+	chemistry, err := squadChemistryByParticipant(h.db, draft.ID, participants)
+	if err != nil {
+		log.Printf("Get squad chemistry for tournament error: %v", err)
+		http.Error(w, "Failed to fetch squads", http.StatusInternalServerError)
+		return
+	}
 
 	response := TournamentData{
-		Draft:        draft,
-		Participants: participants,
-		Matches:      matches,
-		Standings:    standings,
+		Draft:          draft,
+		Participants:   participants,
+		Matches:        matches,
+		PendingMatches: pendingMatches,
+		Goals:          goals,
+		Fixtures:       fixtures,
+		Bracket:        bracket,
+		Groups:         groups,
+		Standings:      standings,
+		HomeTable:      homeTable,
+		AwayTable:      awayTable,
+		Chemistry:      chemistry,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -820,191 +1584,221 @@ func (h *Handler) recordMatch(w http.ResponseWriter, r *http.Request, code strin
 		return
 	}
 
+	if err := verifyParticipantToken(h.db, code, req.RecordedBy, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	match, err := h.processMatchResult(code, req)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	// Broadcast updated tournament state to all WebSocket clients
+	if h.broadcastFunc != nil {
+		// Use tournament-specific broadcast for tournament mode
+		BroadcastTournamentStateToRoom(h.db, code)
+	}
+
+	response := RecordMatchResponse{
+		Match: match,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// processMatchResult validates and stores a match result. It is shared by the
+// HTTP endpoint and the chat /result command.
+func (h *Handler) processMatchResult(code string, req RecordMatchRequest) (database.Match, error) {
+	var match database.Match
+
 	// Validate input
 	if req.HomeTeamName == "" || req.AwayTeamName == "" {
-		http.Error(w, "Team names are required", http.StatusBadRequest)
-		return
+		return match, newStatusError(http.StatusBadRequest, "Team names are required")
 	}
 
 	if req.HomeTeamName == req.AwayTeamName {
-		http.Error(w, "Teams cannot be the same", http.StatusBadRequest)
-		return
+		return match, newStatusError(http.StatusBadRequest, "Teams cannot be the same")
 	}
 
-	if req.HomeScore < 0 || req.AwayScore < 0 {
-		http.Error(w, "Scores must be non-negative", http.StatusBadRequest)
-		return
+	if req.ForfeitedBy != "" {
+		if req.ForfeitedBy != req.HomeTeamName && req.ForfeitedBy != req.AwayTeamName {
+			return match, newStatusError(http.StatusBadRequest, "forfeitedBy must be one of the teams")
+		}
+	} else if err := validateResult(req.HomeScore, req.AwayScore, req.ExtraTimeResult); err != nil {
+		return match, err
+	}
+	if err := validateCards(req.MatchCards); err != nil {
+		return match, err
 	}
 
 	if req.RecordedBy == "" {
-		http.Error(w, "RecordedBy is required", http.StatusBadRequest)
-		return
+		return match, newStatusError(http.StatusBadRequest, "RecordedBy is required")
 	}
 
 	// Start transaction
 	tx, err := h.db.Beginx()
 	if err != nil {
 		log.Printf("Begin transaction error: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return match, newStatusError(http.StatusInternalServerError, "Database error")
 	}
 	defer tx.Rollback()
 
 	// Get draft and verify it's completed or in tournament
 	var draft database.Draft
 	err = tx.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1 FOR UPDATE
 	`, code)
 	if err != nil {
 		log.Printf("Get draft for record match error: %v", err)
-		http.Error(w, "Draft not found", http.StatusNotFound)
-		return
+		return match, newStatusError(http.StatusNotFound, "Draft not found")
 	}
 
 	if draft.Status != "completed" && draft.Status != "tournament" {
-		http.Error(w, "Draft is not completed yet", http.StatusBadRequest)
-		return
+		return match, newStatusError(http.StatusBadRequest, "Draft is not completed yet")
 	}
 
-	// Verify recorder is admin
-	if draft.AdminName != req.RecordedBy {
-		http.Error(w, "Only the admin can record matches", http.StatusForbidden)
-		return
+	// The admin's results count straight away, a team's own result waits for
+	// their opponent to confirm it
+	status := MatchConfirmed
+	var confirmedBy *string
+	if draft.AdminName == req.RecordedBy {
+		confirmedBy = &req.RecordedBy
+	} else if req.RecordedBy == req.HomeTeamName || req.RecordedBy == req.AwayTeamName {
+		status = MatchPending
+	} else {
+		return match, newStatusError(http.StatusForbidden, "Only the admin or the teams involved can record matches")
+	}
+
+	// A walkover is scored as a standard win for the team that turned up
+	if req.ForfeitedBy != "" {
+		if status != MatchConfirmed {
+			return match, newStatusError(http.StatusForbidden, "Only the admin can record a forfeit")
+		}
+		req.HomeScore, req.AwayScore = draft.ForfeitGoals, 0
+		if req.ForfeitedBy == req.HomeTeamName {
+			req.HomeScore, req.AwayScore = 0, draft.ForfeitGoals
+		}
+		req.ExtraTimeResult = database.ExtraTimeResult{}
+		req.MatchCards = database.MatchCards{}
 	}
 
 	// Get team IDs
 	var homeTeamID, awayTeamID int
 	err = tx.Get(&homeTeamID, "SELECT id FROM draft_participants WHERE draft_id = $1 AND name = $2", draft.ID, req.HomeTeamName)
 	if err != nil {
-		http.Error(w, "Home team not found", http.StatusBadRequest)
-		return
+		return match, newStatusError(http.StatusBadRequest, "Home team not found")
 	}
 
 	err = tx.Get(&awayTeamID, "SELECT id FROM draft_participants WHERE draft_id = $1 AND name = $2", draft.ID, req.AwayTeamName)
 	if err != nil {
-		http.Error(w, "Away team not found", http.StatusBadRequest)
-		return
+		return match, newStatusError(http.StatusBadRequest, "Away team not found")
 	}
 
 	// Insert match
-	var match database.Match
 	err = tx.Get(&match, `
 		INSERT INTO matches (draft_id, home_team_id, away_team_id, home_team_name, away_team_name, 
-		                    home_score, away_score, recorded_by) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
-		RETURNING id, draft_id, home_team_id, away_team_id, home_team_name, away_team_name,
-		          home_score, away_score, played_at, recorded_by
+		                    home_score, away_score, recorded_by, status, confirmed_by,
+		                    home_score_aet, away_score_aet, home_penalties, away_penalties, walkover,
+		                    home_yellow_cards, away_yellow_cards, home_red_cards, away_red_cards) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) 
+		RETURNING `+database.MatchColumns+`
 	`, draft.ID, homeTeamID, awayTeamID, req.HomeTeamName, req.AwayTeamName,
-		req.HomeScore, req.AwayScore, req.RecordedBy)
+		req.HomeScore, req.AwayScore, req.RecordedBy, status, confirmedBy,
+		req.HomeScoreAET, req.AwayScoreAET, req.HomePenalties, req.AwayPenalties, req.ForfeitedBy != "",
+		req.HomeYellowCards, req.AwayYellowCards, req.HomeRedCards, req.AwayRedCards)
 	if err != nil {
 		log.Printf("Insert match error: %v", err)
-		http.Error(w, "Failed to record match", http.StatusInternalServerError)
-		return
+		return match, newStatusError(http.StatusInternalServerError, "Failed to record match")
+	}
+
+	if len(req.Goals) > 0 {
+		if err := saveMatchGoals(tx, match, req.Goals); err != nil {
+			return match, err
+		}
+	}
+
+	if status == MatchPending {
+		err = checkPendingResult(tx, draft, match)
+		if err == nil {
+			err = recordDraftEvent(tx, draft.ID, EventMatchSubmitted, req.RecordedBy, match)
+		}
+	} else {
+		err = h.settleMatch(tx, draft, match, req.RecordedBy)
+	}
+	if err != nil {
+		var se *statusError
+		if errors.As(err, &se) {
+			return match, err
+		}
+		log.Printf("Record match error: %v", err)
+		return match, newStatusError(http.StatusInternalServerError, "Failed to record match")
 	}
 
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		log.Printf("Commit match transaction error: %v", err)
-		http.Error(w, "Failed to record match", http.StatusInternalServerError)
-		return
+		return match, newStatusError(http.StatusInternalServerError, "Failed to record match")
 	}
 
-	log.Printf("Match recorded: %s %d - %d %s by %s", req.HomeTeamName, req.HomeScore, req.AwayScore, req.AwayTeamName, req.RecordedBy)
+	log.Printf("Match %s: %s %d - %d %s by %s", status, req.HomeTeamName, req.HomeScore, req.AwayScore, req.AwayTeamName, req.RecordedBy)
 
-	// Broadcast updated tournament state to all WebSocket clients
-	if h.broadcastFunc != nil {
-		// Use tournament-specific broadcast for tournament mode
-		BroadcastTournamentStateToRoom(h.db, code)
-	}
+	return match, nil
+}
 
-	response := RecordMatchResponse{
-		Match: match,
+// settleMatch counts a confirmed match in the tournament: it settles the
+// knockout tie or fixture it was played for, updates the Elo ratings,
+// records it in the timeline, and draws the knockouts after the last group
+// fixture. Errors from the knockout tie are statusErrors.
+func (h *Handler) settleMatch(tx *sqlx.Tx, draft database.Draft, match database.Match, actor string) error {
+	knockout := false
+	if draft.Status == "tournament" {
+		var err error
+		if knockout, err = inKnockoutPhase(tx, draft); err != nil {
+			return fmt.Errorf("check knockout phase: %w", err)
+		}
+	}
+	if knockout {
+		if err := recordKnockoutResult(tx, match); err != nil {
+			return err
+		}
+	} else if err := linkMatchToFixture(tx, match); err != nil {
+		return fmt.Errorf("link match to fixture: %w", err)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
+	if err := applyRatings(tx, match); err != nil {
+		return fmt.Errorf("apply ratings: %w", err)
+	}
 
-func (h *Handler) calculateStandings(participants []database.DraftParticipant, matches []database.Match) []TeamStanding {
-	standings := make(map[string]*TeamStanding)
+	if err := recordDraftEvent(tx, draft.ID, EventMatchRecorded, actor, match); err != nil {
+		return err
+	}
 
-	// Initialize standings for all participants
-	for _, participant := range participants {
-		standings[participant.Name] = &TeamStanding{
-			TeamName:       participant.Name,
-			TeamID:         participant.ID,
-			GamesPlayed:    0,
-			Wins:           0,
-			Draws:          0,
-			Losses:         0,
-			Points:         0,
-			GoalsFor:       0,
-			GoalsAgainst:   0,
-			GoalDifference: 0,
-		}
-	}
-
-	// Process matches
-	for _, match := range matches {
-		homeTeam := standings[match.HomeTeamName]
-		awayTeam := standings[match.AwayTeamName]
-
-		if homeTeam == nil || awayTeam == nil {
-			continue // Skip if team not found
-		}
-
-		// Update games played
-		homeTeam.GamesPlayed++
-		awayTeam.GamesPlayed++
-
-		// Update goals
-		homeTeam.GoalsFor += match.HomeScore
-		homeTeam.GoalsAgainst += match.AwayScore
-		awayTeam.GoalsFor += match.AwayScore
-		awayTeam.GoalsAgainst += match.HomeScore
-
-		// Update results and points
-		if match.HomeScore > match.AwayScore {
-			// Home team wins
-			homeTeam.Wins++
-			homeTeam.Points += 3
-			awayTeam.Losses++
-		} else if match.HomeScore < match.AwayScore {
-			// Away team wins
-			awayTeam.Wins++
-			awayTeam.Points += 3
-			homeTeam.Losses++
-		} else {
-			// Draw
-			homeTeam.Draws++
-			homeTeam.Points += 1
-			awayTeam.Draws++
-			awayTeam.Points += 1
-		}
-
-		// Update goal difference
-		homeTeam.GoalDifference = homeTeam.GoalsFor - homeTeam.GoalsAgainst
-		awayTeam.GoalDifference = awayTeam.GoalsFor - awayTeam.GoalsAgainst
-	}
-
-	// Convert to slice and sort by points (desc), then goal difference (desc), then goals for (desc)
-	result := make([]TeamStanding, 0, len(standings))
-	for _, standing := range standings {
-		result = append(result, *standing)
-	}
-
-	// Sort standings
-	for i := 0; i < len(result); i++ {
-		for j := i + 1; j < len(result); j++ {
-			if result[i].Points < result[j].Points ||
-				(result[i].Points == result[j].Points && result[i].GoalDifference < result[j].GoalDifference) ||
-				(result[i].Points == result[j].Points && result[i].GoalDifference == result[j].GoalDifference && result[i].GoalsFor < result[j].GoalsFor) {
-				result[i], result[j] = result[j], result[i]
-			}
+	// The last group fixture sends the qualifiers through to the knockouts
+	if draft.Status == "tournament" && draft.TournamentFormat == TournamentFormatGroups && !knockout {
+		started, err := h.startGroupKnockout(tx, draft)
+		if err == nil && started {
+			err = recordDraftEvent(tx, draft.ID, EventKnockoutStarted, actor, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("start group knockout: %w", err)
 		}
 	}
+	return nil
+}
 
-	return result
+// remainingSeats returns how many more participants can join, or nil when the
+// draft has no cap
+func remainingSeats(draft database.Draft) *int {
+	if draft.MaxParticipants == nil {
+		return nil
+	}
+	seats := *draft.MaxParticipants - draft.ParticipantCount
+	if seats < 0 {
+		seats = 0
+	}
+	return &seats
 }
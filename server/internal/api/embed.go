@@ -0,0 +1,274 @@
+package api
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+)
+
+// Embed endpoints are read-only, open to any origin and cacheable, so groups
+// can show live standings on their own sites. Payloads only carry what is
+// needed for display.
+
+const embedCacheControl = "public, max-age=30, stale-while-revalidate=60"
+
+type EmbedStanding struct {
+	Position       int    `json:"position"`
+	TeamName       string `json:"teamName"`
+	GamesPlayed    int    `json:"gamesPlayed"`
+	Wins           int    `json:"wins"`
+	Draws          int    `json:"draws"`
+	Losses         int    `json:"losses"`
+	GoalsFor       int    `json:"goalsFor"`
+	GoalsAgainst   int    `json:"goalsAgainst"`
+	GoalDifference int    `json:"goalDifference"`
+	Points         int    `json:"points"`
+}
+
+type EmbedStandingsResponse struct {
+	DraftName string          `json:"draftName"`
+	Status    string          `json:"status"`
+	Standings []EmbedStanding `json:"standings"`
+}
+
+type EmbedPick struct {
+	OverallPickNumber int     `json:"overallPickNumber"`
+	RoundNumber       int     `json:"roundNumber"`
+	PlayerName        string  `json:"playerName"`
+	OverallRating     *int    `json:"overallRating"`
+	Position          *string `json:"position"`
+	Club              *string `json:"club"`
+	AvatarURL         *string `json:"avatarUrl"`
+}
+
+type EmbedTeam struct {
+	Name       string      `json:"name"`
+	DraftOrder int         `json:"draftOrder"`
+	Picks      []EmbedPick `json:"picks"`
+}
+
+type EmbedBoardResponse struct {
+	DraftName    string      `json:"draftName"`
+	Status       string      `json:"status"`
+	CurrentRound int         `json:"currentRound"`
+	TotalRounds  int         `json:"totalRounds"`
+	Teams        []EmbedTeam `json:"teams"`
+}
+
+func (h *Handler) openCorsMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (h *Handler) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /embed/drafts/{code}/{view}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/embed/drafts/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	code, view := parts[0], parts[1]
+
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for embed error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	var response interface{}
+	switch view {
+	case "standings":
+		response, err = h.buildEmbedStandings(draft)
+	case "board":
+		response, err = h.buildEmbedBoard(draft)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Build embed %s error: %v", view, err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Marshal embed %s error: %v", view, err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha1.Sum(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	w.Header().Set("Cache-Control", embedCacheControl)
+	w.Header().Set("ETag", etag)
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func (h *Handler) buildEmbedStandings(draft database.Draft) (EmbedStandingsResponse, error) {
+	response := EmbedStandingsResponse{
+		DraftName: draft.Name,
+		Status:    draft.Status,
+		Standings: []EmbedStanding{},
+	}
+
+	var participants []database.DraftParticipant
+	err := h.db.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		return response, err
+	}
+
+	var matches []database.Match
+	err = h.db.Select(&matches, `
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE draft_id = $1 AND status = 'confirmed' AND `+leagueMatchCondition+`
+		ORDER BY played_at DESC
+	`, draft.ID)
+	if err != nil {
+		return response, err
+	}
+
+	for i, standing := range calculateStandings(participants, matches, draft.TournamentSettings) {
+		response.Standings = append(response.Standings, EmbedStanding{
+			Position:       i + 1,
+			TeamName:       standing.TeamName,
+			GamesPlayed:    standing.GamesPlayed,
+			Wins:           standing.Wins,
+			Draws:          standing.Draws,
+			Losses:         standing.Losses,
+			GoalsFor:       standing.GoalsFor,
+			GoalsAgainst:   standing.GoalsAgainst,
+			GoalDifference: standing.GoalDifference,
+			Points:         standing.Points,
+		})
+	}
+
+	return response, nil
+}
+
+func (h *Handler) buildEmbedBoard(draft database.Draft) (EmbedBoardResponse, error) {
+	response := EmbedBoardResponse{
+		DraftName:    draft.Name,
+		Status:       draft.Status,
+		CurrentRound: draft.CurrentRound,
+		TotalRounds:  draft.TotalRounds,
+		Teams:        []EmbedTeam{},
+	}
+
+	var participants []database.DraftParticipant
+	err := h.db.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		return response, err
+	}
+
+	var picks []struct {
+		ParticipantID     int     `db:"participant_id"`
+		OverallPickNumber int     `db:"overall_pick_number"`
+		RoundNumber       int     `db:"round_number"`
+		FirstName         *string `db:"first_name"`
+		LastName          *string `db:"last_name"`
+		CommonName        *string `db:"common_name"`
+		OverallRating     *int    `db:"overall_rating"`
+		Position          *string `db:"position_short_label"`
+		Club              *string `db:"team_label"`
+		AvatarURL         *string `db:"avatar_url"`
+	}
+	err = h.db.Select(&picks, `
+		SELECT dp.participant_id, dp.overall_pick_number, dp.round_number,
+		       p.first_name, p.last_name, p.common_name, p.overall_rating,
+		       p.position_short_label, p.team_label, p.avatar_url
+		FROM draft_picks dp
+		JOIN drafts d ON dp.draft_id = d.id
+		JOIN players p ON dp.player_id = p.id AND p.dataset = d.dataset
+		WHERE dp.draft_id = $1
+		ORDER BY dp.overall_pick_number
+	`, draft.ID)
+	if err != nil {
+		return response, err
+	}
+
+	teamIndex := make(map[int]int, len(participants))
+	for i, participant := range participants {
+		teamIndex[participant.ID] = i
+		response.Teams = append(response.Teams, EmbedTeam{
+			Name:       participant.Name,
+			DraftOrder: participant.DraftOrder,
+			Picks:      []EmbedPick{},
+		})
+	}
+
+	for _, pick := range picks {
+		i, ok := teamIndex[pick.ParticipantID]
+		if !ok {
+			continue
+		}
+		response.Teams[i].Picks = append(response.Teams[i].Picks, EmbedPick{
+			OverallPickNumber: pick.OverallPickNumber,
+			RoundNumber:       pick.RoundNumber,
+			PlayerName:        playerDisplayName(pick.FirstName, pick.LastName, pick.CommonName),
+			OverallRating:     pick.OverallRating,
+			Position:          pick.Position,
+			Club:              pick.Club,
+			AvatarURL:         pick.AvatarURL,
+		})
+	}
+
+	return response, nil
+}
+
+// playerDisplayName returns the common name if set, otherwise first and last name
+func playerDisplayName(firstName, lastName, commonName *string) string {
+	if commonName != nil && *commonName != "" {
+		return *commonName
+	}
+
+	var parts []string
+	if firstName != nil && *firstName != "" {
+		parts = append(parts, *firstName)
+	}
+	if lastName != nil && *lastName != "" {
+		parts = append(parts, *lastName)
+	}
+	return strings.Join(parts, " ")
+}
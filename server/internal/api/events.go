@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Draft event types recorded in the draft_events timeline
+const (
+	EventDraftCreated           = "draft_created"
+	EventParticipantJoined      = "participant_joined"
+	EventBotsAdded              = "bots_added"
+	EventOrderSet               = "order_set"
+	EventOrderShuffled          = "order_shuffled"
+	EventKeepersSet             = "keepers_set"
+	EventDraftStarted           = "draft_started"
+	EventPick                   = "pick"
+	EventDraftCompleted         = "draft_completed"
+	EventDraftRestarted         = "draft_restarted"
+	EventAdminTransferred       = "admin_transferred"
+	EventParticipantSubstituted = "participant_substituted"
+	EventTournamentStarted      = "tournament_started"
+	EventMatchRecorded          = "match_recorded"
+	EventMatchEdited            = "match_edited"
+	EventMatchSubmitted         = "match_submitted"
+	EventMatchDisputed          = "match_disputed"
+	EventMatchResolved          = "match_resolved"
+	EventKnockoutStarted        = "knockout_started"
+	EventAnnouncement           = "announcement"
+)
+
+// pickEvent is the payload of a pick event, enough to replay the board
+type pickEvent struct {
+	ParticipantName   string  `json:"participantName"`
+	PlayerID          int     `json:"playerId"`
+	RoundNumber       int     `json:"roundNumber"`
+	PickInRound       int     `json:"pickInRound"`
+	OverallPickNumber int     `json:"overallPickNumber"`
+	RatingTier        string  `json:"ratingTier"`
+	IsKeeper          bool    `json:"isKeeper"`
+	Comment           *string `json:"comment,omitempty"`
+}
+
+// recordDraftEvent appends an event to the draft's timeline. It runs on the
+// caller's transaction so the event is only kept if the change is.
+func recordDraftEvent(e sqlx.Execer, draftID int, eventType, actor string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", eventType, err)
+	}
+
+	var actorValue *string
+	if actor != "" {
+		actorValue = &actor
+	}
+
+	_, err = e.Exec(`
+		INSERT INTO draft_events (draft_id, event_type, actor, payload)
+		VALUES ($1, $2, $3, $4)
+	`, draftID, eventType, actorValue, string(data))
+	return err
+}
+
+// draftOrderNames lists participant names in draft order
+func draftOrderNames(participants []database.DraftParticipant) []string {
+	ordered := append([]database.DraftParticipant(nil), participants...)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].DraftOrder < ordered[j].DraftOrder
+	})
+
+	names := make([]string, len(ordered))
+	for i, participant := range ordered {
+		names[i] = participant.Name
+	}
+	return names
+}
+
+type GetDraftEventsResponse struct {
+	Events []database.DraftEvent `json:"events"`
+}
+
+// getDraftEvents returns the draft's timeline in order. Pass ?after={id} to
+// only fetch events newer than the last one seen.
+func (h *Handler) getDraftEvents(w http.ResponseWriter, r *http.Request, code string) {
+	var draftID int
+	err := h.db.Get(&draftID, "SELECT id FROM drafts WHERE code = $1", code)
+	if err != nil {
+		log.Printf("Get draft for events error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	after := 0
+	if afterParam := r.URL.Query().Get("after"); afterParam != "" {
+		after, err = strconv.Atoi(afterParam)
+		if err != nil {
+			http.Error(w, "after must be an event id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	events := []database.DraftEvent{}
+	err = h.db.Select(&events, `
+		SELECT id, draft_id, event_type, actor, payload, created_at
+		FROM draft_events WHERE draft_id = $1 AND id > $2
+		ORDER BY id
+	`, draftID, after)
+	if err != nil {
+		log.Printf("Get draft events error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := GetDraftEventsResponse{
+		Events: events,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
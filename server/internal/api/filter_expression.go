@@ -0,0 +1,293 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"eafc-draft-server/internal/database"
+)
+
+const (
+	maxFilterExpressionLength = 1000
+	maxFilterComparisons      = 20
+)
+
+// filterColumnAliases are the short names filter expressions may use
+var filterColumnAliases = map[string]string{
+	"league":      "league_name",
+	"club":        "team_label",
+	"team":        "team_label",
+	"nationality": "nationality_label",
+	"position":    "position_short_label",
+	"rating":      "overall_rating",
+}
+
+// filterTextColumns are the text columns filter expressions can compare
+var filterTextColumns = map[string]bool{
+	"first_name": true, "last_name": true, "common_name": true, "league_name": true,
+	"team_label": true, "nationality_label": true, "position_short_label": true,
+	"gender": true, "card_type": true,
+}
+
+// parseFilterExpression turns a filter= expression into a WHERE condition,
+// numbering placeholders from argIndex. Expressions compare columns with
+// =, !=, <, <=, > or >= and combine them with AND, OR, NOT and parentheses:
+//
+//	league=Premier League OR nationality=Brazil
+//	(position=CB OR position=RB) AND NOT stat_pac<80
+//
+// Values run up to the next keyword or parenthesis, or can be double quoted.
+// Text columns only support = and !=.
+func parseFilterExpression(expression string, argIndex int) (string, []interface{}, int, error) {
+	if len(expression) > maxFilterExpressionLength {
+		return "", nil, 0, newStatusError(http.StatusBadRequest, fmt.Sprintf("filter can be at most %d characters", maxFilterExpressionLength))
+	}
+
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return "", nil, 0, newStatusError(http.StatusBadRequest, "Invalid filter: "+err.Error())
+	}
+
+	p := &filterParser{tokens: tokens, argIndex: argIndex, numberColumns: database.GetNumberColumns()}
+	condition, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return "", nil, 0, newStatusError(http.StatusBadRequest, "Invalid filter: "+err.Error())
+	}
+	return condition, p.args, p.argIndex, nil
+}
+
+type filterTokenKind int
+
+const (
+	filterWord filterTokenKind = iota
+	filterQuoted
+	filterOperator
+	filterOpen
+	filterClose
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+func (t filterToken) isKeyword(keyword string) bool {
+	return t.kind == filterWord && strings.EqualFold(t.text, keyword)
+}
+
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{filterOpen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{filterClose, ")"})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			tokens = append(tokens, filterToken{filterQuoted, string(runes[i+1 : end])})
+			i = end + 1
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("expected !=")
+			}
+			tokens = append(tokens, filterToken{filterOperator, op})
+			i += len(op)
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"=!<>`, runes[end]) {
+				end++
+			}
+			tokens = append(tokens, filterToken{filterWord, string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens        []filterToken
+	pos           int
+	args          []interface{}
+	argIndex      int
+	comparisons   int
+	numberColumns map[string]bool
+}
+
+func (p *filterParser) peek() *filterToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	terms := []string{left}
+	for t := p.peek(); t != nil && t.isKeyword("OR"); t = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, right)
+	}
+	if len(terms) == 1 {
+		return left, nil
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", nil
+}
+
+func (p *filterParser) parseAnd() (string, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return "", err
+	}
+	terms := []string{left}
+	for t := p.peek(); t != nil && t.isKeyword("AND"); t = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, right)
+	}
+	if len(terms) == 1 {
+		return left, nil
+	}
+	return "(" + strings.Join(terms, " AND ") + ")", nil
+}
+
+func (p *filterParser) parseUnary() (string, error) {
+	t := p.peek()
+	if t == nil {
+		return "", fmt.Errorf("unexpected end of filter")
+	}
+	if t.isKeyword("NOT") {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + operand, nil
+	}
+	if t.kind == filterOpen {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if t := p.peek(); t == nil || t.kind != filterClose {
+			return "", fmt.Errorf("missing )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison reads column op value. Comparisons with NULL count as
+// false, so NOT league=X also matches players without a league.
+func (p *filterParser) parseComparison() (string, error) {
+	p.comparisons++
+	if p.comparisons > maxFilterComparisons {
+		return "", fmt.Errorf("at most %d comparisons are allowed", maxFilterComparisons)
+	}
+
+	t := p.peek()
+	if t.kind != filterWord {
+		return "", fmt.Errorf("expected a column, got %q", t.text)
+	}
+	column := strings.ToLower(t.text)
+	if alias, ok := filterColumnAliases[column]; ok {
+		column = alias
+	}
+	isNumber := p.numberColumns[column]
+	if !isNumber && !filterTextColumns[column] {
+		return "", fmt.Errorf("cannot filter on %q", t.text)
+	}
+	p.pos++
+
+	t = p.peek()
+	if t == nil || t.kind != filterOperator {
+		return "", fmt.Errorf("expected an operator after %s", column)
+	}
+	op := t.text
+	if op == "==" {
+		op = "="
+	}
+	p.pos++
+
+	value, err := p.parseValue()
+	if err != nil {
+		return "", err
+	}
+
+	var arg interface{} = value
+	if isNumber {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("%s must be compared with an integer", column)
+		}
+		arg = number
+	} else if op != "=" && op != "!=" {
+		return "", fmt.Errorf("%s only supports = and !=", column)
+	}
+
+	placeholder := "$" + strconv.Itoa(p.argIndex)
+	p.args = append(p.args, arg)
+	p.argIndex++
+
+	if op == "!=" {
+		return column + " IS DISTINCT FROM " + placeholder, nil
+	}
+	return "COALESCE(" + column + " " + op + " " + placeholder + ", false)", nil
+}
+
+// parseValue reads a quoted value, or bare words up to the next keyword,
+// parenthesis or operator so that league=Premier League needs no quotes
+func (p *filterParser) parseValue() (string, error) {
+	t := p.peek()
+	if t == nil {
+		return "", fmt.Errorf("unexpected end of filter")
+	}
+	if t.kind == filterQuoted {
+		p.pos++
+		return t.text, nil
+	}
+
+	var words []string
+	for t := p.peek(); t != nil && t.kind == filterWord && !t.isKeyword("AND") && !t.isKeyword("OR"); t = p.peek() {
+		words = append(words, t.text)
+		p.pos++
+	}
+	if len(words) == 0 {
+		return "", fmt.Errorf("expected a value, got %q", t.text)
+	}
+	return strings.Join(words, " "), nil
+}
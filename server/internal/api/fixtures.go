@@ -0,0 +1,158 @@
+package api
+
+import (
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// fixtureColumns selects a database.Fixture from fixtures f with the team
+// names joined from draft_participants h and a
+const fixtureColumns = `f.id, f.draft_id, f.round_number, f.home_team_id, f.away_team_id,
+	h.name AS home_team_name, a.name AS away_team_name, f.match_id, f.group_name`
+
+// roundRobinPairing is one fixture of a generated schedule
+type roundRobinPairing struct {
+	Round  int
+	HomeID int
+	AwayID int
+}
+
+// generateRoundRobin schedules every team against every other once with the
+// circle method: the first team stays put while the rest rotate around it
+// each round. An odd number of teams gets a bye slot, whose opponent sits the
+// round out. The fixed team switches home and away every round, the rotation
+// balances everyone else.
+func generateRoundRobin(teamIDs []int) []roundRobinPairing {
+	const bye = 0
+
+	teams := append([]int{}, teamIDs...)
+	if len(teams)%2 == 1 {
+		teams = append(teams, bye)
+	}
+	n := len(teams)
+
+	var pairings []roundRobinPairing
+	for round := 0; round < n-1; round++ {
+		for i := 0; i < n/2; i++ {
+			home, away := teams[i], teams[n-1-i]
+			if i == 0 && round%2 == 1 {
+				home, away = away, home
+			}
+			if home == bye || away == bye {
+				continue
+			}
+			pairings = append(pairings, roundRobinPairing{Round: round + 1, HomeID: home, AwayID: away})
+		}
+
+		// Rotate everyone but the first team one place clockwise
+		last := teams[n-1]
+		copy(teams[2:], teams[1:n-1])
+		teams[1] = last
+	}
+	return pairings
+}
+
+// mirrorRoundRobin returns the second half of a double round-robin: the same
+// rounds again after the first half, with home and away swapped
+func mirrorRoundRobin(pairings []roundRobinPairing) []roundRobinPairing {
+	rounds := 0
+	for _, pairing := range pairings {
+		if pairing.Round > rounds {
+			rounds = pairing.Round
+		}
+	}
+
+	mirrored := make([]roundRobinPairing, len(pairings))
+	for i, pairing := range pairings {
+		mirrored[i] = roundRobinPairing{Round: pairing.Round + rounds, HomeID: pairing.AwayID, AwayID: pairing.HomeID}
+	}
+	return mirrored
+}
+
+// createFixtures generates the round-robin schedule for a draft's
+// participants in draft order, then attaches any matches already recorded
+func createFixtures(tx *sqlx.Tx, draft database.Draft) error {
+	var teamIDs []int
+	err := tx.Select(&teamIDs, `
+		SELECT id FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRoundRobin(tx, draft, teamIDs, nil); err != nil {
+		return err
+	}
+	return linkRecordedMatches(tx, draft.ID)
+}
+
+// insertRoundRobin stores the fixtures of a round-robin between the teams,
+// twice over for a double round-robin. groupName is set for group stages.
+func insertRoundRobin(tx *sqlx.Tx, draft database.Draft, teamIDs []int, groupName *string) error {
+	pairings := generateRoundRobin(teamIDs)
+	if draft.DoubleRoundRobin {
+		pairings = append(pairings, mirrorRoundRobin(pairings)...)
+	}
+
+	for _, pairing := range pairings {
+		_, err := tx.Exec(`
+			INSERT INTO fixtures (draft_id, round_number, home_team_id, away_team_id, group_name)
+			VALUES ($1, $2, $3, $4, $5)
+		`, draft.ID, pairing.Round, pairing.HomeID, pairing.AwayID, groupName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkRecordedMatches attaches the matches recorded before the schedule
+// existed to its fixtures, oldest first
+func linkRecordedMatches(tx *sqlx.Tx, draftID int) error {
+	var matches []database.Match
+	err := tx.Select(&matches, `
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE draft_id = $1 AND status = 'confirmed' ORDER BY played_at, id
+	`, draftID)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if err := linkMatchToFixture(tx, match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkMatchToFixture marks the earliest unplayed fixture between the two
+// teams as played by the match, preferring one with the same home team. A
+// match outside the schedule is left unlinked.
+func linkMatchToFixture(e sqlx.Execer, match database.Match) error {
+	_, err := e.Exec(`
+		UPDATE fixtures SET match_id = $1
+		WHERE id = (
+			SELECT id FROM fixtures
+			WHERE draft_id = $2 AND match_id IS NULL
+			  AND ((home_team_id = $3 AND away_team_id = $4) OR (home_team_id = $4 AND away_team_id = $3))
+			ORDER BY (home_team_id = $3) DESC, round_number, id
+			LIMIT 1
+		)
+	`, match.ID, match.DraftID, match.HomeTeamID, match.AwayTeamID)
+	return err
+}
+
+// loadFixtures returns a draft's schedule in round order, group by group
+func loadFixtures(q sqlx.Queryer, draftID int) ([]database.Fixture, error) {
+	fixtures := []database.Fixture{}
+	err := sqlx.Select(q, &fixtures, `
+		SELECT `+fixtureColumns+`
+		FROM fixtures f
+		JOIN draft_participants h ON h.id = f.home_team_id
+		JOIN draft_participants a ON a.id = f.away_team_id
+		WHERE f.draft_id = $1
+		ORDER BY f.group_name NULLS FIRST, f.round_number, f.id
+	`, draftID)
+	return fixtures, err
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// squadPlayer is a drafted player with the details squad analysis needs
+type squadPlayer struct {
+	ParticipantID      int     `db:"participant_id" json:"-"`
+	PlayerID           int     `db:"player_id" json:"playerId"`
+	FirstName          *string `db:"first_name" json:"-"`
+	LastName           *string `db:"last_name" json:"-"`
+	CommonName         *string `db:"common_name" json:"-"`
+	OverallRating      *int    `db:"overall_rating" json:"overallRating"`
+	Position           *string `db:"position_short_label" json:"position"`
+	AlternatePositions *string `db:"alternate_positions" json:"-"`
+	Club               *string `db:"team_label" json:"club"`
+	League             *string `db:"league_name" json:"league"`
+	Nationality        *string `db:"nationality_label" json:"nationality"`
+}
+
+// loadSquadPlayers returns every drafted player of a draft, keyed by the
+// participant who picked them
+func loadSquadPlayers(q sqlx.Queryer, draftID int) (map[int][]squadPlayer, error) {
+	var players []squadPlayer
+	err := sqlx.Select(q, &players, `
+		SELECT dp.participant_id, dp.player_id, p.first_name, p.last_name, p.common_name,
+		       p.overall_rating, p.position_short_label, p.alternate_positions,
+		       p.team_label, p.league_name, p.nationality_label
+		FROM draft_picks dp
+		JOIN drafts d ON dp.draft_id = d.id
+		JOIN players p ON dp.player_id = p.id AND p.dataset = d.dataset
+		WHERE dp.draft_id = $1
+		ORDER BY dp.overall_pick_number
+	`, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	squads := make(map[int][]squadPlayer)
+	for _, player := range players {
+		squads[player.ParticipantID] = append(squads[player.ParticipantID], player)
+	}
+	return squads, nil
+}
+
+type SquadGrade struct {
+	ParticipantName string `json:"participantName"`
+	Rank            int    `json:"rank"`
+	Grade           string `json:"grade"`
+	Score           int    `json:"score"` // 0-100, weighted from the component scores
+
+	RatingScore    int `json:"ratingScore"`
+	BalanceScore   int `json:"balanceScore"`
+	ChemistryScore int `json:"chemistryScore"`
+
+	Chemistry SquadChemistry `json:"chemistry"`
+
+	AverageRating      *float64       `json:"averageRating"`
+	RatingDistribution map[string]int `json:"ratingDistribution"` // picks per rating tier
+	PositionCounts     map[string]int `json:"positionCounts"`     // picks per position group
+	MissingPositions   []string       `json:"missingPositions"`   // groups short of a standard squad shape
+}
+
+type DraftGradesResponse struct {
+	Draft  database.Draft `json:"draft"`
+	Grades []SquadGrade   `json:"grades"`
+}
+
+// getDraftGrades grades every squad of a finished draft on ratings,
+// positional balance and chemistry
+func (h *Handler) getDraftGrades(w http.ResponseWriter, r *http.Request, code string) {
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for grades error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.Status != "completed" && draft.Status != "tournament" {
+		http.Error(w, "Draft is not completed yet", http.StatusBadRequest)
+		return
+	}
+
+	var participants []database.DraftParticipant
+	err = h.db.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Get participants for grades error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	squads, err := loadSquadPlayers(h.db, draft.ID)
+	if err != nil {
+		log.Printf("Get squads for grades error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	grades := make([]SquadGrade, 0, len(participants))
+	for _, participant := range participants {
+		grade := gradeSquad(squads[participant.ID])
+		grade.ParticipantName = participant.Name
+		grades = append(grades, grade)
+	}
+
+	sort.SliceStable(grades, func(i, j int) bool {
+		return grades[i].Score > grades[j].Score
+	})
+	for i := range grades {
+		grades[i].Rank = i + 1
+	}
+
+	response := DraftGradesResponse{
+		Draft:  draft,
+		Grades: grades,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// gradeSquad scores a squad. Ratings count for half of the grade, positional
+// balance against botSquadTargets for 30% and squad chemistry for 20%.
+func gradeSquad(players []squadPlayer) SquadGrade {
+	grade := SquadGrade{
+		RatingDistribution: map[string]int{"85-89": 0, "80-84": 0, "75-79": 0},
+		PositionCounts:     map[string]int{},
+		MissingPositions:   []string{},
+	}
+
+	ratingTotal, rated := 0, 0
+	for _, player := range players {
+		if player.OverallRating != nil {
+			ratingTotal += *player.OverallRating
+			rated++
+			switch {
+			case *player.OverallRating >= 85:
+				grade.RatingDistribution["85-89"]++
+			case *player.OverallRating >= 80:
+				grade.RatingDistribution["80-84"]++
+			default:
+				grade.RatingDistribution["75-79"]++
+			}
+		}
+		if player.Position != nil {
+			if group := positionGroupOf(*player.Position); group != "" {
+				grade.PositionCounts[group]++
+			}
+		}
+	}
+
+	// An average of 70 scores nothing, 85 and above scores full marks
+	if rated > 0 {
+		average := roundTo2(float64(ratingTotal) / float64(rated))
+		grade.AverageRating = &average
+		grade.RatingScore = clampScore((average - 70) / 15 * 100)
+	}
+
+	covered, needed := 0, 0
+	for _, group := range []string{"GK", "DEF", "MID", "FWD"} {
+		target := botSquadTargets[group]
+		needed += target
+		covered += min(grade.PositionCounts[group], target)
+		if grade.PositionCounts[group] < target {
+			grade.MissingPositions = append(grade.MissingPositions, group)
+		}
+	}
+	grade.BalanceScore = clampScore(float64(covered) / float64(needed) * 100)
+
+	grade.Chemistry = calculateSquadChemistry(players)
+	if grade.Chemistry.MaxScore > 0 {
+		grade.ChemistryScore = clampScore(float64(grade.Chemistry.Score) / float64(grade.Chemistry.MaxScore) * 100)
+	}
+
+	grade.Score = clampScore(0.5*float64(grade.RatingScore) + 0.3*float64(grade.BalanceScore) + 0.2*float64(grade.ChemistryScore))
+	grade.Grade = letterGrade(grade.Score)
+	return grade
+}
+
+func clampScore(value float64) int {
+	return int(math.Round(math.Max(0, math.Min(100, value))))
+}
+
+func letterGrade(score int) string {
+	grades := []struct {
+		min   int
+		grade string
+	}{
+		{90, "A+"}, {85, "A"}, {80, "A-"},
+		{75, "B+"}, {70, "B"}, {65, "B-"},
+		{60, "C+"}, {55, "C"}, {50, "C-"},
+		{40, "D"},
+	}
+	for _, g := range grades {
+		if score >= g.min {
+			return g.grade
+		}
+	}
+	return "F"
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// TournamentFormatGroups plays round-robin groups, then a knockout bracket
+// seeded from the group tables
+const TournamentFormatGroups = "groups"
+
+const (
+	defaultGroupCount      = 2
+	defaultGroupQualifiers = 2
+	maxGroupCount          = 8
+)
+
+type GroupStandings struct {
+	Name      string         `json:"name"`
+	Standings []TeamStanding `json:"standings"`
+}
+
+// tournamentGroup is a group's members and the matches of its fixtures
+type tournamentGroup struct {
+	Name         string
+	Participants []database.DraftParticipant
+	Matches      []database.Match
+}
+
+// groupName returns the letter of the i-th group: A, B, C...
+func groupName(i int) string {
+	return string(rune('A' + i))
+}
+
+// validateGroupSettings checks the group stage fits the field: every group
+// needs two teams, and at least two teams must reach the knockouts
+func validateGroupSettings(groupCount, qualifiers, participantCount int) error {
+	if groupCount < 1 || groupCount > maxGroupCount {
+		return newStatusError(http.StatusBadRequest, "groupCount must be between 1 and 8")
+	}
+	if participantCount < groupCount*2 {
+		return newStatusError(http.StatusBadRequest, "Every group needs at least 2 participants")
+	}
+	smallestGroup := participantCount / groupCount
+	if qualifiers < 1 || qualifiers > smallestGroup {
+		return newStatusError(http.StatusBadRequest, "groupQualifiers must be between 1 and the size of the smallest group")
+	}
+	if groupCount*qualifiers < 2 {
+		return newStatusError(http.StatusBadRequest, "At least 2 participants must qualify for the knockouts")
+	}
+	return nil
+}
+
+// createGroupStage deals the seeded teams into groups like pots, snaking
+// back and forth so every group gets an even spread of seeds, and schedules
+// a round-robin within each group
+func createGroupStage(tx *sqlx.Tx, draft database.Draft, seededTeamIDs []int) error {
+	groups := make([][]int, draft.GroupCount)
+	for i, teamID := range seededTeamIDs {
+		index := i % draft.GroupCount
+		if (i/draft.GroupCount)%2 == 1 {
+			index = draft.GroupCount - 1 - index
+		}
+		groups[index] = append(groups[index], teamID)
+	}
+
+	for i, members := range groups {
+		name := groupName(i)
+		_, err := tx.Exec(`
+			UPDATE draft_participants SET tournament_group = $1 WHERE id = ANY($2)
+		`, name, pq.Array(members))
+		if err != nil {
+			return err
+		}
+		if err := insertRoundRobin(tx, draft, members, &name); err != nil {
+			return err
+		}
+	}
+	return linkRecordedMatches(tx, draft.ID)
+}
+
+// splitGroups sorts the participants into their groups, with the matches
+// played for each group's fixtures. Matches outside the group stage, such as
+// knockout ties, are left out.
+func splitGroups(participants []database.DraftParticipant, matches []database.Match, fixtures []database.Fixture) []tournamentGroup {
+	groupOfMatch := make(map[int]string)
+	for _, fixture := range fixtures {
+		if fixture.GroupName != nil && fixture.MatchID != nil {
+			groupOfMatch[*fixture.MatchID] = *fixture.GroupName
+		}
+	}
+
+	byName := make(map[string]*tournamentGroup)
+	var names []string
+	for _, participant := range participants {
+		if participant.TournamentGroup == nil {
+			continue
+		}
+		name := *participant.TournamentGroup
+		if byName[name] == nil {
+			byName[name] = &tournamentGroup{Name: name}
+			names = append(names, name)
+		}
+		byName[name].Participants = append(byName[name].Participants, participant)
+	}
+	for _, match := range matches {
+		if group := byName[groupOfMatch[match.ID]]; group != nil {
+			group.Matches = append(group.Matches, match)
+		}
+	}
+
+	sort.Strings(names)
+	groups := make([]tournamentGroup, len(names))
+	for i, name := range names {
+		groups[i] = *byName[name]
+	}
+	return groups
+}
+
+// groupStandings computes each group's table
+func (h *Handler) groupStandings(participants []database.DraftParticipant, matches []database.Match, fixtures []database.Fixture, rules database.TournamentSettings) []GroupStandings {
+	result := []GroupStandings{}
+	for _, group := range splitGroups(participants, matches, fixtures) {
+		result = append(result, GroupStandings{
+			Name:      group.Name,
+			Standings: calculateStandings(group.Participants, group.Matches, rules),
+		})
+	}
+	return result
+}
+
+// startGroupKnockout seeds the knockout bracket once every group fixture has
+// been played. Group winners are the top seeds, then the runners-up and so
+// on, each in group order, so group winners meet runners-up from another
+// group in the first round. It reports whether the knockouts started.
+func (h *Handler) startGroupKnockout(tx *sqlx.Tx, draft database.Draft) (bool, error) {
+	var unplayed int
+	err := tx.Get(&unplayed, `
+		SELECT COUNT(*) FROM fixtures WHERE draft_id = $1 AND group_name IS NOT NULL AND match_id IS NULL
+	`, draft.ID)
+	if err != nil || unplayed > 0 {
+		return false, err
+	}
+
+	var started bool
+	if err := tx.Get(&started, "SELECT EXISTS(SELECT 1 FROM knockout_ties WHERE draft_id = $1)", draft.ID); err != nil || started {
+		return false, err
+	}
+
+	var participants []database.DraftParticipant
+	err = tx.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		return false, err
+	}
+
+	var matches []database.Match
+	err = tx.Select(&matches, `
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE draft_id = $1 AND status = 'confirmed' AND `+leagueMatchCondition+`
+	`, draft.ID)
+	if err != nil {
+		return false, err
+	}
+
+	fixtures, err := loadFixtures(tx, draft.ID)
+	if err != nil {
+		return false, err
+	}
+
+	tables := h.groupStandings(participants, matches, fixtures, draft.TournamentSettings)
+	var seeded []int
+	for position := 0; position < draft.GroupQualifiers; position++ {
+		for _, table := range tables {
+			if position < len(table.Standings) {
+				seeded = append(seeded, table.Standings[position].TeamID)
+			}
+		}
+	}
+
+	if err := createBracket(tx, draft.ID, seeded); err != nil {
+		return false, err
+	}
+	log.Printf("Group stage finished for draft %d, %d teams go through to the knockouts", draft.ID, len(seeded))
+	return true, nil
+}
+
+// inKnockoutPhase reports whether results now settle knockout ties: always
+// in a knockout tournament, and once the bracket exists in a groups one or
+// a league one with playoffs
+func inKnockoutPhase(q sqlx.Queryer, draft database.Draft) (bool, error) {
+	if draft.TournamentFormat == TournamentFormatKnockout {
+		return true, nil
+	}
+	var exists bool
+	err := sqlx.Get(q, &exists, "SELECT EXISTS(SELECT 1 FROM knockout_ties WHERE draft_id = $1)", draft.ID)
+	return exists, err
+}
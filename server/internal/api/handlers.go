@@ -1,6 +1,8 @@
 package api
 
 import (
+	"errors"
+	"log"
 	"net/http"
 
 	"eafc-draft-server/internal/config"
@@ -12,6 +14,10 @@ type Handler struct {
 	db            *sqlx.DB
 	config        *config.Config
 	broadcastFunc func(*sqlx.DB, string) // Function to broadcast draft state
+	maintenance   *maintenanceState
+	playerData    *playerDataState
+	playerSync    *playerSyncState
+	shutdown      *shutdownState
 }
 
 func NewHandler(db *sqlx.DB, cfg *config.Config) *Handler {
@@ -19,6 +25,10 @@ func NewHandler(db *sqlx.DB, cfg *config.Config) *Handler {
 		db:            db,
 		config:        cfg,
 		broadcastFunc: nil,
+		maintenance:   &maintenanceState{enabled: cfg.MaintenanceMode, message: cfg.MaintenanceMessage},
+		playerData:    newPlayerDataState(),
+		playerSync:    &playerSyncState{},
+		shutdown:      &shutdownState{},
 	}
 }
 
@@ -35,11 +45,42 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/players", h.corsMiddleware(h.getPlayers))
 	mux.HandleFunc("/api/players/search", h.corsMiddleware(h.searchPlayers))
 	mux.HandleFunc("/api/players/enums", h.corsMiddleware(h.getPlayerEnums))
+	mux.HandleFunc("/api/players/datasets", h.corsMiddleware(h.getPlayerDatasets))
+	mux.HandleFunc("/api/players/random", h.corsMiddleware(h.getRandomPlayers))
+	mux.HandleFunc("/api/players/aggregates", h.corsMiddleware(h.getPlayerAggregates))
+	mux.HandleFunc("/api/players/top", h.corsMiddleware(h.getTopPlayers))
+	mux.HandleFunc("/api/players/batch", h.corsMiddleware(h.getPlayersBatch))
+	mux.HandleFunc("/api/players/", h.corsMiddleware(h.handlePlayerOperations))
 
 	// Draft endpoints
 	mux.HandleFunc("/api/drafts", h.corsMiddleware(h.handleDrafts))
+	mux.HandleFunc("/api/drafts/mock", h.corsMiddleware(h.createMockDraft))
+
+	// Draft settings templates
+	mux.HandleFunc("/api/templates", h.corsMiddleware(h.handleTemplates))
+	mux.HandleFunc("/api/templates/", h.corsMiddleware(h.handleTemplates))
 	mux.HandleFunc("/api/drafts/", h.corsMiddleware(h.handleDraftOperations))
 
+	// Leagues, grouping drafts into seasons
+	mux.HandleFunc("/api/leagues", h.corsMiddleware(h.handleLeagues))
+	mux.HandleFunc("/api/leagues/", h.corsMiddleware(h.handleLeagues))
+
+	// Server-wide Elo ratings
+	mux.HandleFunc("/api/ratings", h.corsMiddleware(h.handleRatings))
+	mux.HandleFunc("/api/ratings/", h.corsMiddleware(h.handleRatings))
+
+	// Operator endpoints
+	mux.HandleFunc("/api/admin/maintenance", h.adminMiddleware(h.handleMaintenance))
+	mux.HandleFunc("/api/admin/players/import", h.adminMiddleware(h.importPlayers))
+	mux.HandleFunc("/api/admin/players/sync", h.adminMiddleware(h.handlePlayerSync))
+	mux.HandleFunc("/api/admin/prices/sync", h.adminMiddleware(h.handlePriceSync))
+	mux.HandleFunc("/api/admin/players/aliases", h.adminMiddleware(h.handlePlayerAliases))
+	mux.HandleFunc("/api/admin/players/aliases/", h.adminMiddleware(h.handlePlayerAliases))
+	mux.HandleFunc("/api/admin/drafts/", h.adminMiddleware(h.resetParticipantToken))
+
+	// Public read-only embed endpoints
+	mux.HandleFunc("/embed/drafts/", h.openCorsMiddleware(h.handleEmbed))
+
 	// WebSocket endpoint
 	mux.HandleFunc("/ws/drafts/", h.handleDraftWebSocket)
 }
@@ -51,7 +92,7 @@ func (h *Handler) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		// Set CORS headers first
 		w.Header().Set("Access-Control-Allow-Origin", h.config.AllowedOrigin)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+participantTokenHeader+", "+templateTokenHeader)
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		// Handle preflight requests
@@ -75,3 +116,30 @@ func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("healthy"))
 }
+
+// statusError is an error carrying the HTTP status it should be reported with,
+// for logic shared between HTTP handlers and WebSocket messages
+type statusError struct {
+	Status  int
+	Message string
+}
+
+func (e *statusError) Error() string {
+	return e.Message
+}
+
+func newStatusError(status int, message string) error {
+	return &statusError{Status: status, Message: message}
+}
+
+// writeStatusError writes err as an HTTP error, using its status if it has
+// one. Anything else is unexpected, so it is logged and not shown.
+func writeStatusError(w http.ResponseWriter, err error) {
+	var se *statusError
+	if errors.As(err, &se) {
+		http.Error(w, se.Message, se.Status)
+		return
+	}
+	log.Printf("Request error: %v", err)
+	http.Error(w, "Database error", http.StatusInternalServerError)
+}
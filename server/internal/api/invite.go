@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+	"eafc-draft-server/internal/qrcode"
+)
+
+// inviteQRScale is the size of one QR module in pixels, big enough to scan
+// from across the room
+const inviteQRScale = 10
+
+type InviteResponse struct {
+	Code      string `json:"code"`
+	JoinURL   string `json:"joinUrl"`
+	QRCode    string `json:"qrCode"` // PNG as a data URL
+	IsPrivate bool   `json:"isPrivate"`
+}
+
+// getInvite returns a shareable join link for the draft and a QR code of it.
+// With ?format=png the QR code is served as an image.
+func (h *Handler) getInvite(w http.ResponseWriter, r *http.Request, code string) {
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for invite error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	joinURL := strings.TrimRight(h.config.AllowedOrigin, "/") + "/draft/" + url.PathEscape(draft.Code)
+
+	image, err := qrcode.PNG(joinURL, inviteQRScale)
+	if err != nil {
+		log.Printf("Generate invite QR code error: %v", err)
+		http.Error(w, "Failed to generate QR code", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "png" {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(image)
+		return
+	}
+
+	response := InviteResponse{
+		Code:      draft.Code,
+		JoinURL:   joinURL,
+		QRCode:    "data:image/png;base64," + base64.StdEncoding.EncodeToString(image),
+		IsPrivate: draft.IsPrivate,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type KeeperAssignment struct {
+	ParticipantName string `json:"participantName"`
+	PlayerID        int    `json:"playerId"`
+	RoundNumber     int    `json:"roundNumber"`
+}
+
+type SetKeepersRequest struct {
+	AdminName string             `json:"adminName"`
+	Keepers   []KeeperAssignment `json:"keepers"`
+}
+
+type KeepersResponse struct {
+	Keepers []database.DraftKeeper `json:"keepers"`
+}
+
+func (h *Handler) handleKeepers(w http.ResponseWriter, r *http.Request, code string) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getKeepers(w, r, code)
+	case http.MethodPut:
+		h.setKeepers(w, r, code)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) getKeepers(w http.ResponseWriter, r *http.Request, code string) {
+	keepers := []database.DraftKeeper{}
+	err := h.db.Select(&keepers, `
+		SELECT k.id, k.draft_id, k.participant_id, k.player_id, k.round_number, part.name as participant_name
+		FROM draft_keepers k
+		JOIN drafts d ON k.draft_id = d.id
+		JOIN draft_participants part ON k.participant_id = part.id
+		WHERE d.code = $1
+		ORDER BY k.round_number, part.draft_order
+	`, code)
+	if err != nil {
+		log.Printf("Get keepers error: %v", err)
+		http.Error(w, "Failed to fetch keepers", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(KeepersResponse{Keepers: keepers})
+}
+
+// setKeepers replaces the keeper list of a draft that hasn't started yet.
+// Keepers are validated against the same tier quotas as regular picks.
+func (h *Handler) setKeepers(w http.ResponseWriter, r *http.Request, code string) {
+	var req SetKeepersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Set keepers decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Get draft and verify admin
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for keepers error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can assign keepers", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.Status != "waiting" {
+		http.Error(w, "Keepers can only be assigned before the draft starts", http.StatusBadRequest)
+		return
+	}
+
+	var participants []database.DraftParticipant
+	err = tx.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Get participants for keepers error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	participantsByName := make(map[string]*database.DraftParticipant, len(participants))
+	for i := range participants {
+		participantsByName[participants[i].Name] = &participants[i]
+	}
+
+	seenPlayers := make(map[int]bool)
+	seenRounds := make(map[string]bool)
+	for _, keeper := range req.Keepers {
+		participant, ok := participantsByName[keeper.ParticipantName]
+		if !ok {
+			http.Error(w, fmt.Sprintf("Participant %s is not in this draft", keeper.ParticipantName), http.StatusBadRequest)
+			return
+		}
+
+		if keeper.RoundNumber < 1 || keeper.RoundNumber > draft.TotalRounds {
+			http.Error(w, fmt.Sprintf("Round %d is out of range", keeper.RoundNumber), http.StatusBadRequest)
+			return
+		}
+
+		roundKey := fmt.Sprintf("%d:%d", participant.ID, keeper.RoundNumber)
+		if seenRounds[roundKey] {
+			http.Error(w, fmt.Sprintf("%s has more than one keeper in round %d", participant.Name, keeper.RoundNumber), http.StatusBadRequest)
+			return
+		}
+		seenRounds[roundKey] = true
+
+		if seenPlayers[keeper.PlayerID] {
+			http.Error(w, fmt.Sprintf("Player %d is kept more than once", keeper.PlayerID), http.StatusBadRequest)
+			return
+		}
+		seenPlayers[keeper.PlayerID] = true
+
+		var player database.Player
+		err = tx.Get(&player, "SELECT id, overall_rating, position_short_label FROM players WHERE id = $1 AND dataset = $2", keeper.PlayerID, draft.Dataset)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Player %d not found", keeper.PlayerID), http.StatusBadRequest)
+			return
+		}
+		if player.OverallRating == nil {
+			http.Error(w, fmt.Sprintf("Player %d has no rating", keeper.PlayerID), http.StatusBadRequest)
+			return
+		}
+
+		// Count the keeper against the participant's quota
+		tier := h.getRatingTier(*player.OverallRating)
+		if tier == "invalid" {
+			http.Error(w, "Cannot keep players rated 90+", http.StatusBadRequest)
+			return
+		}
+		if !h.canPickFromTier(draft, *participant, tier) {
+			http.Error(w, fmt.Sprintf("%s: %v", participant.Name, h.formatQuotaError(draft, *participant, tier)), http.StatusBadRequest)
+			return
+		}
+		if !isGoalkeeper(player.PositionShortLabel) && mustPickGoalkeeper(draft, *participant) {
+			http.Error(w, fmt.Sprintf("%s: %v", participant.Name, goalkeeperMinimumError(draft, *participant)), http.StatusBadRequest)
+			return
+		}
+		incrementTierCount(participant, tier)
+
+		if isGoalkeeper(player.PositionShortLabel) {
+			if !canPickGoalkeeper(draft, *participant) {
+				http.Error(w, fmt.Sprintf("%s: %v", participant.Name, goalkeeperQuotaError(draft, *participant)), http.StatusBadRequest)
+				return
+			}
+			participant.PicksGK++
+		}
+	}
+
+	_, err = tx.Exec("DELETE FROM draft_keepers WHERE draft_id = $1", draft.ID)
+	if err != nil {
+		log.Printf("Clear keepers error: %v", err)
+		http.Error(w, "Failed to save keepers", http.StatusInternalServerError)
+		return
+	}
+
+	for _, keeper := range req.Keepers {
+		_, err = tx.Exec(`
+			INSERT INTO draft_keepers (draft_id, participant_id, player_id, round_number)
+			VALUES ($1, $2, $3, $4)
+		`, draft.ID, participantsByName[keeper.ParticipantName].ID, keeper.PlayerID, keeper.RoundNumber)
+		if err != nil {
+			log.Printf("Insert keeper error: %v", err)
+			http.Error(w, "Failed to save keepers", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventKeepersSet, req.AdminName, req.Keepers); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to save keepers", http.StatusInternalServerError)
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to save keepers", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Admin set %d keepers for draft %s", len(req.Keepers), code)
+
+	h.getKeepers(w, r, code)
+}
+
+// incrementTierCount bumps the in-memory quota counter for a tier, mirroring
+// updateParticipantQuota
+func incrementTierCount(participant *database.DraftParticipant, tier string) {
+	switch tier {
+	case "85-89":
+		participant.Picks8589++
+	case "80-84":
+		participant.Picks8084++
+	case "75-79":
+		participant.Picks7579++
+	}
+}
+
+// placeKeepers turns the draft's keepers into picks once the draft order is
+// final. Each keeper fills its participant's slot in the chosen round.
+func (h *Handler) placeKeepers(tx *sqlx.Tx, draft database.Draft, participants []database.DraftParticipant) error {
+	var keepers []database.DraftKeeper
+	err := tx.Select(&keepers, `
+		SELECT k.id, k.draft_id, k.participant_id, k.player_id, k.round_number, part.name as participant_name
+		FROM draft_keepers k
+		JOIN draft_participants part ON k.participant_id = part.id
+		WHERE k.draft_id = $1
+	`, draft.ID)
+	if err != nil {
+		return fmt.Errorf("get keepers: %w", err)
+	}
+
+	orders := make(map[int]int, len(participants))
+	for _, participant := range participants {
+		orders[participant.ID] = participant.DraftOrder
+	}
+
+	for _, keeper := range keepers {
+		pickInRound := 0
+		for pick := 1; pick <= draft.ParticipantCount; pick++ {
+			if calculateCurrentPicker(draft.OrderMode, keeper.RoundNumber, pick, draft.ParticipantCount) == orders[keeper.ParticipantID] {
+				pickInRound = pick
+				break
+			}
+		}
+		if pickInRound == 0 {
+			return fmt.Errorf("no slot for keeper %d in round %d", keeper.PlayerID, keeper.RoundNumber)
+		}
+
+		var player database.Player
+		if err := tx.Get(&player, "SELECT id, overall_rating, position_short_label FROM players WHERE id = $1 AND dataset = $2", keeper.PlayerID, draft.Dataset); err != nil {
+			return fmt.Errorf("get keeper player: %w", err)
+		}
+		tier := h.getRatingTier(*player.OverallRating)
+
+		overallPickNumber := (keeper.RoundNumber-1)*draft.ParticipantCount + pickInRound
+		_, err = tx.Exec(`
+			INSERT INTO draft_picks (draft_id, participant_id, player_id, round_number, pick_in_round, 
+			                        overall_pick_number, player_rating_tier, is_keeper) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, true)
+		`, draft.ID, keeper.ParticipantID, keeper.PlayerID, keeper.RoundNumber, pickInRound,
+			overallPickNumber, tier)
+		if err != nil {
+			return fmt.Errorf("insert keeper pick: %w", err)
+		}
+
+		if err := h.updateParticipantQuota(tx, keeper.ParticipantID, tier); err != nil {
+			return fmt.Errorf("update keeper quota: %w", err)
+		}
+		if isGoalkeeper(player.PositionShortLabel) {
+			if err := updateGoalkeeperQuota(tx, keeper.ParticipantID); err != nil {
+				return fmt.Errorf("update keeper quota: %w", err)
+			}
+		}
+
+		err = recordDraftEvent(tx, draft.ID, EventPick, keeper.ParticipantName, pickEvent{
+			ParticipantName:   keeper.ParticipantName,
+			PlayerID:          keeper.PlayerID,
+			RoundNumber:       keeper.RoundNumber,
+			PickInRound:       pickInRound,
+			OverallPickNumber: overallPickNumber,
+			RatingTier:        tier,
+			IsKeeper:          true,
+		})
+		if err != nil {
+			return fmt.Errorf("record keeper event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// nextOpenSlot advances from the given turn past any slots already filled by
+// keepers. The returned round is past totalRounds when the draft is complete.
+func (h *Handler) nextOpenSlot(tx *sqlx.Tx, draftID, round, pickInRound, participantCount, totalRounds int) (int, int, error) {
+	for round <= totalRounds {
+		var filled bool
+		err := tx.Get(&filled, `
+			SELECT EXISTS(SELECT 1 FROM draft_picks WHERE draft_id = $1 AND round_number = $2 AND pick_in_round = $3)
+		`, draftID, round, pickInRound)
+		if err != nil {
+			return round, pickInRound, err
+		}
+		if !filled {
+			break
+		}
+		round, pickInRound = h.calculateNextTurn(round, pickInRound, participantCount, totalRounds)
+	}
+	return round, pickInRound, nil
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Tournament formats: a league table, or a single elimination bracket
+const (
+	TournamentFormatLeague   = "league"
+	TournamentFormatKnockout = "knockout"
+)
+
+// Knockout seedings: by draft order, or by the league table so far
+const (
+	SeedingDraftOrder = "draft_order"
+	SeedingStandings  = "standings"
+)
+
+// knockoutTieColumns selects a database.KnockoutTie from knockout_ties t with
+// the team names joined from draft_participants h, a and w
+const knockoutTieColumns = `t.id, t.draft_id, t.round_number, t.slot, t.home_team_id, t.away_team_id,
+	h.name AS home_team_name, a.name AS away_team_name, t.home_seed, t.away_seed,
+	t.match_id, t.winner_id, w.name AS winner_name`
+
+// bracketSeedOrder returns the seeds of a bracket of size teams (a power of
+// two) in slot order, so that consecutive pairs meet in the first round and
+// the top seeds can only meet late: 1 v 8, 4 v 5, 2 v 7, 3 v 6.
+func bracketSeedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// createBracket builds a single elimination bracket for the teams in seed
+// order. The field is padded to a power of two with byes, which go to the
+// top seeds, and every later round is created empty to be filled as winners
+// advance.
+func createBracket(tx *sqlx.Tx, draftID int, seededTeamIDs []int) error {
+	size, rounds := 1, 0
+	for size < len(seededTeamIDs) {
+		size *= 2
+		rounds++
+	}
+
+	order := bracketSeedOrder(size)
+	for slot := 0; slot < size/2; slot++ {
+		homeSeed, awaySeed := order[slot*2], order[slot*2+1]
+		homeID := seededTeamIDs[homeSeed-1] // the higher seed always has a team
+
+		var awayID, awaySeedValue, winnerID *int
+		if awaySeed <= len(seededTeamIDs) {
+			awayID, awaySeedValue = &seededTeamIDs[awaySeed-1], &awaySeed
+		} else {
+			winnerID = &homeID // bye
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO knockout_ties (draft_id, round_number, slot, home_team_id, away_team_id, home_seed, away_seed, winner_id)
+			VALUES ($1, 1, $2, $3, $4, $5, $6, $7)
+		`, draftID, slot, homeID, awayID, homeSeed, awaySeedValue, winnerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for round := 2; round <= rounds; round++ {
+		for slot := 0; slot < size>>round; slot++ {
+			_, err := tx.Exec(`
+				INSERT INTO knockout_ties (draft_id, round_number, slot) VALUES ($1, $2, $3)
+			`, draftID, round, slot)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Teams with a bye go straight through to the second round
+	var byes []database.KnockoutTie
+	err := tx.Select(&byes, `
+		SELECT id, draft_id, round_number, slot, home_team_id, home_seed, winner_id
+		FROM knockout_ties WHERE draft_id = $1 AND round_number = 1 AND winner_id IS NOT NULL
+	`, draftID)
+	if err != nil {
+		return err
+	}
+	for _, tie := range byes {
+		if err := advanceKnockoutWinner(tx, tie, *tie.WinnerID, tie.HomeSeed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advanceKnockoutWinner puts the winner of a tie into their next round tie,
+// as the home team when they came from the upper half of the pair. The final
+// has no next tie.
+func advanceKnockoutWinner(e sqlx.Execer, tie database.KnockoutTie, winnerID int, winnerSeed *int) error {
+	side := "away"
+	if tie.Slot%2 == 0 {
+		side = "home"
+	}
+	_, err := e.Exec(`
+		UPDATE knockout_ties SET `+side+`_team_id = $1, `+side+`_seed = $2
+		WHERE draft_id = $3 AND round_number = $4 AND slot = $5
+	`, winnerID, winnerSeed, tie.DraftID, tie.RoundNumber+1, tie.Slot/2)
+	return err
+}
+
+// recordKnockoutResult settles the open tie between the match's teams and
+// sends the winner through to the next round. Errors are statusErrors.
+func recordKnockoutResult(tx *sqlx.Tx, match database.Match) error {
+	var tie database.KnockoutTie
+	err := tx.Get(&tie, `
+		SELECT id, draft_id, round_number, slot, home_team_id, away_team_id, home_seed, away_seed, winner_id
+		FROM knockout_ties
+		WHERE draft_id = $1 AND winner_id IS NULL
+		  AND ((home_team_id = $2 AND away_team_id = $3) OR (home_team_id = $3 AND away_team_id = $2))
+		FOR UPDATE
+	`, match.DraftID, match.HomeTeamID, match.AwayTeamID)
+	if err == sql.ErrNoRows {
+		return newStatusError(http.StatusBadRequest, "These teams don't have an open knockout tie")
+	}
+	if err != nil {
+		log.Printf("Get knockout tie error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to record match")
+	}
+
+	winnerID, ok := matchWinner(match)
+	if !ok {
+		return newStatusError(http.StatusBadRequest, "A knockout tie needs a winner, add extra time or penalties")
+	}
+	winnerSeed := tie.HomeSeed
+	if tie.AwayTeamID != nil && winnerID == *tie.AwayTeamID {
+		winnerSeed = tie.AwaySeed
+	}
+
+	_, err = tx.Exec(`
+		UPDATE knockout_ties SET match_id = $1, winner_id = $2 WHERE id = $3
+	`, match.ID, winnerID, tie.ID)
+	if err == nil {
+		err = advanceKnockoutWinner(tx, tie, winnerID, winnerSeed)
+	}
+	if err != nil {
+		log.Printf("Advance knockout winner error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to record match")
+	}
+	return nil
+}
+
+// loadBracket returns a draft's knockout ties by round and slot, empty for
+// league tournaments
+func loadBracket(q sqlx.Queryer, draftID int) ([]database.KnockoutTie, error) {
+	ties := []database.KnockoutTie{}
+	err := sqlx.Select(q, &ties, `
+		SELECT `+knockoutTieColumns+`
+		FROM knockout_ties t
+		LEFT JOIN draft_participants h ON h.id = t.home_team_id
+		LEFT JOIN draft_participants a ON a.id = t.away_team_id
+		LEFT JOIN draft_participants w ON w.id = t.winner_id
+		WHERE t.draft_id = $1
+		ORDER BY t.round_number, t.slot
+	`, draftID)
+	return ties, err
+}
+
+// seedTeams orders the participants for a knockout bracket or group draw,
+// best seed first
+func (h *Handler) seedTeams(q sqlx.Queryer, draft database.Draft, seeding string) ([]int, error) {
+	var participants []database.DraftParticipant
+	err := sqlx.Select(q, &participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	seeded := make([]int, 0, len(participants))
+	if seeding != SeedingStandings {
+		for _, participant := range participants {
+			seeded = append(seeded, participant.ID)
+		}
+		return seeded, nil
+	}
+
+	var matches []database.Match
+	err = sqlx.Select(q, &matches, `
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE draft_id = $1 AND status = 'confirmed' AND `+leagueMatchCondition+`
+	`, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, standing := range calculateStandings(participants, matches, draft.TournamentSettings) {
+		seeded = append(seeded, standing.TeamID)
+	}
+	return seeded, nil
+}
+
+// correctKnockoutResult re-settles the tie a match decided after its score
+// was edited. A new winner replaces the old one in the next round as long as
+// that tie hasn't been played yet. Matches outside the bracket are left
+// alone. Errors are statusErrors.
+func correctKnockoutResult(tx *sqlx.Tx, match database.Match) error {
+	var tie database.KnockoutTie
+	err := tx.Get(&tie, `
+		SELECT id, draft_id, round_number, slot, home_team_id, away_team_id, home_seed, away_seed, winner_id
+		FROM knockout_ties WHERE match_id = $1 FOR UPDATE
+	`, match.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		log.Printf("Get knockout tie for match error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to edit match")
+	}
+
+	winnerID, ok := matchWinner(match)
+	if !ok {
+		return newStatusError(http.StatusBadRequest, "A knockout tie needs a winner, add extra time or penalties")
+	}
+	if tie.WinnerID != nil && *tie.WinnerID == winnerID {
+		return nil
+	}
+	winnerSeed := tie.HomeSeed
+	if tie.AwayTeamID != nil && winnerID == *tie.AwayTeamID {
+		winnerSeed = tie.AwaySeed
+	}
+
+	var nextPlayed bool
+	err = tx.Get(&nextPlayed, `
+		SELECT EXISTS(
+			SELECT 1 FROM knockout_ties
+			WHERE draft_id = $1 AND round_number = $2 AND slot = $3 AND match_id IS NOT NULL
+		)
+	`, tie.DraftID, tie.RoundNumber+1, tie.Slot/2)
+	if err != nil {
+		log.Printf("Check next knockout tie error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to edit match")
+	}
+	if nextPlayed {
+		return newStatusError(http.StatusConflict, "The winner has already played their next tie")
+	}
+
+	_, err = tx.Exec("UPDATE knockout_ties SET winner_id = $1 WHERE id = $2", winnerID, tie.ID)
+	if err == nil {
+		err = advanceKnockoutWinner(tx, tie, winnerID, winnerSeed)
+	}
+	if err != nil {
+		log.Printf("Correct knockout winner error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to edit match")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+
+	"eafc-draft-server/internal/database"
+)
+
+// PlayerLeader is a drafted player's goal and assist tally
+type PlayerLeader struct {
+	PlayerID   int    `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	TeamName   string `json:"teamName"`
+	Goals      int    `json:"goals"`
+	Assists    int    `json:"assists"`
+}
+
+// TeamLeader is the goal and assist tally of a participant's scorers
+type TeamLeader struct {
+	TeamName string `json:"teamName"`
+	Goals    int    `json:"goals"`
+	Assists  int    `json:"assists"`
+}
+
+// TournamentLeaders are the golden boot standings, best first
+type TournamentLeaders struct {
+	Players      []PlayerLeader `json:"players"`
+	Participants []TeamLeader   `json:"participants"`
+}
+
+// tournamentLeaders tallies goals and assists per drafted player and per
+// participant. Ties on goals go to the one with more assists.
+func tournamentLeaders(goals []database.MatchGoal) TournamentLeaders {
+	players := make(map[int]*PlayerLeader)
+	teams := make(map[string]*TeamLeader)
+
+	player := func(id int, name, team string) *PlayerLeader {
+		if players[id] == nil {
+			players[id] = &PlayerLeader{PlayerID: id, PlayerName: name, TeamName: team}
+		}
+		return players[id]
+	}
+
+	for _, goal := range goals {
+		player(goal.PlayerID, goal.PlayerName, goal.TeamName).Goals++
+
+		if teams[goal.TeamName] == nil {
+			teams[goal.TeamName] = &TeamLeader{TeamName: goal.TeamName}
+		}
+		teams[goal.TeamName].Goals++
+
+		if goal.AssistPlayerID != nil {
+			name := ""
+			if goal.AssistName != nil {
+				name = *goal.AssistName
+			}
+			player(*goal.AssistPlayerID, name, goal.TeamName).Assists++
+			teams[goal.TeamName].Assists++
+		}
+	}
+
+	leaders := TournamentLeaders{
+		Players:      make([]PlayerLeader, 0, len(players)),
+		Participants: make([]TeamLeader, 0, len(teams)),
+	}
+	for _, p := range players {
+		leaders.Players = append(leaders.Players, *p)
+	}
+	for _, t := range teams {
+		leaders.Participants = append(leaders.Participants, *t)
+	}
+
+	sort.Slice(leaders.Players, func(i, j int) bool {
+		a, b := leaders.Players[i], leaders.Players[j]
+		if a.Goals != b.Goals {
+			return a.Goals > b.Goals
+		}
+		if a.Assists != b.Assists {
+			return a.Assists > b.Assists
+		}
+		return a.PlayerName < b.PlayerName
+	})
+	sort.Slice(leaders.Participants, func(i, j int) bool {
+		a, b := leaders.Participants[i], leaders.Participants[j]
+		if a.Goals != b.Goals {
+			return a.Goals > b.Goals
+		}
+		if a.Assists != b.Assists {
+			return a.Assists > b.Assists
+		}
+		return a.TeamName < b.TeamName
+	})
+
+	return leaders
+}
+
+// getTournamentLeaders returns the top scorer and assist leaderboards
+func (h *Handler) getTournamentLeaders(w http.ResponseWriter, r *http.Request, code string) {
+	var draftID int
+	err := h.db.Get(&draftID, "SELECT id FROM drafts WHERE code = $1", code)
+	if err != nil {
+		log.Printf("Get draft for leaders error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	goals, err := loadMatchGoals(h.db, draftID, 0)
+	if err != nil {
+		log.Printf("Get match goals for leaders error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tournamentLeaders(goals))
+}
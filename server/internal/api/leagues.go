@@ -0,0 +1,458 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"eafc-draft-server/internal/database"
+)
+
+const maxLeagueNameLength = 100
+
+type CreateLeagueRequest struct {
+	Name string `json:"name"`
+}
+
+type SetDraftLeagueRequest struct {
+	AdminName  string `json:"adminName"`
+	LeagueCode string `json:"leagueCode"` // empty takes the draft out of its league
+}
+
+type SetDraftLeagueResponse struct {
+	Draft database.Draft `json:"draft"`
+}
+
+// LeagueSeason is one draft night of a league
+type LeagueSeason struct {
+	Season    int        `json:"season"`
+	DraftCode string     `json:"draftCode"`
+	DraftName string     `json:"draftName"`
+	Status    string     `json:"status"`
+	Format    string     `json:"format"`
+	CreatedAt *time.Time `json:"createdAt"`
+	Champion  *string    `json:"champion"` // knockout winner or table leader, nil before any results
+}
+
+type GetLeagueResponse struct {
+	League  database.League `json:"league"`
+	Seasons []LeagueSeason  `json:"seasons"`
+}
+
+// AllTimeStanding sums a participant's season tables. Points are counted
+// under each season's own rules.
+type AllTimeStanding struct {
+	TeamName       string `json:"teamName"`
+	Seasons        int    `json:"seasons"`
+	Titles         int    `json:"titles"`
+	GamesPlayed    int    `json:"gamesPlayed"`
+	Wins           int    `json:"wins"`
+	Draws          int    `json:"draws"`
+	Losses         int    `json:"losses"`
+	Points         int    `json:"points"`
+	GoalsFor       int    `json:"goalsFor"`
+	GoalsAgainst   int    `json:"goalsAgainst"`
+	GoalDifference int    `json:"goalDifference"`
+}
+
+type GetLeagueTableResponse struct {
+	League    database.League   `json:"league"`
+	Standings []AllTimeStanding `json:"standings"`
+}
+
+// HeadToHeadRecord is every meeting between two participants, from TeamA's
+// side, with TeamA the first of the two by name
+type HeadToHeadRecord struct {
+	TeamA  string `json:"teamA"`
+	TeamB  string `json:"teamB"`
+	Played int    `json:"played"`
+	WinsA  int    `json:"winsA"`
+	Draws  int    `json:"draws"`
+	WinsB  int    `json:"winsB"`
+	GoalsA int    `json:"goalsA"`
+	GoalsB int    `json:"goalsB"`
+}
+
+type GetHeadToHeadResponse struct {
+	League  database.League    `json:"league"`
+	Records []HeadToHeadRecord `json:"records"`
+}
+
+// leagueSeasonData is what the league views need from each season
+type leagueSeasonData struct {
+	Draft        database.Draft
+	Participants []database.DraftParticipant
+	Matches      []database.Match
+	Bracket      []database.KnockoutTie
+}
+
+// handleLeagues serves POST /api/leagues and, for a league code,
+// GET /api/leagues/{code}, /table and /head-to-head
+func (h *Handler) handleLeagues(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/leagues"), "/")
+	if path == "" {
+		switch r.Method {
+		case http.MethodPost:
+			h.createLeague(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	var league database.League
+	err := h.db.Get(&league, "SELECT id, code, name, created_at FROM leagues WHERE code = $1", parts[0])
+	if err != nil {
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	}
+
+	if len(parts) > 2 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	seasons, err := h.loadLeagueSeasons(league.ID)
+	if err != nil {
+		log.Printf("Get league seasons error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var response interface{}
+	switch {
+	case len(parts) == 1:
+		response = GetLeagueResponse{League: league, Seasons: h.leagueSeasons(seasons)}
+	case parts[1] == "table":
+		response = GetLeagueTableResponse{League: league, Standings: h.allTimeTable(seasons)}
+	case parts[1] == "head-to-head":
+		records := headToHeadRecords(seasons)
+		if a, b := r.URL.Query().Get("a"), r.URL.Query().Get("b"); a != "" || b != "" {
+			records = filterHeadToHead(records, a, b)
+		}
+		response = GetHeadToHeadResponse{League: league, Records: records}
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// createLeague starts a league that drafts can then be added to
+func (h *Handler) createLeague(w http.ResponseWriter, r *http.Request) {
+	var req CreateLeagueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Create league decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxLeagueNameLength {
+		http.Error(w, fmt.Sprintf("Name is required and can be at most %d characters", maxLeagueNameLength), http.StatusBadRequest)
+		return
+	}
+
+	code, err := h.generateUniqueLeagueCode()
+	if err != nil {
+		log.Printf("Generate league code error: %v", err)
+		http.Error(w, "Failed to create league", http.StatusInternalServerError)
+		return
+	}
+
+	var league database.League
+	err = h.db.Get(&league, `
+		INSERT INTO leagues (code, name) VALUES ($1, $2)
+		RETURNING id, code, name, created_at
+	`, code, req.Name)
+	if err != nil {
+		log.Printf("Create league error: %v", err)
+		http.Error(w, "Failed to create league", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Created league %s (%s)", league.Code, league.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(league)
+}
+
+// generateUniqueLeagueCode generates codes until it finds one no league uses
+func (h *Handler) generateUniqueLeagueCode() (string, error) {
+	for attempts := 0; attempts < 10; attempts++ {
+		code, err := h.generateDraftCode()
+		if err != nil {
+			return "", err
+		}
+
+		var exists bool
+		err = h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM leagues WHERE code = $1)", code)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("no unique league code after 10 attempts")
+}
+
+// setDraftLeague adds a draft to a league as its next season, or takes it out
+func (h *Handler) setDraftLeague(w http.ResponseWriter, r *http.Request, code string) {
+	var req SetDraftLeagueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Set draft league decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for league error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can change the draft's league", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(h.db, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.IsMock {
+		http.Error(w, "Mock drafts can't be part of a league", http.StatusBadRequest)
+		return
+	}
+
+	var leagueID *int
+	if req.LeagueCode != "" {
+		var id int
+		if err := h.db.Get(&id, "SELECT id FROM leagues WHERE code = $1", req.LeagueCode); err != nil {
+			http.Error(w, "League not found", http.StatusNotFound)
+			return
+		}
+		leagueID = &id
+	}
+
+	if _, err := h.db.Exec("UPDATE drafts SET league_id = $1 WHERE id = $2", leagueID, draft.ID); err != nil {
+		log.Printf("Set draft league error: %v", err)
+		http.Error(w, "Failed to set league", http.StatusInternalServerError)
+		return
+	}
+	draft.LeagueID = leagueID
+
+	response := SetDraftLeagueResponse{
+		Draft: draft,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// loadLeagueSeasons loads a league's drafts, oldest first, with their
+// confirmed matches and brackets
+func (h *Handler) loadLeagueSeasons(leagueID int) ([]leagueSeasonData, error) {
+	var drafts []database.Draft
+	err := h.db.Select(&drafts, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE league_id = $1 ORDER BY created_at, id
+	`, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	seasons := make([]leagueSeasonData, 0, len(drafts))
+	for _, draft := range drafts {
+		season := leagueSeasonData{Draft: draft}
+		err := h.db.Select(&season.Participants, `
+			SELECT `+database.ParticipantColumns+`
+			FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+		`, draft.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = h.db.Select(&season.Matches, `
+			SELECT `+database.MatchColumns+`
+			FROM matches WHERE draft_id = $1 AND status = 'confirmed' ORDER BY played_at, id
+		`, draft.ID)
+		if err != nil {
+			return nil, err
+		}
+		if season.Bracket, err = loadBracket(h.db, draft.ID); err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+	return seasons, nil
+}
+
+// seasonChampion is the winner of the final for cup formats, or whoever tops
+// the table once results are in
+func (h *Handler) seasonChampion(season leagueSeasonData) *string {
+	if len(season.Bracket) > 0 {
+		final := season.Bracket[len(season.Bracket)-1]
+		return final.WinnerName
+	}
+	if len(season.Matches) == 0 {
+		return nil
+	}
+	standings := calculateStandings(season.Participants, season.Matches, season.Draft.TournamentSettings)
+	return &standings[0].TeamName
+}
+
+// leagueSeasons numbers a league's drafts as seasons, oldest first
+func (h *Handler) leagueSeasons(seasons []leagueSeasonData) []LeagueSeason {
+	result := make([]LeagueSeason, 0, len(seasons))
+	for i, season := range seasons {
+		result = append(result, LeagueSeason{
+			Season:    i + 1,
+			DraftCode: season.Draft.Code,
+			DraftName: season.Draft.Name,
+			Status:    season.Draft.Status,
+			Format:    season.Draft.TournamentFormat,
+			CreatedAt: season.Draft.CreatedAt,
+			Champion:  h.seasonChampion(season),
+		})
+	}
+	return result
+}
+
+// allTimeTable adds up every season's table per participant name, matching
+// names the way ratings do
+func (h *Handler) allTimeTable(seasons []leagueSeasonData) []AllTimeStanding {
+	totals := make(map[string]*AllTimeStanding)
+	total := func(name string) *AllTimeStanding {
+		key := ratingKey(name)
+		if totals[key] == nil {
+			totals[key] = &AllTimeStanding{}
+		}
+		totals[key].TeamName = name // the latest spelling wins
+		return totals[key]
+	}
+
+	for _, season := range seasons {
+		league := leagueMatches(season.Matches, season.Bracket)
+		for _, standing := range calculateStandings(season.Participants, league, season.Draft.TournamentSettings) {
+			t := total(standing.TeamName)
+			t.Seasons++
+			t.GamesPlayed += standing.GamesPlayed
+			t.Wins += standing.Wins
+			t.Draws += standing.Draws
+			t.Losses += standing.Losses
+			t.Points += standing.Points
+			t.GoalsFor += standing.GoalsFor
+			t.GoalsAgainst += standing.GoalsAgainst
+			t.GoalDifference += standing.GoalDifference
+		}
+		if champion := h.seasonChampion(season); champion != nil {
+			total(*champion).Titles++
+		}
+	}
+
+	table := make([]AllTimeStanding, 0, len(totals))
+	for _, t := range totals {
+		table = append(table, *t)
+	}
+	sort.Slice(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.GoalDifference != b.GoalDifference {
+			return a.GoalDifference > b.GoalDifference
+		}
+		if a.GoalsFor != b.GoalsFor {
+			return a.GoalsFor > b.GoalsFor
+		}
+		return a.TeamName < b.TeamName
+	})
+	return table
+}
+
+// headToHeadRecords collects every pairing that has met in the league. The
+// 90 minute score counts, as in the tables.
+func headToHeadRecords(seasons []leagueSeasonData) []HeadToHeadRecord {
+	records := make(map[[2]string]*HeadToHeadRecord)
+	for _, season := range seasons {
+		for _, match := range season.Matches {
+			nameA, nameB := match.HomeTeamName, match.AwayTeamName
+			goalsA, goalsB := match.HomeScore, match.AwayScore
+			if ratingKey(nameB) < ratingKey(nameA) {
+				nameA, nameB, goalsA, goalsB = nameB, nameA, goalsB, goalsA
+			}
+
+			key := [2]string{ratingKey(nameA), ratingKey(nameB)}
+			if records[key] == nil {
+				records[key] = &HeadToHeadRecord{}
+			}
+			record := records[key]
+			record.TeamA, record.TeamB = nameA, nameB
+			record.Played++
+			record.GoalsA += goalsA
+			record.GoalsB += goalsB
+			switch {
+			case goalsA > goalsB:
+				record.WinsA++
+			case goalsB > goalsA:
+				record.WinsB++
+			default:
+				record.Draws++
+			}
+		}
+	}
+
+	result := make([]HeadToHeadRecord, 0, len(records))
+	for _, record := range records {
+		result = append(result, *record)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Played != result[j].Played {
+			return result[i].Played > result[j].Played
+		}
+		return ratingKey(result[i].TeamA+"\x00"+result[i].TeamB) < ratingKey(result[j].TeamA+"\x00"+result[j].TeamB)
+	})
+	return result
+}
+
+// filterHeadToHead keeps the records involving a, or the one between a and b
+func filterHeadToHead(records []HeadToHeadRecord, a, b string) []HeadToHeadRecord {
+	involves := func(record HeadToHeadRecord, name string) bool {
+		return name == "" || ratingKey(record.TeamA) == ratingKey(name) || ratingKey(record.TeamB) == ratingKey(name)
+	}
+
+	filtered := []HeadToHeadRecord{}
+	for _, record := range records {
+		if involves(record, a) && involves(record, b) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered
+}
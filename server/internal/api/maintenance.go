@@ -0,0 +1,117 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// maintenanceState is the operator-controlled maintenance flag. While enabled,
+// new drafts and picks are rejected but read endpoints keep working.
+type maintenanceState struct {
+	mutex   sync.RWMutex
+	enabled bool
+	message string
+}
+
+func (m *maintenanceState) get() (bool, string) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.enabled, m.message
+}
+
+func (m *maintenanceState) set(enabled bool, message string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.enabled = enabled
+	m.message = message
+}
+
+type MaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+}
+
+type MaintenanceResponse struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+}
+
+// maintenanceError returns an error for write operations while in maintenance mode
+func (h *Handler) maintenanceError() error {
+	if enabled, message := h.maintenance.get(); enabled {
+		return newStatusError(http.StatusServiceUnavailable, "maintenance: "+message)
+	}
+	return nil
+}
+
+// isOperator reports whether the request carries the configured admin token
+func (h *Handler) isOperator(r *http.Request) bool {
+	token := r.Header.Get("X-Admin-Token")
+	return h.config.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.config.AdminToken)) == 1
+}
+
+// adminMiddleware guards operator endpoints with the configured admin token
+func (h *Handler) adminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.config.AdminToken == "" {
+			http.Error(w, "Admin API is disabled", http.StatusForbidden)
+			return
+		}
+
+		if !h.isOperator(r) {
+			http.Error(w, "Invalid admin token", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (h *Handler) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s /api/admin/maintenance", r.Method)
+
+	switch r.Method {
+	case http.MethodGet:
+		enabled, message := h.maintenance.get()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MaintenanceResponse{Enabled: enabled, Message: message})
+	case http.MethodPut:
+		var req MaintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Printf("Maintenance decode error: %v", err)
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		message := req.Message
+		if message == "" {
+			message = h.config.MaintenanceMessage
+		}
+		h.maintenance.set(req.Enabled, message)
+
+		log.Printf("Maintenance mode set to %t: %s", req.Enabled, message)
+
+		// Show or clear the banner in every room
+		roomManager.BroadcastToAll(maintenanceMessage(req.Enabled, message))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MaintenanceResponse{Enabled: req.Enabled, Message: message})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// maintenanceMessage builds the banner event sent to WebSocket clients
+func maintenanceMessage(enabled bool, message string) []byte {
+	data, err := json.Marshal(WSMessage{
+		Type: "maintenance",
+		Data: map[string]interface{}{"enabled": enabled, "message": message},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal maintenance message: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Match statuses. Only confirmed matches count towards the standings.
+const (
+	MatchConfirmed = "confirmed"
+	MatchPending   = "pending"
+	MatchDisputed  = "disputed"
+	MatchRejected  = "rejected"
+)
+
+type ConfirmMatchRequest struct {
+	Name string `json:"name"` // the opponent of the team that submitted it, or the admin
+}
+
+// checkPendingResult rejects a submitted result that could never be
+// confirmed, so teams hear about it straight away rather than when their
+// opponent tries. Errors are statusErrors.
+func checkPendingResult(tx *sqlx.Tx, draft database.Draft, match database.Match) error {
+	if draft.Status != "tournament" {
+		return nil
+	}
+	knockout, err := inKnockoutPhase(tx, draft)
+	if err != nil {
+		log.Printf("Check knockout phase error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to record match")
+	}
+	if _, ok := matchWinner(match); knockout && !ok {
+		return newStatusError(http.StatusBadRequest, "A knockout tie needs a winner, add extra time or penalties")
+	}
+	return nil
+}
+
+// loadPendingMatches returns the results waiting for confirmation or for
+// the admin to settle a dispute, oldest first
+func loadPendingMatches(q sqlx.Queryer, draftID int) ([]database.Match, error) {
+	matches := []database.Match{}
+	err := sqlx.Select(q, &matches, `
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE draft_id = $1 AND status IN ('pending', 'disputed') ORDER BY played_at, id
+	`, draftID)
+	return matches, err
+}
+
+// lockMatch locks a draft and one of its matches for a status change. Errors
+// are statusErrors.
+func lockMatch(tx *sqlx.Tx, code, matchParam string) (database.Draft, database.Match, error) {
+	var draft database.Draft
+	var match database.Match
+
+	matchID, err := strconv.Atoi(matchParam)
+	if err != nil {
+		return draft, match, newStatusError(http.StatusBadRequest, "Invalid match ID")
+	}
+
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for match error: %v", err)
+		return draft, match, newStatusError(http.StatusNotFound, "Draft not found")
+	}
+
+	err = tx.Get(&match, `
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE id = $1 AND draft_id = $2 FOR UPDATE
+	`, matchID, draft.ID)
+	if err != nil {
+		return draft, match, newStatusError(http.StatusNotFound, "Match not found")
+	}
+	return draft, match, nil
+}
+
+// matchOpponent is the team that didn't submit a result
+func matchOpponent(match database.Match) string {
+	if match.RecordedBy == match.HomeTeamName {
+		return match.AwayTeamName
+	}
+	return match.HomeTeamName
+}
+
+// confirmMatch accepts a pending result. The opponent of the team that
+// submitted it confirms it, or the admin overrides and confirms it for them.
+func (h *Handler) confirmMatch(w http.ResponseWriter, r *http.Request, code, matchParam string) {
+	var req ConfirmMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Confirm match decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	draft, match, err := lockMatch(tx, code, matchParam)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.Name, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if match.Status != MatchPending {
+		http.Error(w, "Match is not waiting for confirmation", http.StatusConflict)
+		return
+	}
+
+	if req.Name != matchOpponent(match) && req.Name != draft.AdminName {
+		http.Error(w, "Only the opponent or the admin can confirm this result", http.StatusForbidden)
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matches SET status = 'confirmed', confirmed_by = $1 WHERE id = $2
+	`, req.Name, match.ID)
+	if err != nil {
+		log.Printf("Confirm match error: %v", err)
+		http.Error(w, "Failed to confirm match", http.StatusInternalServerError)
+		return
+	}
+	match.Status, match.ConfirmedBy = MatchConfirmed, &req.Name
+
+	if err := h.settleMatch(tx, draft, match, req.Name); err != nil {
+		var se *statusError
+		if errors.As(err, &se) {
+			writeStatusError(w, err)
+			return
+		}
+		log.Printf("Settle confirmed match error: %v", err)
+		http.Error(w, "Failed to confirm match", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to confirm match", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Match %d confirmed by %s", match.ID, req.Name)
+
+	if h.broadcastFunc != nil {
+		BroadcastTournamentStateToRoom(h.db, code)
+	}
+
+	response := RecordMatchResponse{
+		Match: match,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+)
+
+// Admin decisions on a pending or disputed result
+const (
+	ResolveConfirm = "confirm"
+	ResolveReject  = "reject"
+)
+
+const maxDisputeReasonLength = 500
+
+type DisputeMatchRequest struct {
+	Name   string `json:"name"` // the opponent of the team that submitted the result
+	Reason string `json:"reason"`
+}
+
+type ResolveMatchRequest struct {
+	AdminName string `json:"adminName"`
+	Decision  string `json:"decision"` // confirm or reject
+	// Optional corrected score when confirming, replacing any extra time,
+	// penalties and cards submitted with it
+	HomeScore *int `json:"homeScore"`
+	AwayScore *int `json:"awayScore"`
+	database.ExtraTimeResult
+	database.MatchCards
+}
+
+type GetPendingMatchesResponse struct {
+	Matches []database.Match `json:"matches"`
+}
+
+// matchResolvedEvent is the payload of a match_resolved event and of the
+// matchResolved message sent to the room
+type matchResolvedEvent struct {
+	Match      database.Match `json:"match"`
+	Decision   string         `json:"decision"`
+	ResolvedBy string         `json:"resolvedBy"`
+}
+
+// getPendingMatches is the admin's queue: results waiting for the opponent
+// and results the opponent has disputed
+func (h *Handler) getPendingMatches(w http.ResponseWriter, r *http.Request, code string) {
+	var draftID int
+	err := h.db.Get(&draftID, "SELECT id FROM drafts WHERE code = $1", code)
+	if err != nil {
+		log.Printf("Get draft for pending matches error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	matches, err := loadPendingMatches(h.db, draftID)
+	if err != nil {
+		log.Printf("Get pending matches error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := GetPendingMatchesResponse{
+		Matches: matches,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// disputeMatch lets the opponent object to a pending result. It then waits
+// for the admin to confirm or reject it.
+func (h *Handler) disputeMatch(w http.ResponseWriter, r *http.Request, code, matchParam string) {
+	var req DisputeMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Dispute match decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Name == "" || req.Reason == "" {
+		http.Error(w, "Name and reason are required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Reason) > maxDisputeReasonLength {
+		http.Error(w, "Reason is too long", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	draft, match, err := lockMatch(tx, code, matchParam)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.Name, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if match.Status != MatchPending {
+		http.Error(w, "Only pending results can be disputed", http.StatusConflict)
+		return
+	}
+
+	if req.Name != matchOpponent(match) {
+		http.Error(w, "Only the opponent can dispute this result", http.StatusForbidden)
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matches SET status = 'disputed', dispute_reason = $1 WHERE id = $2
+	`, req.Reason, match.ID)
+	if err != nil {
+		log.Printf("Dispute match error: %v", err)
+		http.Error(w, "Failed to dispute match", http.StatusInternalServerError)
+		return
+	}
+	match.Status, match.DisputeReason = MatchDisputed, &req.Reason
+
+	if err := recordDraftEvent(tx, draft.ID, EventMatchDisputed, req.Name, match); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to dispute match", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to dispute match", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Match %d disputed by %s", match.ID, req.Name)
+
+	if h.broadcastFunc != nil {
+		BroadcastTournamentStateToRoom(h.db, code)
+	}
+
+	response := RecordMatchResponse{
+		Match: match,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// resolveMatch is the admin's decision on a pending or disputed result:
+// confirm it, optionally with a corrected score, or reject it. The decision
+// is announced to the room.
+func (h *Handler) resolveMatch(w http.ResponseWriter, r *http.Request, code, matchParam string) {
+	var req ResolveMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Resolve match decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Decision != ResolveConfirm && req.Decision != ResolveReject {
+		http.Error(w, "decision must be confirm or reject", http.StatusBadRequest)
+		return
+	}
+
+	if (req.HomeScore == nil) != (req.AwayScore == nil) {
+		http.Error(w, "homeScore and awayScore must be given together", http.StatusBadRequest)
+		return
+	}
+	if req.HomeScore != nil {
+		if req.Decision != ResolveConfirm {
+			http.Error(w, "A corrected score can only be given when confirming", http.StatusBadRequest)
+			return
+		}
+		if err := validateResult(*req.HomeScore, *req.AwayScore, req.ExtraTimeResult); err != nil {
+			writeStatusError(w, err)
+			return
+		}
+		if err := validateCards(req.MatchCards); err != nil {
+			writeStatusError(w, err)
+			return
+		}
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	draft, match, err := lockMatch(tx, code, matchParam)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can resolve results", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if match.Status != MatchPending && match.Status != MatchDisputed {
+		http.Error(w, "Match is not waiting for a decision", http.StatusConflict)
+		return
+	}
+
+	if req.Decision == ResolveReject {
+		_, err = tx.Exec("UPDATE matches SET status = 'rejected' WHERE id = $1", match.ID)
+		match.Status = MatchRejected
+	} else {
+		if req.HomeScore != nil {
+			match.HomeScore, match.AwayScore = *req.HomeScore, *req.AwayScore
+			match.ExtraTimeResult = req.ExtraTimeResult
+			match.MatchCards = req.MatchCards
+		}
+		_, err = tx.Exec(`
+			UPDATE matches
+			SET status = 'confirmed', confirmed_by = $1, home_score = $2, away_score = $3,
+			    home_score_aet = $4, away_score_aet = $5, home_penalties = $6, away_penalties = $7,
+			    home_yellow_cards = $8, away_yellow_cards = $9, home_red_cards = $10, away_red_cards = $11
+			WHERE id = $12
+		`, req.AdminName, match.HomeScore, match.AwayScore, match.HomeScoreAET, match.AwayScoreAET,
+			match.HomePenalties, match.AwayPenalties, match.HomeYellowCards, match.AwayYellowCards,
+			match.HomeRedCards, match.AwayRedCards, match.ID)
+		match.Status, match.ConfirmedBy = MatchConfirmed, &req.AdminName
+	}
+	if err != nil {
+		log.Printf("Resolve match error: %v", err)
+		http.Error(w, "Failed to resolve match", http.StatusInternalServerError)
+		return
+	}
+
+	if match.Status == MatchConfirmed {
+		if err := h.settleMatch(tx, draft, match, req.AdminName); err != nil {
+			var se *statusError
+			if errors.As(err, &se) {
+				writeStatusError(w, err)
+				return
+			}
+			log.Printf("Settle resolved match error: %v", err)
+			http.Error(w, "Failed to resolve match", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	event := matchResolvedEvent{
+		Match:      match,
+		Decision:   req.Decision,
+		ResolvedBy: req.AdminName,
+	}
+	if err := recordDraftEvent(tx, draft.ID, EventMatchResolved, req.AdminName, event); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to resolve match", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to resolve match", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Match %d resolved by %s: %s", match.ID, req.AdminName, req.Decision)
+
+	if h.broadcastFunc != nil {
+		broadcastMessage(code, "matchResolved", event)
+		BroadcastTournamentStateToRoom(h.db, code)
+	}
+
+	response := RecordMatchResponse{
+		Match: match,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"eafc-draft-server/internal/database"
+)
+
+type EditMatchRequest struct {
+	AdminName string `json:"adminName"`
+	HomeScore int    `json:"homeScore"`
+	AwayScore int    `json:"awayScore"`
+	Reason    string `json:"reason"` // optional, kept in the audit trail
+
+	database.ExtraTimeResult
+	database.MatchCards
+}
+
+type EditMatchResponse struct {
+	Match database.Match `json:"match"`
+}
+
+// matchEditedEvent is the payload of a match_edited event, the audit trail of
+// score corrections
+type matchEditedEvent struct {
+	MatchID      int     `json:"matchId"`
+	HomeTeamName string  `json:"homeTeamName"`
+	AwayTeamName string  `json:"awayTeamName"`
+	OldHomeScore int     `json:"oldHomeScore"`
+	OldAwayScore int     `json:"oldAwayScore"`
+	HomeScore    int     `json:"homeScore"`
+	AwayScore    int     `json:"awayScore"`
+	Reason       *string `json:"reason,omitempty"`
+
+	OldExtraTime database.ExtraTimeResult `json:"oldExtraTime"`
+	ExtraTime    database.ExtraTimeResult `json:"extraTime"`
+	OldCards     database.MatchCards      `json:"oldCards"`
+	Cards        database.MatchCards      `json:"cards"`
+}
+
+// editMatch lets the admin correct a mis-entered scoreline. Standings are
+// computed from the matches, so they follow on their own; a knockout tie the
+// match decided is re-settled. League and group results are frozen once the
+// knockouts have been drawn from them.
+func (h *Handler) editMatch(w http.ResponseWriter, r *http.Request, code, matchParam string) {
+	matchID, err := strconv.Atoi(matchParam)
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	var req EditMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Edit match decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateResult(req.HomeScore, req.AwayScore, req.ExtraTimeResult); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+	if err := validateCards(req.MatchCards); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for edit match error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can edit matches", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	var match database.Match
+	err = tx.Get(&match, `
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE id = $1 AND draft_id = $2 FOR UPDATE
+	`, matchID, draft.ID)
+	if err != nil {
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+
+	event := matchEditedEvent{
+		MatchID:      match.ID,
+		HomeTeamName: match.HomeTeamName,
+		AwayTeamName: match.AwayTeamName,
+		OldHomeScore: match.HomeScore,
+		OldAwayScore: match.AwayScore,
+		HomeScore:    req.HomeScore,
+		AwayScore:    req.AwayScore,
+		OldExtraTime: match.ExtraTimeResult,
+		ExtraTime:    req.ExtraTimeResult,
+		OldCards:     match.MatchCards,
+		Cards:        req.MatchCards,
+	}
+	if req.Reason != "" {
+		event.Reason = &req.Reason
+	}
+	match.HomeScore, match.AwayScore = req.HomeScore, req.AwayScore
+	match.ExtraTimeResult = req.ExtraTimeResult
+	match.MatchCards = req.MatchCards
+
+	if draft.Status == "tournament" && draft.TournamentFormat != TournamentFormatKnockout {
+		var fixtureMatch bool
+		err = tx.Get(&fixtureMatch, "SELECT EXISTS(SELECT 1 FROM fixtures WHERE match_id = $1)", match.ID)
+		if err != nil {
+			log.Printf("Check fixture error: %v", err)
+			http.Error(w, "Failed to edit match", http.StatusInternalServerError)
+			return
+		}
+		knockout, err := inKnockoutPhase(tx, draft)
+		if err != nil {
+			log.Printf("Check knockout phase error: %v", err)
+			http.Error(w, "Failed to edit match", http.StatusInternalServerError)
+			return
+		}
+		if fixtureMatch && knockout {
+			http.Error(w, "League and group results can't be changed once the knockouts have started", http.StatusConflict)
+			return
+		}
+	}
+
+	var goalCounts []struct {
+		ParticipantID int `db:"participant_id"`
+		Goals         int `db:"goals"`
+	}
+	err = tx.Select(&goalCounts, `
+		SELECT participant_id, COUNT(*) AS goals FROM match_goals WHERE match_id = $1 GROUP BY participant_id
+	`, match.ID)
+	if err != nil {
+		log.Printf("Count match goals error: %v", err)
+		http.Error(w, "Failed to edit match", http.StatusInternalServerError)
+		return
+	}
+	goalsByTeam := make(map[int]int, len(goalCounts))
+	for _, count := range goalCounts {
+		goalsByTeam[count.ParticipantID] = count.Goals
+	}
+	if err := checkGoalCount(match, goalsByTeam); err != nil {
+		http.Error(w, "Remove scorers before lowering the score", http.StatusConflict)
+		return
+	}
+
+	if err := correctKnockoutResult(tx, match); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matches
+		SET home_score = $1, away_score = $2, home_score_aet = $3, away_score_aet = $4,
+		    home_penalties = $5, away_penalties = $6, home_yellow_cards = $7, away_yellow_cards = $8,
+		    home_red_cards = $9, away_red_cards = $10
+		WHERE id = $11
+	`, match.HomeScore, match.AwayScore, match.HomeScoreAET, match.AwayScoreAET,
+		match.HomePenalties, match.AwayPenalties, match.HomeYellowCards, match.AwayYellowCards,
+		match.HomeRedCards, match.AwayRedCards, match.ID)
+	if err != nil {
+		log.Printf("Update match error: %v", err)
+		http.Error(w, "Failed to edit match", http.StatusInternalServerError)
+		return
+	}
+
+	// Ratings follow the corrected result
+	if match.Status == MatchConfirmed {
+		err = revertRatings(tx, match.ID)
+		if err == nil {
+			err = applyRatings(tx, match)
+		}
+		if err != nil {
+			log.Printf("Update ratings for edited match error: %v", err)
+			http.Error(w, "Failed to edit match", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventMatchEdited, req.AdminName, event); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to edit match", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to edit match", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Match %d edited: %s %d - %d %s (was %d - %d) by %s", match.ID, match.HomeTeamName,
+		match.HomeScore, match.AwayScore, match.AwayTeamName, event.OldHomeScore, event.OldAwayScore, req.AdminName)
+
+	if h.broadcastFunc != nil {
+		BroadcastTournamentStateToRoom(h.db, code)
+	}
+
+	response := EditMatchResponse{
+		Match: match,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
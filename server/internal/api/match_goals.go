@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// maxGoalMinute allows for extra time and stoppage time
+const maxGoalMinute = 130
+
+// matchGoalColumns selects a database.MatchGoal from match_goals g with the
+// team joined from draft_participants t and the player names from players p
+// and assister a
+const matchGoalColumns = `g.id, g.match_id, g.participant_id, t.name AS team_name, g.player_id,
+	COALESCE(NULLIF(p.common_name, ''), CONCAT_WS(' ', NULLIF(p.first_name, ''), NULLIF(p.last_name, ''))) AS player_name,
+	g.minute, g.assist_player_id,
+	COALESCE(NULLIF(a.common_name, ''), CONCAT_WS(' ', NULLIF(a.first_name, ''), NULLIF(a.last_name, ''))) AS assist_name`
+
+type MatchGoalRequest struct {
+	PlayerID       int  `json:"playerId"`
+	Minute         int  `json:"minute"`
+	AssistPlayerID *int `json:"assistPlayerId"`
+}
+
+type SetMatchGoalsRequest struct {
+	Name  string             `json:"name"` // the admin or one of the teams
+	Goals []MatchGoalRequest `json:"goals"`
+}
+
+type MatchGoalsResponse struct {
+	Goals []database.MatchGoal `json:"goals"`
+}
+
+// loadMatchGoals returns the scorers of a draft's confirmed matches, or of a
+// single match when matchID isn't zero, in match and minute order
+func loadMatchGoals(q sqlx.Queryer, draftID, matchID int) ([]database.MatchGoal, error) {
+	goals := []database.MatchGoal{}
+	err := sqlx.Select(q, &goals, `
+		SELECT `+matchGoalColumns+`
+		FROM match_goals g
+		JOIN matches m ON m.id = g.match_id
+		JOIN drafts d ON d.id = m.draft_id
+		JOIN draft_participants t ON t.id = g.participant_id
+		LEFT JOIN players p ON p.id = g.player_id AND p.dataset = d.dataset
+		LEFT JOIN players a ON a.id = g.assist_player_id AND a.dataset = d.dataset
+		WHERE m.draft_id = $1 AND (($2 = 0 AND m.status = 'confirmed') OR m.id = $2)
+		ORDER BY m.played_at, m.id, g.minute, g.id
+	`, draftID, matchID)
+	return goals, err
+}
+
+// checkGoalCount makes sure neither team has more scorers than goals, going
+// by the score after extra time when there was any
+func checkGoalCount(match database.Match, goalsByTeam map[int]int) error {
+	home, away := match.HomeScore, match.AwayScore
+	if match.HomeScoreAET != nil {
+		home, away = *match.HomeScoreAET, *match.AwayScoreAET
+	}
+	if goalsByTeam[match.HomeTeamID] > home || goalsByTeam[match.AwayTeamID] > away {
+		return newStatusError(http.StatusBadRequest, "A team can't have more scorers than goals")
+	}
+	return nil
+}
+
+// saveMatchGoals replaces a match's scorers. Every scorer and assister must
+// be in the squad of one of the two teams, and a goal counts for the team
+// that drafted its scorer. Errors are statusErrors.
+func saveMatchGoals(tx *sqlx.Tx, match database.Match, goals []MatchGoalRequest) error {
+	if match.Walkover && len(goals) > 0 {
+		return newStatusError(http.StatusBadRequest, "A walkover has no scorers")
+	}
+
+	var picks []struct {
+		PlayerID      int `db:"player_id"`
+		ParticipantID int `db:"participant_id"`
+	}
+	err := tx.Select(&picks, `
+		SELECT player_id, participant_id FROM draft_picks
+		WHERE draft_id = $1 AND participant_id IN ($2, $3)
+	`, match.DraftID, match.HomeTeamID, match.AwayTeamID)
+	if err != nil {
+		log.Printf("Get squads for match goals error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to save scorers")
+	}
+	squad := make(map[int]int, len(picks))
+	for _, pick := range picks {
+		squad[pick.PlayerID] = pick.ParticipantID
+	}
+
+	goalsByTeam := make(map[int]int)
+	for _, goal := range goals {
+		team, ok := squad[goal.PlayerID]
+		if !ok {
+			return newStatusError(http.StatusBadRequest, fmt.Sprintf("Player %d isn't in either squad", goal.PlayerID))
+		}
+		if goal.Minute < 1 || goal.Minute > maxGoalMinute {
+			return newStatusError(http.StatusBadRequest, fmt.Sprintf("minute must be between 1 and %d", maxGoalMinute))
+		}
+		if goal.AssistPlayerID != nil {
+			if *goal.AssistPlayerID == goal.PlayerID {
+				return newStatusError(http.StatusBadRequest, "A player can't assist their own goal")
+			}
+			if squad[*goal.AssistPlayerID] != team {
+				return newStatusError(http.StatusBadRequest, fmt.Sprintf("Player %d isn't in the scorer's squad", *goal.AssistPlayerID))
+			}
+		}
+		goalsByTeam[team]++
+	}
+	if err := checkGoalCount(match, goalsByTeam); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM match_goals WHERE match_id = $1", match.ID)
+	if err != nil {
+		log.Printf("Clear match goals error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to save scorers")
+	}
+	for _, goal := range goals {
+		_, err = tx.Exec(`
+			INSERT INTO match_goals (match_id, participant_id, player_id, minute, assist_player_id)
+			VALUES ($1, $2, $3, $4, $5)
+		`, match.ID, squad[goal.PlayerID], goal.PlayerID, goal.Minute, goal.AssistPlayerID)
+		if err != nil {
+			log.Printf("Insert match goal error: %v", err)
+			return newStatusError(http.StatusInternalServerError, "Failed to save scorers")
+		}
+	}
+	return nil
+}
+
+// setMatchGoals replaces the scorers of a recorded match. The admin and the
+// two teams can fill them in after the result.
+func (h *Handler) setMatchGoals(w http.ResponseWriter, r *http.Request, code, matchParam string) {
+	var req SetMatchGoalsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Set match goals decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	draft, match, err := lockMatch(tx, code, matchParam)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.Name, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if req.Name != draft.AdminName && req.Name != match.HomeTeamName && req.Name != match.AwayTeamName {
+		http.Error(w, "Only the admin or the teams involved can add scorers", http.StatusForbidden)
+		return
+	}
+
+	if match.Status == MatchRejected {
+		http.Error(w, "Match was rejected", http.StatusConflict)
+		return
+	}
+
+	if err := saveMatchGoals(tx, match, req.Goals); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	goals, err := loadMatchGoals(tx, draft.ID, match.ID)
+	if err != nil {
+		log.Printf("Get match goals error: %v", err)
+		http.Error(w, "Failed to save scorers", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to save scorers", http.StatusInternalServerError)
+		return
+	}
+
+	if h.broadcastFunc != nil {
+		BroadcastTournamentStateToRoom(h.db, code)
+	}
+
+	response := MatchGoalsResponse{
+		Goals: goals,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
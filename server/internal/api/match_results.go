@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+)
+
+// validateResult checks that a scoreline is non-negative and that extra time
+// and penalties follow on from it: extra time only after a draw in 90
+// minutes, and a shootout only when the teams are still level.
+func validateResult(homeScore, awayScore int, extra database.ExtraTimeResult) error {
+	if homeScore < 0 || awayScore < 0 {
+		return newStatusError(http.StatusBadRequest, "Scores must be non-negative")
+	}
+
+	if (extra.HomeScoreAET == nil) != (extra.AwayScoreAET == nil) {
+		return newStatusError(http.StatusBadRequest, "homeScoreAet and awayScoreAet must be given together")
+	}
+	if (extra.HomePenalties == nil) != (extra.AwayPenalties == nil) {
+		return newStatusError(http.StatusBadRequest, "homePenalties and awayPenalties must be given together")
+	}
+
+	if extra.HomeScoreAET != nil {
+		if homeScore != awayScore {
+			return newStatusError(http.StatusBadRequest, "Extra time is only played after a draw")
+		}
+		if *extra.HomeScoreAET < homeScore || *extra.AwayScoreAET < awayScore {
+			return newStatusError(http.StatusBadRequest, "The score after extra time includes the goals in 90 minutes")
+		}
+		homeScore, awayScore = *extra.HomeScoreAET, *extra.AwayScoreAET
+	}
+
+	if extra.HomePenalties != nil {
+		if homeScore != awayScore {
+			return newStatusError(http.StatusBadRequest, "A penalty shootout is only needed when the teams are level")
+		}
+		if *extra.HomePenalties < 0 || *extra.AwayPenalties < 0 {
+			return newStatusError(http.StatusBadRequest, "Penalties must be non-negative")
+		}
+		if *extra.HomePenalties == *extra.AwayPenalties {
+			return newStatusError(http.StatusBadRequest, "A penalty shootout needs a winner")
+		}
+	}
+	return nil
+}
+
+// Fair play points per booking, fewer is better
+const (
+	fairPlayYellow = 1
+	fairPlayRed    = 3
+)
+
+// A team is abandoned below seven players, so it can't have more than five
+// sent off
+const (
+	maxYellowCards = 30
+	maxRedCards    = 5
+)
+
+// validateCards checks a match's bookings are possible
+func validateCards(cards database.MatchCards) error {
+	for _, yellow := range []int{cards.HomeYellowCards, cards.AwayYellowCards} {
+		if yellow < 0 || yellow > maxYellowCards {
+			return newStatusError(http.StatusBadRequest, fmt.Sprintf("Yellow cards must be between 0 and %d", maxYellowCards))
+		}
+	}
+	for _, red := range []int{cards.HomeRedCards, cards.AwayRedCards} {
+		if red < 0 || red > maxRedCards {
+			return newStatusError(http.StatusBadRequest, fmt.Sprintf("Red cards must be between 0 and %d", maxRedCards))
+		}
+	}
+	return nil
+}
+
+// fairPlayPoints weighs a team's bookings for the fair play tiebreaker
+func fairPlayPoints(yellowCards, redCards int) int {
+	return yellowCards*fairPlayYellow + redCards*fairPlayRed
+}
+
+// matchWinner returns the team that went through: on the score after extra
+// time if there was any, then on penalties. ok is false for a draw. League
+// standings ignore this and count the 90 minute score.
+func matchWinner(match database.Match) (winnerID int, ok bool) {
+	home, away := match.HomeScore, match.AwayScore
+	if match.HomeScoreAET != nil {
+		home, away = *match.HomeScoreAET, *match.AwayScoreAET
+	}
+	if home == away && match.HomePenalties != nil {
+		home, away = *match.HomePenalties, *match.AwayPenalties
+	}
+
+	switch {
+	case home > away:
+		return match.HomeTeamID, true
+	case away > home:
+		return match.AwayTeamID, true
+	}
+	return 0, false
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/lib/pq"
+)
+
+type CreateMockDraftRequest struct {
+	Name        string   `json:"name"`
+	AdminName   string   `json:"adminName"`
+	Bots        int      `json:"bots"`
+	Strategy    string   `json:"strategy"`
+	PoolLeagues []string `json:"poolLeagues,omitempty"`
+}
+
+type CreateMockDraftResponse struct {
+	Draft        database.Draft              `json:"draft"`
+	Participants []database.DraftParticipant `json:"participants"`
+	Token        string                      `json:"token"`
+}
+
+const (
+	defaultMockBots = 5
+	maxMockBots     = 11
+)
+
+// createMockDraft creates a practice draft for a single user against bots.
+// The draft starts straight away and bots pick instantly, so by the time the
+// response is sent it is the user's turn.
+func (h *Handler) createMockDraft(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s /api/drafts/mock", r.Method)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.maintenanceError(); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	var req CreateMockDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Create mock draft decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		req.Name = "Mock draft"
+	}
+	if req.Bots <= 0 {
+		req.Bots = defaultMockBots
+	}
+	if req.Bots > maxMockBots {
+		http.Error(w, "Too many bots for a mock draft", http.StatusBadRequest)
+		return
+	}
+	if req.Strategy == "" {
+		req.Strategy = BotStrategyPositionalNeed
+	}
+	if !isValidBotStrategy(req.Strategy) {
+		http.Error(w, "Strategy must be best_available, positional_need or random", http.StatusBadRequest)
+		return
+	}
+
+	code, err := h.generateUniqueDraftCode()
+	if err != nil {
+		log.Printf("Generate unique code error: %v", err)
+		http.Error(w, "Failed to generate draft code", http.StatusInternalServerError)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Create draft
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		INSERT INTO drafts (code, name, admin_name, participant_count, pool_leagues, is_mock, dataset) 
+		VALUES ($1, $2, $3, 1, $4, true, $5) 
+		RETURNING `+database.DraftColumns+`
+	`, code, req.Name, req.AdminName, pq.StringArray(cleanStringList(req.PoolLeagues)), h.config.PlayerDataset)
+	if err != nil {
+		log.Printf("Create mock draft error: %v", err)
+		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
+		return
+	}
+
+	token, err := generateParticipantToken()
+	if err != nil {
+		log.Printf("Generate participant token error: %v", err)
+		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
+		return
+	}
+
+	// Add the user as admin and first participant
+	_, err = tx.Exec(`
+		INSERT INTO draft_participants (draft_id, name, draft_order, is_admin, token) 
+		VALUES ($1, $2, 1, true, $3)
+	`, draft.ID, req.AdminName, token)
+	if err != nil {
+		log.Printf("Create mock admin participant error: %v", err)
+		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := h.insertBots(tx, &draft, req.Bots, req.Strategy); err != nil {
+		log.Printf("Insert mock bots error: %v", err)
+		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
+		return
+	}
+
+	var participants []database.DraftParticipant
+	err = tx.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Get mock participants error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.shuffleParticipants(participants, nil); err != nil {
+		log.Printf("Shuffle participants error: %v", err)
+		http.Error(w, "Failed to randomize draft order", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.saveDraftOrder(tx, participants); err != nil {
+		log.Printf("Save draft order error: %v", err)
+		http.Error(w, "Failed to update draft order", http.StatusInternalServerError)
+		return
+	}
+
+	// Start right away
+	now := time.Now()
+	_, err = tx.Exec("UPDATE drafts SET status = 'active', started_at = $1 WHERE id = $2", now, draft.ID)
+	if err != nil {
+		log.Printf("Start mock draft error: %v", err)
+		http.Error(w, "Failed to start draft", http.StatusInternalServerError)
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to create draft", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Created mock draft %s for %s against %d bots", code, req.AdminName, req.Bots)
+
+	// Play the bots up to the user's first turn
+	h.runBotPicks(code)
+
+	err = h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Reload mock draft error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.db.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Reload mock participants error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := CreateMockDraftResponse{
+		Draft:        draft,
+		Participants: participants,
+		Token:        token,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeMsgpack is a reference decoder written from the MessagePack spec,
+// kept apart from the encoder so the two can't share a mistake. Integers
+// decode to int64, floats to float64, maps to map[string]interface{}.
+func decodeMsgpack(data []byte) (interface{}, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("unexpected end of input")
+	}
+	code, rest := data[0], data[1:]
+
+	take := func(n int) ([]byte, error) {
+		if len(rest) < n {
+			return nil, fmt.Errorf("want %d bytes after 0x%02x, have %d", n, code, len(rest))
+		}
+		b := rest[:n]
+		rest = rest[n:]
+		return b, nil
+	}
+	length := func(size int) (int, error) {
+		b, err := take(size)
+		if err != nil {
+			return 0, err
+		}
+		switch size {
+		case 1:
+			return int(b[0]), nil
+		case 2:
+			return int(binary.BigEndian.Uint16(b)), nil
+		default:
+			return int(binary.BigEndian.Uint32(b)), nil
+		}
+	}
+	str := func(n int) (interface{}, []byte, error) {
+		b, err := take(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		return string(b), rest, nil
+	}
+	array := func(n int) (interface{}, []byte, error) {
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			item, next, err := decodeMsgpack(rest)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+			rest = next
+		}
+		return items, rest, nil
+	}
+	object := func(n int) (interface{}, []byte, error) {
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			key, next, err := decodeMsgpack(rest)
+			if err != nil {
+				return nil, nil, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("map key %v is %T", key, key)
+			}
+			value, next, err := decodeMsgpack(next)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[k] = value
+			rest = next
+		}
+		return m, rest, nil
+	}
+
+	switch {
+	case code <= 0x7f:
+		return int64(code), rest, nil
+	case code >= 0xe0:
+		return int64(int8(code)), rest, nil
+	case code&0xe0 == 0xa0:
+		return str(int(code & 0x1f))
+	case code&0xf0 == 0x90:
+		return array(int(code & 0x0f))
+	case code&0xf0 == 0x80:
+		return object(int(code & 0x0f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, rest, nil
+	case 0xc2:
+		return false, rest, nil
+	case 0xc3:
+		return true, rest, nil
+	case 0xcb:
+		b, err := take(8)
+		if err != nil {
+			return nil, nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), rest, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := take(1 << (code - 0xcc))
+		if err != nil {
+			return nil, nil, err
+		}
+		var u uint64
+		for _, c := range b {
+			u = u<<8 | uint64(c)
+		}
+		if u > math.MaxInt64 {
+			return nil, nil, fmt.Errorf("uint64 %d out of range", u)
+		}
+		return int64(u), rest, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (code - 0xd0)
+		b, err := take(size)
+		if err != nil {
+			return nil, nil, err
+		}
+		var u uint64
+		for _, c := range b {
+			u = u<<8 | uint64(c)
+		}
+		// Sign extend from the top bit of the value
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, rest, nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := length(1 << (code - 0xd9))
+		if err != nil {
+			return nil, nil, err
+		}
+		return str(n)
+	case 0xdc, 0xdd:
+		n, err := length(2 << (code - 0xdc))
+		if err != nil {
+			return nil, nil, err
+		}
+		return array(n)
+	case 0xde, 0xdf:
+		n, err := length(2 << (code - 0xde))
+		if err != nil {
+			return nil, nil, err
+		}
+		return object(n)
+	}
+	return nil, nil, fmt.Errorf("unexpected type byte 0x%02x", code)
+}
+
+// numbers returns the array [0, 1, ..., n-1]
+func numbers(n int) []interface{} {
+	items := make([]interface{}, n)
+	for i := range items {
+		items[i] = int64(i)
+	}
+	return items
+}
+
+// keys returns a map of n entries, "k00": 0 and so on
+func keys(n int) map[string]interface{} {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		m[fmt.Sprintf("k%02d", i)] = int64(i)
+	}
+	return m
+}
+
+func TestJSONToMsgpackRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		header []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"true", true, []byte{0xc3}},
+		{"false", false, []byte{0xc2}},
+
+		{"zero", int64(0), []byte{0x00}},
+		{"positive fixint max", int64(127), []byte{0x7f}},
+		{"uint8 min", int64(128), []byte{0xcc, 0x80}},
+		{"uint8 max", int64(math.MaxUint8), []byte{0xcc, 0xff}},
+		{"uint16 min", int64(math.MaxUint8 + 1), []byte{0xcd, 0x01, 0x00}},
+		{"uint16 max", int64(math.MaxUint16), []byte{0xcd, 0xff, 0xff}},
+		{"uint32 min", int64(math.MaxUint16 + 1), []byte{0xce, 0x00, 0x01, 0x00, 0x00}},
+		{"uint32 max", int64(math.MaxUint32), []byte{0xce, 0xff, 0xff, 0xff, 0xff}},
+		{"uint64 min", int64(math.MaxUint32 + 1), []byte{0xcf, 0, 0, 0, 0x01, 0, 0, 0, 0}},
+		{"uint64 max", int64(math.MaxInt64), []byte{0xcf, 0x7f}},
+
+		{"negative fixint max", int64(-1), []byte{0xff}},
+		{"negative fixint min", int64(-32), []byte{0xe0}},
+		{"int8 max", int64(-33), []byte{0xd0, 0xdf}},
+		{"int8 min", int64(math.MinInt8), []byte{0xd0, 0x80}},
+		{"int16 max", int64(math.MinInt8 - 1), []byte{0xd1, 0xff, 0x7f}},
+		{"int16 min", int64(math.MinInt16), []byte{0xd1, 0x80, 0x00}},
+		{"int32 max", int64(math.MinInt16 - 1), []byte{0xd2, 0xff, 0xff, 0x7f, 0xff}},
+		{"int32 min", int64(math.MinInt32), []byte{0xd2, 0x80, 0, 0, 0}},
+		{"int64 max", int64(math.MinInt32 - 1), []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+		{"int64 min", int64(math.MinInt64), []byte{0xd3, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+
+		{"float", 1.5, []byte{0xcb, 0x3f, 0xf8}},
+		{"negative float", -0.25, []byte{0xcb, 0xbf, 0xd0}},
+		{"large float", 1e300, []byte{0xcb}},
+
+		{"empty string", "", []byte{0xa0}},
+		{"fixstr max", strings.Repeat("a", 31), []byte{0xbf}},
+		{"str8 min", strings.Repeat("a", 32), []byte{0xd9, 32}},
+		{"str8 max", strings.Repeat("a", math.MaxUint8), []byte{0xd9, 0xff}},
+		{"str16 min", strings.Repeat("a", math.MaxUint8+1), []byte{0xda, 0x01, 0x00}},
+		{"str16 max", strings.Repeat("a", math.MaxUint16), []byte{0xda, 0xff, 0xff}},
+		{"str32 min", strings.Repeat("a", math.MaxUint16+1), []byte{0xdb, 0x00, 0x01, 0x00, 0x00}},
+		{"multibyte string", "Mbappé ⚽", []byte{0xab}},
+
+		{"empty array", []interface{}{}, []byte{0x90}},
+		{"fixarray max", numbers(15), []byte{0x9f}},
+		{"array16 min", numbers(16), []byte{0xdc, 0x00, 0x10}},
+		{"array16 max", numbers(math.MaxUint16), []byte{0xdc, 0xff, 0xff}},
+		{"array32 min", numbers(math.MaxUint16 + 1), []byte{0xdd, 0x00, 0x01, 0x00, 0x00}},
+
+		{"empty map", map[string]interface{}{}, []byte{0x80}},
+		{"fixmap max", keys(15), []byte{0x8f}},
+		{"map16 min", keys(16), []byte{0xde, 0x00, 0x10}},
+
+		{"nested maps", map[string]interface{}{
+			"type": "pickMade",
+			"seq":  int64(42),
+			"data": map[string]interface{}{
+				"pick": map[string]interface{}{
+					"playerId": int64(20801),
+					"rating":   91.5,
+					"player":   nil,
+					"tags":     []interface{}{"ST", map[string]interface{}{"k": keys(16)}},
+				},
+				"empty": map[string]interface{}{},
+			},
+		}, []byte{0x83}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := jsonToMsgpack(message)
+			if err != nil {
+				t.Fatalf("jsonToMsgpack: %v", err)
+			}
+			if !bytes.HasPrefix(encoded, tt.header) {
+				t.Errorf("header = % x, want % x", encoded[:min(len(encoded), len(tt.header))], tt.header)
+			}
+
+			decoded, rest, err := decodeMsgpack(encoded)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(rest) != 0 {
+				t.Errorf("%d trailing bytes", len(rest))
+			}
+			if !reflect.DeepEqual(decoded, tt.value) {
+				t.Errorf("round trip changed the value")
+			}
+		})
+	}
+}
+
+func TestJSONToMsgpackSortsMapKeys(t *testing.T) {
+	encoded, err := jsonToMsgpack([]byte(`{"b":1,"a":{"d":null,"c":true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x82,
+		0xa1, 'a', 0x82,
+		0xa1, 'c', 0xc3,
+		0xa1, 'd', 0xc0,
+		0xa1, 'b', 0x01,
+	}
+	if !bytes.Equal(encoded, want) {
+		t.Errorf("got % x, want % x", encoded, want)
+	}
+}
+
+func TestJSONToMsgpackRejectsInvalidJSON(t *testing.T) {
+	if _, err := jsonToMsgpack([]byte(`{"a":`)); err == nil {
+		t.Error("expected an error for truncated JSON")
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+)
+
+// Participant roles in a listed draft
+const (
+	DraftRoleAdmin       = "admin"
+	DraftRoleParticipant = "participant"
+)
+
+type ParticipantDraft struct {
+	Draft      database.Draft `json:"draft"`
+	Role       string         `json:"role"`
+	DraftOrder int            `json:"draftOrder"`
+}
+
+type ListDraftsResponse struct {
+	Drafts []ParticipantDraft `json:"drafts"`
+}
+
+// listParticipantDrafts returns every draft a participant name has joined,
+// newest first, so returning users can find their drafts without the codes.
+// Supports an optional status filter.
+func (h *Handler) listParticipantDrafts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("participant")
+	if name == "" {
+		http.Error(w, "participant is required", http.StatusBadRequest)
+		return
+	}
+
+	var rows []struct {
+		database.Draft
+		IsAdmin    bool `db:"participant_is_admin"`
+		DraftOrder int  `db:"participant_draft_order"`
+	}
+	err := h.db.Select(&rows, `
+		SELECT `+database.DraftColumns+`, participant_is_admin, participant_draft_order
+		FROM drafts
+		JOIN (
+			SELECT draft_id, is_admin AS participant_is_admin, draft_order AS participant_draft_order
+			FROM draft_participants WHERE name = $1 AND NOT is_bot
+		) p ON p.draft_id = drafts.id
+		WHERE $2 = '' OR status = $2
+		ORDER BY created_at DESC
+	`, name, query.Get("status"))
+	if err != nil {
+		log.Printf("List participant drafts error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListDraftsResponse{Drafts: make([]ParticipantDraft, 0, len(rows))}
+	for _, row := range rows {
+		role := DraftRoleParticipant
+		if row.IsAdmin {
+			role = DraftRoleAdmin
+		}
+		response.Drafts = append(response.Drafts, ParticipantDraft{
+			Draft:      row.Draft,
+			Role:       role,
+			DraftOrder: row.DraftOrder,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
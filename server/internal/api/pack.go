@@ -0,0 +1,181 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Pack sizes allowed in pack mode
+const (
+	minPackSize = 2
+	maxPackSize = 10
+)
+
+type GetPackResponse struct {
+	Pack    database.DraftPack `json:"pack"`
+	Players []PickPlayer       `json:"players"`
+}
+
+// dealPack returns the pack for the turn on the clock, dealing a random set of
+// players the participant could pick if there isn't one yet. A pack stays the
+// same until it's picked from, so refreshing never re-rolls it.
+func (h *Handler) dealPack(draft database.Draft, participant database.DraftParticipant) (database.DraftPack, error) {
+	overallPickNumber := (draft.CurrentRound-1)*draft.ParticipantCount + draft.CurrentPickInRound
+
+	var pack database.DraftPack
+	err := h.db.Get(&pack, `
+		SELECT draft_id, overall_pick_number, participant_id, player_ids, dealt_at, expires_at
+		FROM draft_packs WHERE draft_id = $1 AND overall_pick_number = $2
+	`, draft.ID, overallPickNumber)
+	if err == nil {
+		return pack, nil
+	}
+	if err != sql.ErrNoRows {
+		return pack, err
+	}
+
+	conditions, args, err := h.eligiblePlayerConditions(draft, participant)
+	if err != nil {
+		return pack, err
+	}
+	args = append(args, *draft.PackSize)
+	query := "SELECT id FROM players WHERE " + strings.Join(conditions, " AND ") +
+		fmt.Sprintf(" ORDER BY random() LIMIT $%d", len(args))
+
+	var playerIDs pq.Int64Array
+	if err := h.db.Select(&playerIDs, query, args...); err != nil {
+		return pack, err
+	}
+	if len(playerIDs) == 0 {
+		return pack, fmt.Errorf("no eligible players left to deal")
+	}
+
+	// Whoever deals first wins if two requests race
+	_, err = h.db.Exec(`
+		INSERT INTO draft_packs (draft_id, overall_pick_number, participant_id, player_ids, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (draft_id, overall_pick_number) DO NOTHING
+	`, draft.ID, overallPickNumber, participant.ID, playerIDs, draft.PickDeadline)
+	if err != nil {
+		return pack, err
+	}
+
+	err = h.db.Get(&pack, `
+		SELECT draft_id, overall_pick_number, participant_id, player_ids, dealt_at, expires_at
+		FROM draft_packs WHERE draft_id = $1 AND overall_pick_number = $2
+	`, draft.ID, overallPickNumber)
+	if err == nil {
+		log.Printf("Dealt a pack of %d players to %s in draft %s", len(pack.PlayerIDs), participant.Name, draft.Code)
+	}
+	return pack, err
+}
+
+// checkPackPick returns an error unless the player is in the pack dealt for
+// the turn
+func checkPackPick(q sqlx.Queryer, draftID, overallPickNumber, playerID int) error {
+	var inPack bool
+	err := sqlx.Get(q, &inPack, `
+		SELECT $3 = ANY(player_ids) FROM draft_packs
+		WHERE draft_id = $1 AND overall_pick_number = $2
+	`, draftID, overallPickNumber, playerID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no pack has been dealt for this turn")
+	}
+	if err != nil {
+		return fmt.Errorf("database error checking pack")
+	}
+	if !inPack {
+		return fmt.Errorf("player is not in your pack")
+	}
+	return nil
+}
+
+// getPack deals or returns the pack of the participant on the clock. Only
+// they can see it, so the request carries their name and token.
+func (h *Handler) getPack(w http.ResponseWriter, r *http.Request, code string) {
+	participantName := r.URL.Query().Get("participant")
+	if participantName == "" {
+		http.Error(w, "participant is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := verifyParticipantToken(h.db, code, participantName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for pack error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.PackSize == nil {
+		http.Error(w, "Draft is not in pack mode", http.StatusBadRequest)
+		return
+	}
+
+	if draft.Status != "active" {
+		http.Error(w, "Draft is not active", http.StatusBadRequest)
+		return
+	}
+
+	var participant database.DraftParticipant
+	err = h.db.Get(&participant, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 AND name = $2
+	`, draft.ID, participantName)
+	if err != nil {
+		http.Error(w, "Participant not found", http.StatusNotFound)
+		return
+	}
+
+	currentPicker := calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+	if participant.DraftOrder != currentPicker {
+		http.Error(w, "It's not your turn", http.StatusConflict)
+		return
+	}
+
+	pack, err := h.dealPack(draft, participant)
+	if err != nil {
+		log.Printf("Deal pack error: %v", err)
+		http.Error(w, "Failed to deal pack", http.StatusInternalServerError)
+		return
+	}
+
+	players := []PickPlayer{}
+	err = h.db.Select(&players, `
+		SELECT id, first_name, last_name, common_name, overall_rating, position_short_label,
+		       alternate_positions, team_label, team_image_url, league_name, nationality_label,
+		       nationality_image_url, avatar_url, shield_url
+		FROM players WHERE id = ANY($1) AND dataset = $2
+		ORDER BY overall_rating DESC, id
+	`, pack.PlayerIDs, draft.Dataset)
+	if err != nil {
+		log.Printf("Get pack players error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := GetPackResponse{
+		Pack:    pack,
+		Players: players,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"log"
+	"time"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// setPickDeadline starts the clock for the turn that was just reached, if the
+// draft has a pick timer. It returns the new deadline, or nil without a timer.
+func (h *Handler) setPickDeadline(tx *sqlx.Tx, draft database.Draft, active bool) (*time.Time, error) {
+	var deadline *time.Time
+	if active && draft.PickTimerSeconds > 0 {
+		d := time.Now().Add(time.Duration(draft.PickTimerSeconds) * time.Second).Truncate(time.Microsecond)
+		deadline = &d
+	}
+
+	_, err := tx.Exec("UPDATE drafts SET pick_deadline = $1 WHERE id = $2", deadline, draft.ID)
+	return deadline, err
+}
+
+// pickTimerTickInterval is how often the room is told the time left on the
+// clock
+const pickTimerTickInterval = 5 * time.Second
+
+// schedulePickTimer auto-picks for the participant on the clock if the turn
+// at round/pickInRound is still open when the deadline passes, and counts the
+// clock down for the room until then
+func (h *Handler) schedulePickTimer(draftCode string, round, pickInRound int, deadline time.Time) {
+	go h.runPickClock(draftCode, round, pickInRound, deadline)
+
+	time.AfterFunc(time.Until(deadline), func() {
+		var draft database.Draft
+		err := h.db.Get(&draft, `
+			SELECT `+database.DraftColumns+`
+			FROM drafts WHERE code = $1
+		`, draftCode)
+		if err != nil {
+			log.Printf("Get draft for pick timer error: %v", err)
+			return
+		}
+
+		// Nothing to do if the pick was made or the clock was reset
+		if draft.Status != "active" || draft.CurrentRound != round || draft.CurrentPickInRound != pickInRound {
+			return
+		}
+		if draft.PickDeadline == nil || time.Now().Before(*draft.PickDeadline) {
+			return
+		}
+
+		// In blind mode the clock runs for the whole round, reveal it as is
+		if draft.BlindMode {
+			if h.resolveBlindRound(draftCode, draft.CurrentRound) {
+				h.resolveBlindRoundsIfReady(draftCode)
+			}
+			return
+		}
+
+		currentPicker := calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+
+		var participant database.DraftParticipant
+		err = h.db.Get(&participant, `
+			SELECT `+database.ParticipantColumns+`
+			FROM draft_participants WHERE draft_id = $1 AND draft_order = $2
+		`, draft.ID, currentPicker)
+		if err != nil {
+			log.Printf("Get participant for pick timer error: %v", err)
+			return
+		}
+
+		// Pick the best available player on their behalf
+		strategy := BotStrategyBestAvailable
+		participant.BotStrategy = &strategy
+		playerID, err := h.chooseBotPick(draft, participant)
+		if err != nil {
+			log.Printf("Pick timer could not choose for %s in draft %s: %v", participant.Name, draftCode, err)
+			return
+		}
+
+		made, err := h.processPick(draftCode, participant.Name, playerID, "")
+		if err != nil {
+			log.Printf("Pick timer auto-pick failed for %s in draft %s: %v", participant.Name, draftCode, err)
+			return
+		}
+
+		log.Printf("Pick timer expired for %s in draft %s, auto-picked player %d", participant.Name, draftCode, playerID)
+
+		broadcastPickMade(h.db, draftCode, made)
+		notifyAutoPicked(draftCode, participant.Name, playerID)
+		h.runBotPicks(draftCode)
+	})
+}
+
+// ResumePickTimers reschedules the clocks of active drafts, which only live in
+// memory and so stop with the server. Turns whose deadline passed while it
+// was down are auto-picked straight away.
+func (h *Handler) ResumePickTimers() {
+	var turns []struct {
+		Code               string    `db:"code"`
+		CurrentRound       int       `db:"current_round"`
+		CurrentPickInRound int       `db:"current_pick_in_round"`
+		PickDeadline       time.Time `db:"pick_deadline"`
+	}
+	err := h.db.Select(&turns, `
+		SELECT code, current_round, current_pick_in_round, pick_deadline
+		FROM drafts WHERE status = 'active' AND pick_deadline IS NOT NULL
+	`)
+	if err != nil {
+		log.Printf("Get drafts for pick timers error: %v", err)
+		return
+	}
+
+	for _, turn := range turns {
+		h.schedulePickTimer(turn.Code, turn.CurrentRound, turn.CurrentPickInRound, turn.PickDeadline)
+	}
+	if len(turns) > 0 {
+		log.Printf("Resumed %d pick timers", len(turns))
+	}
+}
+
+// runPickClock broadcasts the deadline of the turn at round/pickInRound and
+// the time left on it every pickTimerTickInterval, until the turn is over.
+// Clients render a clock in sync with everyone else from the deadline, and
+// correct for their own clock being off with the server time.
+func (h *Handler) runPickClock(draftCode string, round, pickInRound int, deadline time.Time) {
+	ticker := time.NewTicker(pickTimerTickInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if !now.Before(deadline) {
+			return
+		}
+
+		broadcastEphemeralMessage(draftCode, "pickTimer", map[string]interface{}{
+			"roundNumber":      round,
+			"pickInRound":      pickInRound,
+			"pickDeadline":     deadline,
+			"serverTime":       now,
+			"remainingSeconds": int(deadline.Sub(now).Round(time.Second).Seconds()),
+		})
+
+		<-ticker.C
+
+		// Stop once the pick is made or the clock is reset
+		var turn struct {
+			Status             string     `db:"status"`
+			CurrentRound       int        `db:"current_round"`
+			CurrentPickInRound int        `db:"current_pick_in_round"`
+			PickDeadline       *time.Time `db:"pick_deadline"`
+		}
+		err := h.db.Get(&turn, `
+			SELECT status, current_round, current_pick_in_round, pick_deadline
+			FROM drafts WHERE code = $1
+		`, draftCode)
+		if err != nil {
+			log.Printf("Get draft for pick clock error: %v", err)
+			return
+		}
+		if turn.Status != "active" || turn.CurrentRound != round || turn.CurrentPickInRound != pickInRound ||
+			turn.PickDeadline == nil || !turn.PickDeadline.Equal(deadline) {
+			return
+		}
+	}
+}
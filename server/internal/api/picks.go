@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"eafc-draft-server/internal/database"
+)
+
+// PickPlayer is the player details included with each pick
+type PickPlayer struct {
+	ID                  int     `db:"id" json:"id"`
+	FirstName           *string `db:"first_name" json:"firstName"`
+	LastName            *string `db:"last_name" json:"lastName"`
+	CommonName          *string `db:"common_name" json:"commonName"`
+	OverallRating       *int    `db:"overall_rating" json:"overallRating"`
+	PositionShortLabel  *string `db:"position_short_label" json:"positionShortLabel"`
+	AlternatePositions  *string `db:"alternate_positions" json:"alternatePositions"`
+	TeamLabel           *string `db:"team_label" json:"teamLabel"`
+	TeamImageURL        *string `db:"team_image_url" json:"teamImageUrl"`
+	LeagueName          *string `db:"league_name" json:"leagueName"`
+	NationalityLabel    *string `db:"nationality_label" json:"nationalityLabel"`
+	NationalityImageURL *string `db:"nationality_image_url" json:"nationalityImageUrl"`
+	AvatarURL           *string `db:"avatar_url" json:"avatarUrl"`
+	ShieldURL           *string `db:"shield_url" json:"shieldUrl"`
+}
+
+// PickWithPlayer is a draft pick joined with who made it and who was picked
+type PickWithPlayer struct {
+	database.DraftPick
+	ParticipantName string     `db:"participant_name" json:"participantName"`
+	Player          PickPlayer `db:"player" json:"player"`
+}
+
+type GetPicksResponse struct {
+	Picks      []PickWithPlayer `json:"picks"`
+	Pagination *Pagination      `json:"pagination"`
+}
+
+// getPicks lists a draft's picks in pick order. Supports filtering by
+// participant, round, tier and position (a position label or group such as
+// DEF), with page/limit pagination.
+func (h *Handler) getPicks(w http.ResponseWriter, r *http.Request, code string) {
+	var draftID int
+	err := h.db.Get(&draftID, "SELECT id FROM drafts WHERE code = $1", code)
+	if err != nil {
+		log.Printf("Get draft for picks error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	offset := (page - 1) * limit
+
+	conditions := []string{"dp.draft_id = $1"}
+	args := []interface{}{draftID}
+	argIndex := 2
+
+	if participant := query.Get("participant"); participant != "" {
+		conditions = append(conditions, fmt.Sprintf("part.name = $%d", argIndex))
+		args = append(args, participant)
+		argIndex++
+	}
+
+	if roundParam := query.Get("round"); roundParam != "" {
+		round, err := strconv.Atoi(roundParam)
+		if err != nil {
+			http.Error(w, "round must be a number", http.StatusBadRequest)
+			return
+		}
+		conditions = append(conditions, fmt.Sprintf("dp.round_number = $%d", argIndex))
+		args = append(args, round)
+		argIndex++
+	}
+
+	if tier := query.Get("tier"); tier != "" {
+		conditions = append(conditions, fmt.Sprintf("dp.player_rating_tier = $%d", argIndex))
+		args = append(args, tier)
+		argIndex++
+	}
+
+	if position := strings.ToUpper(query.Get("position")); position != "" {
+		if _, isGroup := positionGroups[position]; isGroup {
+			conditions = append(conditions, fmt.Sprintf("p.position_short_label = ANY($%d)", argIndex))
+			args = append(args, positionLabelsArray(position))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("p.position_short_label = $%d", argIndex))
+			args = append(args, position)
+		}
+		argIndex++
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+	fromClause := `
+		FROM draft_picks dp
+		JOIN drafts d ON dp.draft_id = d.id
+		JOIN players p ON dp.player_id = p.id AND p.dataset = d.dataset
+		JOIN draft_participants part ON dp.participant_id = part.id
+		WHERE ` + whereClause
+
+	var totalCount int
+	err = h.db.Get(&totalCount, "SELECT COUNT(*) "+fromClause, args...)
+	if err != nil {
+		log.Printf("Count picks error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	picks := []PickWithPlayer{}
+	err = h.db.Select(&picks, `
+		SELECT dp.id, dp.draft_id, dp.participant_id, dp.player_id, dp.round_number,
+		       dp.pick_in_round, dp.overall_pick_number, dp.player_rating_tier, dp.picked_at, dp.is_keeper, dp.comment,
+		       part.name AS participant_name,
+		       p.id AS "player.id", p.first_name AS "player.first_name", p.last_name AS "player.last_name",
+		       p.common_name AS "player.common_name", p.overall_rating AS "player.overall_rating",
+		       p.position_short_label AS "player.position_short_label",
+		       p.alternate_positions AS "player.alternate_positions", p.team_label AS "player.team_label",
+		       p.team_image_url AS "player.team_image_url", p.league_name AS "player.league_name",
+		       p.nationality_label AS "player.nationality_label",
+		       p.nationality_image_url AS "player.nationality_image_url",
+		       p.avatar_url AS "player.avatar_url", p.shield_url AS "player.shield_url"
+		`+fromClause+fmt.Sprintf(`
+		ORDER BY dp.overall_pick_number
+		LIMIT $%d OFFSET $%d`, argIndex, argIndex+1), append(args, limit, offset)...)
+	if err != nil {
+		log.Printf("Get picks error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	response := GetPicksResponse{
+		Picks: picks,
+		Pagination: &Pagination{
+			Page:        page,
+			Limit:       limit,
+			TotalItems:  totalCount,
+			TotalPages:  totalPages,
+			HasNext:     page < totalPages,
+			HasPrevious: page > 1,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// maxPickCommentLength caps the comment a participant can attach to a pick
+const maxPickCommentLength = 140
+
+func validatePickComment(comment string) error {
+	if utf8.RuneCountInString(comment) > maxPickCommentLength {
+		return fmt.Errorf("comment must be at most %d characters", maxPickCommentLength)
+	}
+	return nil
+}
+
+// pickComment trims a pick comment, returning nil when there is none
+func pickComment(comment string) *string {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil
+	}
+	return &comment
+}
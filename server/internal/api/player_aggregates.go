@@ -0,0 +1,98 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PlayerAggregate summarises the players sharing one league, club,
+// nationality or position
+type PlayerAggregate struct {
+	Value         string   `db:"value" json:"value"`
+	Count         int      `db:"player_count" json:"count"`
+	AverageRating *float64 `db:"average_rating" json:"averageRating"`
+	MaxRating     *int     `db:"max_rating" json:"maxRating"`
+}
+
+type GetPlayerAggregatesResponse struct {
+	TotalPlayers  int               `json:"totalPlayers"`
+	AverageRating *float64          `json:"averageRating"`
+	Leagues       []PlayerAggregate `json:"leagues"`
+	Clubs         []PlayerAggregate `json:"clubs"`
+	Nationalities []PlayerAggregate `json:"nationalities"`
+	Positions     []PlayerAggregate `json:"positions"` // by main position
+}
+
+// getPlayerAggregates returns player counts and ratings grouped by league,
+// club, nationality and position, largest groups first. It takes the same
+// filters as the player listing, so a theme draft can be sized up before it
+// is created.
+func (h *Handler) getPlayerAggregates(w http.ResponseWriter, r *http.Request) {
+	log.Printf("GET /api/players/aggregates - Query params: %v", r.URL.Query())
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	conditions, args, _, err := h.playerFilterConditions(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	baseQuery := " FROM " + listedPlayers + " players"
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	response := GetPlayerAggregatesResponse{}
+	var totals struct {
+		Count         int      `db:"player_count"`
+		AverageRating *float64 `db:"average_rating"`
+	}
+	err = h.db.Get(&totals, `SELECT COUNT(*) AS player_count,
+		ROUND(AVG(overall_rating), 1)::float8 AS average_rating`+baseQuery+whereClause, args...)
+	if err != nil {
+		log.Printf("Player aggregates query error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	response.TotalPlayers = totals.Count
+	response.AverageRating = totals.AverageRating
+
+	groups := map[string]*[]PlayerAggregate{
+		"league_name":          &response.Leagues,
+		"team_label":           &response.Clubs,
+		"nationality_label":    &response.Nationalities,
+		"position_short_label": &response.Positions,
+	}
+	for column, aggregates := range groups {
+		groupConditions := append([]string{column + " IS NOT NULL"}, conditions...)
+		*aggregates = []PlayerAggregate{}
+		err := h.db.Select(aggregates, `
+			SELECT `+column+` AS value, COUNT(*) AS player_count,
+				ROUND(AVG(overall_rating), 1)::float8 AS average_rating,
+				MAX(overall_rating) AS max_rating`+baseQuery+`
+			WHERE `+strings.Join(groupConditions, " AND ")+`
+			GROUP BY `+column+`
+			ORDER BY player_count DESC, value
+		`, args...)
+		if err != nil {
+			log.Printf("Player aggregates by %s error: %v", column, err)
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Draft-scoped aggregates change with every pick, so only the ETag applies
+	var lastModified *time.Time
+	if r.URL.Query().Get("draft") == "" {
+		updatedAt := h.playerData.lastModified()
+		lastModified = &updatedAt
+	}
+	writeCachableJSON(w, r, response, lastModified)
+}
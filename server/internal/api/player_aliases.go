@@ -0,0 +1,153 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/lib/pq"
+)
+
+const maxAliasLength = 50
+
+type CreatePlayerAliasRequest struct {
+	PlayerID int    `json:"playerId"`
+	Alias    string `json:"alias"`
+}
+
+type GetPlayerAliasesResponse struct {
+	Aliases []database.PlayerAlias `json:"aliases"`
+}
+
+// handlePlayerAliases manages the nicknames player search resolves:
+// GET and POST /api/admin/players/aliases, DELETE /api/admin/players/aliases/{id}
+func (h *Handler) handlePlayerAliases(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s", r.Method, r.URL.Path)
+
+	idPart := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/players/aliases"), "/")
+	if idPart == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.getPlayerAliases(w, r)
+		case http.MethodPost:
+			h.createPlayerAlias(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		http.Error(w, "Invalid alias ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodDelete:
+		h.deletePlayerAlias(w, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getPlayerAliases lists every alias, or a single player's with ?player_id=
+func (h *Handler) getPlayerAliases(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT id, player_id, alias, created_at FROM player_aliases"
+	var args []interface{}
+	if value := r.URL.Query().Get("player_id"); value != "" {
+		playerID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid player ID", http.StatusBadRequest)
+			return
+		}
+		query += " WHERE player_id = $1"
+		args = append(args, playerID)
+	}
+	query += " ORDER BY player_id, lower(alias)"
+
+	aliases := []database.PlayerAlias{}
+	if err := h.db.Select(&aliases, query, args...); err != nil {
+		log.Printf("Get player aliases error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GetPlayerAliasesResponse{Aliases: aliases})
+}
+
+func (h *Handler) createPlayerAlias(w http.ResponseWriter, r *http.Request) {
+	var req CreatePlayerAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Create player alias decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Alias = strings.Join(strings.Fields(req.Alias), " ")
+	if req.Alias == "" || len(req.Alias) > maxAliasLength {
+		http.Error(w, "alias must be 1-"+strconv.Itoa(maxAliasLength)+" characters", http.StatusBadRequest)
+		return
+	}
+
+	// Aliases apply to the player in every dataset, so any dataset will do
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM players WHERE id = $1)", req.PlayerID); err != nil {
+		log.Printf("Check alias player error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+
+	var alias database.PlayerAlias
+	err := h.db.Get(&alias, `
+		INSERT INTO player_aliases (player_id, alias) VALUES ($1, $2)
+		RETURNING id, player_id, alias, created_at
+	`, req.PlayerID, req.Alias)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			http.Error(w, "The player already has this alias", http.StatusConflict)
+			return
+		}
+		log.Printf("Create player alias error: %v", err)
+		http.Error(w, "Failed to create alias", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Added alias %q for player %d", alias.Alias, alias.PlayerID)
+	h.playersChanged()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(alias)
+}
+
+func (h *Handler) deletePlayerAlias(w http.ResponseWriter, id int) {
+	var playerID int
+	err := h.db.Get(&playerID, "DELETE FROM player_aliases WHERE id = $1 RETURNING player_id", id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Alias not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Delete player alias error: %v", err)
+		http.Error(w, "Failed to delete alias", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Deleted alias %d of player %d", id, playerID)
+	h.playersChanged()
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/lib/pq"
+)
+
+const maxBatchPlayers = 500
+
+type BatchPlayersRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+type BatchPlayersResponse struct {
+	Players    interface{} `json:"players"`    // []database.Player, or field maps with fields=
+	MissingIDs []int64     `json:"missingIds"` // requested ids not in the dataset
+}
+
+// getPlayersBatch returns the players with the posted ids in the order they
+// were asked for, without the URL length limits of id=in:... It supports the
+// dataset and fields parameters.
+func (h *Handler) getPlayersBatch(w http.ResponseWriter, r *http.Request) {
+	log.Printf("POST /api/players/batch")
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dataset, err := h.requestedDataset(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	fields, err := parsePlayerFields(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	var req BatchPlayersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Batch players decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.IDs) == 0 {
+		http.Error(w, "ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) > maxBatchPlayers {
+		http.Error(w, "At most "+strconv.Itoa(maxBatchPlayers)+" ids can be fetched at once", http.StatusBadRequest)
+		return
+	}
+
+	var found []database.Player
+	err = h.db.Select(&found, `
+		SELECT * FROM `+listedPlayers+` players
+		WHERE dataset = $1 AND id = ANY($2)
+	`, dataset, pq.Int64Array(req.IDs))
+	if err != nil {
+		log.Printf("Batch players query error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	byID := make(map[int64]database.Player, len(found))
+	for _, player := range found {
+		byID[int64(player.ID)] = player
+	}
+
+	players := []database.Player{}
+	missing := []int64{}
+	seen := make(map[int64]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if player, ok := byID[id]; ok {
+			players = append(players, player)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BatchPlayersResponse{
+		Players:    selectPlayerFields(players, fields),
+		MissingIDs: missing,
+	})
+}
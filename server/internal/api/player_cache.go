@@ -0,0 +1,115 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// playerDataState tracks when the player dataset last changed, so clients can
+// revalidate cached player responses instead of downloading them again. It
+// also holds the filter enums, which only change with the dataset.
+type playerDataState struct {
+	mutex     sync.RWMutex
+	updatedAt time.Time
+	enums     *GetPlayerEnumsResponse // nil until loaded or after a change
+}
+
+func newPlayerDataState() *playerDataState {
+	return &playerDataState{updatedAt: time.Now().UTC().Truncate(time.Second)}
+}
+
+func (p *playerDataState) lastModified() time.Time {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.updatedAt
+}
+
+// playersChanged must be called whenever the players table is modified. It
+// drops the cached enums so the next request reloads them.
+func (h *Handler) playersChanged() {
+	h.playerData.mutex.Lock()
+	defer h.playerData.mutex.Unlock()
+	h.playerData.updatedAt = time.Now().UTC().Truncate(time.Second)
+	h.playerData.enums = nil
+}
+
+// playerEnums returns the player filter enums, loading them on first use
+func (h *Handler) playerEnums() (GetPlayerEnumsResponse, error) {
+	h.playerData.mutex.RLock()
+	enums := h.playerData.enums
+	h.playerData.mutex.RUnlock()
+	if enums != nil {
+		return *enums, nil
+	}
+
+	h.playerData.mutex.Lock()
+	defer h.playerData.mutex.Unlock()
+	if h.playerData.enums != nil {
+		return *h.playerData.enums, nil
+	}
+
+	loaded, err := loadPlayerEnums(h.db)
+	if err != nil {
+		return loaded, err
+	}
+	h.playerData.enums = &loaded
+	return loaded, nil
+}
+
+// WarmPlayerCache loads the player filter enums ahead of the first request
+func (h *Handler) WarmPlayerCache() error {
+	_, err := h.playerEnums()
+	return err
+}
+
+// writeCachableJSON writes response as JSON with an ETag of its content. A
+// matching If-None-Match gets 304 Not Modified. Responses that only depend on
+// the player dataset also pass lastModified for If-Modified-Since.
+func writeCachableJSON(w http.ResponseWriter, r *http.Request, response interface{}, lastModified *time.Time) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache") // always revalidate
+	if lastModified != nil {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if etagMatches(match, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if since := r.Header.Get("If-Modified-Since"); since != "" && lastModified != nil {
+		if t, err := http.ParseTime(since); err == nil && !lastModified.After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// etagMatches checks an If-None-Match header, which may list several tags
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
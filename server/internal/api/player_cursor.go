@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// playerCursor marks the last player of a page in keyset pagination. It holds
+// the sort key and id, plus the ordering it was made for so a cursor can't be
+// reused with a different sort.
+type playerCursor struct {
+	SortBy        string      `json:"s"`
+	SortDirection string      `json:"d"`
+	Value         interface{} `json:"v"` // nil when the sort column was NULL
+	ID            int         `json:"id"`
+}
+
+func encodePlayerCursor(cursor playerCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePlayerCursor(value string) (playerCursor, error) {
+	var cursor playerCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, fmt.Errorf("invalid cursor")
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&cursor); err != nil {
+		return cursor, fmt.Errorf("invalid cursor")
+	}
+	if number, ok := cursor.Value.(json.Number); ok {
+		cursor.Value = number.String()
+	}
+	return cursor, nil
+}
+
+// playerCursorCondition returns the WHERE condition selecting players after
+// the cursor, for rows ordered by sortBy with NULLS LAST and then id
+func playerCursorCondition(cursor playerCursor, argIndex int) (string, []interface{}, int) {
+	if cursor.Value == nil {
+		// Already inside the trailing NULLs, only ids are left to order by
+		return fmt.Sprintf("(%s IS NULL AND id > $%d)", cursor.SortBy, argIndex), []interface{}{cursor.ID}, argIndex + 1
+	}
+
+	comparison := ">"
+	if cursor.SortDirection == "desc" {
+		comparison = "<"
+	}
+	condition := fmt.Sprintf("(%s %s $%d OR (%s = $%d AND id > $%d) OR %s IS NULL)",
+		cursor.SortBy, comparison, argIndex, cursor.SortBy, argIndex, argIndex+1, cursor.SortBy)
+	return condition, []interface{}{cursor.Value, cursor.ID}, argIndex + 2
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+)
+
+// parsePlayerFields reads fields=, a comma separated list of player columns
+// such as id,common_name,overall_rating,position_short_label, so clients can
+// fetch only what they render. The id is always included. It returns nil
+// when the parameter is absent, meaning every field.
+func parsePlayerFields(r *http.Request) ([]string, error) {
+	value := r.URL.Query().Get("fields")
+	if value == "" {
+		return nil, nil
+	}
+
+	fields := []string{"id"}
+	seen := map[string]bool{"id": true}
+	for _, column := range strings.Split(value, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" || seen[column] {
+			continue
+		}
+		if !database.IsPlayerField(column) {
+			return nil, newStatusError(http.StatusBadRequest, "Unknown player field: "+column)
+		}
+		seen[column] = true
+		fields = append(fields, column)
+	}
+	return fields, nil
+}
+
+// selectPlayerFields trims players down to the requested fields, or returns
+// them whole when fields is nil
+func selectPlayerFields(players []database.Player, fields []string) interface{} {
+	if fields == nil {
+		return players
+	}
+	selected := make([]map[string]interface{}, len(players))
+	for i, player := range players {
+		selected[i] = player.SelectFields(fields)
+	}
+	return selected
+}
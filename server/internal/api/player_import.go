@@ -0,0 +1,371 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"eafc-draft-server/internal/database"
+)
+
+// maxImportSize caps an uploaded player dataset
+const maxImportSize = 50 << 20
+
+type ImportSkippedRow struct {
+	Row    int    `json:"row"` // 1-based, not counting the CSV header
+	ID     *int   `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type ImportPlayersResponse struct {
+	Inserted       int                `json:"inserted"`
+	Updated        int                `json:"updated"`
+	Skipped        int                `json:"skipped"`
+	SkippedRows    []ImportSkippedRow `json:"skippedRows"`
+	IgnoredColumns []string           `json:"ignoredColumns"` // columns in the file that players don't have
+}
+
+// importPlayers loads a player dataset from a CSV (with a header row, as the
+// scraper writes it) or a JSON array of player objects, upserting on player
+// id within the ?dataset= dataset (the server default if not given). The file
+// is sent as the request body or as the "file" form field.
+func (h *Handler) importPlayers(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s /api/admin/players/import", r.Method)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dataset, err := h.requestedDataset(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	body, format, err := importSource(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+
+	rows, err := parsePlayerFile(body, format)
+	if err != nil {
+		http.Error(w, "Invalid "+format+" file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.upsertPlayers(rows, dataset)
+	if err != nil {
+		log.Printf("Import players error: %v", err)
+		http.Error(w, "Failed to import players", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Player import into %s: %d inserted, %d updated, %d skipped", dataset, response.Inserted, response.Updated, response.Skipped)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// importSource finds the uploaded file and whether it is CSV or JSON
+func importSource(r *http.Request) (io.ReadCloser, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("multipart uploads need a \"file\" field")
+		}
+		switch strings.ToLower(filepath.Ext(header.Filename)) {
+		case ".csv":
+			return file, "csv", nil
+		case ".json":
+			return file, "json", nil
+		}
+		file.Close()
+		return nil, "", fmt.Errorf("file must be .csv or .json")
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return r.Body, "csv", nil
+	case "application/json":
+		return r.Body, "json", nil
+	}
+	return nil, "", fmt.Errorf("content type must be text/csv, application/json or multipart/form-data")
+}
+
+// parsePlayerFile reads a csv or json player dataset into rows keyed by column
+func parsePlayerFile(body io.Reader, format string) ([]map[string]string, error) {
+	if format == "json" {
+		return parseJSONPlayers(body)
+	}
+	return parseCSVPlayers(body)
+}
+
+func parseCSVPlayers(body io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = importColumnName(header[i])
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(header))
+		for i, value := range record {
+			row[header[i]] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseJSONPlayers(body io.Reader) ([]map[string]string, error) {
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+
+	var objects []map[string]interface{}
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]string, len(objects))
+	for i, object := range objects {
+		rows[i] = make(map[string]string, len(object))
+		for key, value := range object {
+			if value == nil {
+				rows[i][importColumnName(key)] = ""
+			} else {
+				rows[i][importColumnName(key)] = fmt.Sprint(value)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// importColumnName maps scraper headers (stat_ballControl) and Player JSON
+// keys (overallRating) to column names (stat_ball_control, overall_rating)
+func importColumnName(name string) string {
+	var b strings.Builder
+	for i, c := range strings.TrimSpace(name) {
+		if unicode.IsUpper(c) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	column := b.String()
+
+	// The JSON keys of image URLs end in "Url"
+	return strings.Replace(column, "_u_r_l", "_url", 1)
+}
+
+// upsertPlayers validates and stores the rows in one transaction under a
+// dataset. Invalid rows are skipped and reported, columns missing from a row
+// are left as they are on existing players.
+func (h *Handler) upsertPlayers(rows []map[string]string, dataset string) (ImportPlayersResponse, error) {
+	response := ImportPlayersResponse{SkippedRows: []ImportSkippedRow{}, IgnoredColumns: []string{}}
+
+	known := make(map[string]bool)
+	for _, column := range database.GetPlayerColumns() {
+		known[column] = true
+	}
+	numberColumns := database.GetNumberColumns()
+
+	ignored := make(map[string]bool)
+	for _, row := range rows {
+		for column := range row {
+			if !known[column] && !ignored[column] {
+				ignored[column] = true
+				response.IgnoredColumns = append(response.IgnoredColumns, column)
+			}
+		}
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		return response, err
+	}
+	defer tx.Rollback()
+
+	for i, row := range rows {
+		values, id, reason := validateImportRow(row, known, numberColumns)
+		if reason != "" {
+			response.Skipped++
+			response.SkippedRows = append(response.SkippedRows, ImportSkippedRow{Row: i + 1, ID: id, Reason: reason})
+			continue
+		}
+		values["dataset"] = dataset
+
+		columns := make([]string, 0, len(values))
+		placeholders := make([]string, 0, len(values))
+		updates := make([]string, 0, len(values))
+		args := make([]interface{}, 0, len(values))
+		for _, column := range database.GetPlayerColumns() {
+			value, ok := values[column]
+			if !ok {
+				continue
+			}
+			columns = append(columns, column)
+			args = append(args, value)
+			placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+			if column != "id" && column != "dataset" {
+				updates = append(updates, column+" = EXCLUDED."+column)
+			}
+		}
+
+		conflict := "DO NOTHING"
+		if len(updates) > 0 {
+			conflict = "DO UPDATE SET " + strings.Join(updates, ", ")
+		}
+
+		// xmax is 0 for freshly inserted rows
+		var inserted bool
+		err := tx.Get(&inserted, `
+			INSERT INTO players (`+strings.Join(columns, ", ")+`)
+			VALUES (`+strings.Join(placeholders, ", ")+`)
+			ON CONFLICT (id, dataset) `+conflict+`
+			RETURNING (xmax = 0)
+		`, args...)
+		if err != nil {
+			return response, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		if inserted {
+			response.Inserted++
+		} else {
+			response.Updated++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return response, err
+	}
+
+	if response.Inserted+response.Updated > 0 {
+		h.playersChanged()
+	}
+	return response, nil
+}
+
+// validateImportRow converts a row to column values. It returns the reason
+// the row can't be imported, or "".
+func validateImportRow(row map[string]string, known, numberColumns map[string]bool) (map[string]interface{}, *int, string) {
+	rawID := strings.TrimSpace(row["id"])
+	if rawID == "" {
+		return nil, nil, "missing id"
+	}
+	id, err := strconv.Atoi(rawID)
+	if err != nil || id <= 0 {
+		return nil, nil, "id must be a positive integer"
+	}
+
+	values := map[string]interface{}{"id": id}
+	for column, raw := range row {
+		// The dataset comes from the request, not the file
+		if !known[column] || column == "id" || column == "dataset" {
+			continue
+		}
+		raw = strings.TrimSpace(raw)
+		if column == "gender" {
+			// Gender can't be NULL, a blank keeps the stored value
+			if raw == "" {
+				continue
+			}
+			gender, ok := importGender(raw)
+			if !ok {
+				return nil, &id, "gender must be men or women"
+			}
+			values[column] = gender
+			continue
+		}
+		if column == "card_type" {
+			// Card type can't be NULL either
+			if raw == "" {
+				continue
+			}
+			cardType := strings.ToLower(raw)
+			if !isValidCardType(cardType) {
+				return nil, &id, "card_type must be standard, icon or hero"
+			}
+			values[column] = cardType
+			continue
+		}
+		if raw == "" {
+			values[column] = nil
+			continue
+		}
+		if column == "birthdate" {
+			birthdate, err := parseBirthdate(raw)
+			if err != nil {
+				return nil, &id, "birthdate must be YYYY-MM-DD or MM/DD/YYYY"
+			}
+			values[column] = birthdate
+			continue
+		}
+		if !numberColumns[column] {
+			values[column] = raw
+			continue
+		}
+
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, &id, fmt.Sprintf("%s must be an integer", column)
+		}
+		if (column == "overall_rating" || strings.HasPrefix(column, "stat_")) && (number < 1 || number > 99) {
+			return nil, &id, fmt.Sprintf("%s must be between 1 and 99", column)
+		}
+		if (column == "height" || column == "weight") && number <= 0 {
+			return nil, &id, fmt.Sprintf("%s must be positive", column)
+		}
+		values[column] = number
+	}
+
+	return values, &id, ""
+}
+
+// parseBirthdate reads an ISO date, or the US format EA's ratings use
+func parseBirthdate(value string) (time.Time, error) {
+	birthdate, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		birthdate, err = time.Parse("01/02/2006", value)
+	}
+	return birthdate, err
+}
+
+// importGender accepts men/women and EA's gender codes (0 for men's, 1 for
+// women's football)
+func importGender(value string) (string, bool) {
+	switch strings.ToLower(value) {
+	case "men", "male", "m", "0":
+		return PoolGenderMen, true
+	case "women", "female", "w", "f", "1":
+		return PoolGenderWomen, true
+	}
+	return "", false
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SyncPricesResponse struct {
+	Updated     int                `json:"updated"`
+	Skipped     int                `json:"skipped"`
+	SkippedRows []ImportSkippedRow `json:"skippedRows"`
+	Source      string             `json:"source"`
+	Dataset     string             `json:"dataset"`
+	SyncedAt    time.Time          `json:"syncedAt"`
+}
+
+// StartPriceSync syncs market prices of the default dataset from the
+// configured feed on the configured interval, if both are set
+func (h *Handler) StartPriceSync() {
+	if h.config.PriceSyncURL == "" || h.config.PriceSyncInterval <= 0 {
+		return
+	}
+
+	log.Printf("Syncing prices from %s every %s", h.config.PriceSyncURL, h.config.PriceSyncInterval)
+
+	go runEvery(h.config.PriceSyncInterval, func() {
+		if _, err := h.syncPrices(); err != nil {
+			log.Printf("Scheduled price sync error: %v", err)
+		}
+	})
+}
+
+// handlePriceSync runs a price sync right away
+func (h *Handler) handlePriceSync(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s /api/admin/prices/sync", r.Method)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response, err := h.syncPrices()
+	if err != nil {
+		log.Printf("Manual price sync error: %v", err)
+		writeStatusError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// syncPrices downloads the price feed and stores the price of every known
+// player in it. The feed is CSV or JSON with a player id (id or playerId)
+// and a price in coins per row. Players missing from the feed keep their
+// last price.
+func (h *Handler) syncPrices() (SyncPricesResponse, error) {
+	response := SyncPricesResponse{
+		SkippedRows: []ImportSkippedRow{},
+		Source:      h.config.PriceSyncURL,
+		Dataset:     h.config.PlayerDataset,
+	}
+
+	if h.config.PriceSyncURL == "" {
+		return response, newStatusError(http.StatusBadRequest, "Price sync is not configured")
+	}
+
+	if !h.playerSync.prices.TryLock() {
+		return response, newStatusError(http.StatusConflict, "A price sync is already running")
+	}
+	defer h.playerSync.prices.Unlock()
+
+	rows, err := fetchPlayerFile(h.config.PriceSyncURL)
+	if err != nil {
+		return response, err
+	}
+
+	var playerIDs []int
+	if err := h.db.Select(&playerIDs, "SELECT id FROM players WHERE dataset = $1", h.config.PlayerDataset); err != nil {
+		return response, err
+	}
+	known := make(map[int]bool, len(playerIDs))
+	for _, id := range playerIDs {
+		known[id] = true
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		return response, err
+	}
+	defer tx.Rollback()
+
+	for i, row := range rows {
+		id, price, reason := validatePriceRow(row)
+		if reason == "" && !known[*id] {
+			reason = "unknown player"
+		}
+		if reason != "" {
+			response.Skipped++
+			response.SkippedRows = append(response.SkippedRows, ImportSkippedRow{Row: i + 1, ID: id, Reason: reason})
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO player_prices (player_id, dataset, price, updated_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (player_id, dataset) DO UPDATE SET price = EXCLUDED.price, updated_at = EXCLUDED.updated_at
+		`, *id, h.config.PlayerDataset, price)
+		if err != nil {
+			return response, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		response.Updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return response, err
+	}
+	response.SyncedAt = time.Now()
+
+	if response.Updated > 0 {
+		h.playersChanged()
+	}
+
+	log.Printf("Price sync: %d updated, %d skipped", response.Updated, response.Skipped)
+
+	return response, nil
+}
+
+// validatePriceRow reads the player id and price of a feed row. It returns the
+// reason the row can't be used, or "".
+func validatePriceRow(row map[string]string) (*int, int, string) {
+	rawID := strings.TrimSpace(row["player_id"])
+	if rawID == "" {
+		rawID = strings.TrimSpace(row["id"])
+	}
+	if rawID == "" {
+		return nil, 0, "missing id"
+	}
+	id, err := strconv.Atoi(rawID)
+	if err != nil || id <= 0 {
+		return nil, 0, "id must be a positive integer"
+	}
+
+	price, err := strconv.Atoi(strings.TrimSpace(row["price"]))
+	if err != nil || price < 0 {
+		return &id, 0, "price must be a non-negative integer"
+	}
+
+	return &id, price, ""
+}
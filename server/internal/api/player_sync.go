@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"eafc-draft-server/internal/database"
+)
+
+// playerSyncTimeout bounds how long downloading the dataset may take
+const playerSyncTimeout = 2 * time.Minute
+
+// playerSyncState makes sure only one sync of each kind runs at a time
+type playerSyncState struct {
+	players sync.Mutex
+	prices  sync.Mutex
+}
+
+type SyncPlayersResponse struct {
+	ImportPlayersResponse
+	Unchanged int       `json:"unchanged"`
+	Source    string    `json:"source"`
+	Dataset   string    `json:"dataset"`
+	SyncedAt  time.Time `json:"syncedAt"`
+}
+
+// StartPlayerSync syncs the default player dataset from the configured URL on
+// the configured interval, if both are set
+func (h *Handler) StartPlayerSync() {
+	if h.config.PlayerSyncURL == "" || h.config.PlayerSyncInterval <= 0 {
+		return
+	}
+
+	log.Printf("Syncing players from %s every %s", h.config.PlayerSyncURL, h.config.PlayerSyncInterval)
+
+	go runEvery(h.config.PlayerSyncInterval, func() {
+		if _, err := h.syncPlayers(); err != nil {
+			log.Printf("Scheduled player sync error: %v", err)
+		}
+	})
+}
+
+// runEvery calls fn on every tick of interval, forever
+func runEvery(interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		fn()
+	}
+}
+
+// handlePlayerSync runs a sync right away
+func (h *Handler) handlePlayerSync(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s /api/admin/players/sync", r.Method)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response, err := h.syncPlayers()
+	if err != nil {
+		log.Printf("Manual player sync error: %v", err)
+		writeStatusError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// syncPlayers downloads the dataset, compares it with the stored players of
+// the default dataset and upserts only the players that are new or changed
+func (h *Handler) syncPlayers() (SyncPlayersResponse, error) {
+	response := SyncPlayersResponse{Source: h.config.PlayerSyncURL, Dataset: h.config.PlayerDataset}
+
+	if h.config.PlayerSyncURL == "" {
+		return response, newStatusError(http.StatusBadRequest, "Player sync is not configured")
+	}
+
+	if !h.playerSync.players.TryLock() {
+		return response, newStatusError(http.StatusConflict, "A player sync is already running")
+	}
+	defer h.playerSync.players.Unlock()
+
+	rows, err := fetchPlayerFile(h.config.PlayerSyncURL)
+	if err != nil {
+		return response, err
+	}
+
+	var existing []database.Player
+	if err := h.db.Select(&existing, "SELECT * FROM players WHERE dataset = $1", h.config.PlayerDataset); err != nil {
+		return response, err
+	}
+	existingByID := make(map[int]database.Player, len(existing))
+	for _, player := range existing {
+		existingByID[player.ID] = player
+	}
+
+	known := make(map[string]bool)
+	for _, column := range database.GetPlayerColumns() {
+		known[column] = true
+	}
+	numberColumns := database.GetNumberColumns()
+
+	// Invalid rows go through too so they are reported as skipped
+	var changed []map[string]string
+	for _, row := range rows {
+		values, id, reason := validateImportRow(row, known, numberColumns)
+		if reason == "" {
+			if player, ok := existingByID[*id]; ok && playerMatches(player, values) {
+				response.Unchanged++
+				continue
+			}
+		}
+		changed = append(changed, row)
+	}
+
+	imported, err := h.upsertPlayers(changed, h.config.PlayerDataset)
+	if err != nil {
+		return response, err
+	}
+	response.ImportPlayersResponse = imported
+	response.SyncedAt = time.Now()
+
+	log.Printf("Player sync: %d inserted, %d updated, %d unchanged, %d skipped",
+		imported.Inserted, imported.Updated, response.Unchanged, imported.Skipped)
+
+	return response, nil
+}
+
+// fetchPlayerFile downloads a CSV or JSON file and parses it into rows keyed
+// by column, like an uploaded import
+func fetchPlayerFile(fileURL string) ([]map[string]string, error) {
+	client := &http.Client{Timeout: playerSyncTimeout}
+	resp, err := client.Get(fileURL)
+	if err != nil {
+		return nil, newStatusError(http.StatusBadGateway, "Failed to download "+fileURL+": "+err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(http.StatusBadGateway, fmt.Sprintf("Download of %s returned %s", fileURL, resp.Status))
+	}
+
+	rows, err := parsePlayerFile(io.LimitReader(resp.Body, maxImportSize), syncFormat(fileURL, resp.Header.Get("Content-Type")))
+	if err != nil {
+		return nil, newStatusError(http.StatusBadGateway, "Invalid file at "+fileURL+": "+err.Error())
+	}
+	return rows, nil
+}
+
+// syncFormat picks the dataset format from the response type or URL
+func syncFormat(rawURL, contentType string) string {
+	if strings.Contains(contentType, "json") {
+		return "json"
+	}
+	if parsed, err := url.Parse(rawURL); err == nil && strings.EqualFold(path.Ext(parsed.Path), ".json") {
+		return "json"
+	}
+	return "csv"
+}
+
+// playerMatches reports whether every value of an incoming row equals the
+// stored player
+func playerMatches(player database.Player, values map[string]interface{}) bool {
+	for column, value := range values {
+		stored := player.ColumnValue(column)
+		if date, ok := value.(time.Time); ok {
+			if storedDate, ok := stored.(time.Time); !ok || !storedDate.Equal(date) {
+				return false
+			}
+		} else if stored != value {
+			return false
+		}
+	}
+	return true
+}
@@ -1,20 +1,33 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// listedPlayers stands in for the players table in player listings. It adds
+// each player's latest market price and their age, so both can be filtered
+// and sorted on like stored columns.
+const listedPlayers = `(
+	SELECT players.*, pp.price, pp.updated_at AS price_updated_at,
+	       date_part('year', age(players.birthdate))::int AS age
+	FROM players
+	LEFT JOIN player_prices pp ON pp.player_id = players.id AND pp.dataset = players.dataset
+)`
+
 type GetPlayersResponse struct {
-	Players    []database.Player `json:"players"`
-	Pagination *Pagination       `json:"pagination"`
+	Players    interface{} `json:"players"` // []database.Player, or field maps with fields=
+	Pagination *Pagination `json:"pagination"`
 }
 
 type Pagination struct {
@@ -24,6 +37,9 @@ type Pagination struct {
 	TotalPages  int  `json:"totalPages"`
 	HasNext     bool `json:"hasNext"`
 	HasPrevious bool `json:"hasPrevious"`
+	// NextCursor is set in cursor mode (?after=) while more results follow.
+	// Page is 0 in cursor mode.
+	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
 type RangeParam struct {
@@ -39,6 +55,7 @@ type GetPlayerEnumsResponse struct {
 	Positions            []string              `json:"positions"`
 	PlayerAbilities      []string              `json:"playerAbilities"`
 	PreferredFootOptions []PreferredFootOption `json:"preferredFootOptions"`
+	PositionGroups       map[string][]string   `json:"positionGroups"` // position_group values and the labels they cover
 }
 
 type PreferredFootOption struct {
@@ -83,6 +100,31 @@ func (h *Handler) parseRangeParam(value string) RangeParam {
 	return result
 }
 
+type sortKey struct {
+	column    string
+	direction string
+}
+
+// parseSortParam reads a column:direction list such as
+// overall_rating:desc,stat_pac:desc. The direction defaults to desc.
+func parseSortParam(value string, validColumns map[string]bool) ([]sortKey, error) {
+	var keys []sortKey
+	for _, part := range strings.Split(value, ",") {
+		column, direction, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if direction == "" {
+			direction = "desc"
+		}
+		if !validColumns[column] {
+			return nil, fmt.Errorf("cannot sort by %q", column)
+		}
+		if direction != "asc" && direction != "desc" {
+			return nil, fmt.Errorf("sort direction must be asc or desc")
+		}
+		keys = append(keys, sortKey{column: column, direction: direction})
+	}
+	return keys, nil
+}
+
 func (h *Handler) getPlayers(w http.ResponseWriter, r *http.Request) {
 	log.Printf("GET /api/players - Query params: %v", r.URL.Query())
 
@@ -92,6 +134,12 @@ func (h *Handler) getPlayers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	fields, err := parsePlayerFields(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
 	// Parse pagination parameters
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	if page < 1 {
@@ -124,7 +172,8 @@ func (h *Handler) getPlayers(w http.ResponseWriter, r *http.Request) {
 		"stat_pas": true, "stat_penalties": true, "stat_phy": true, "stat_positioning": true,
 		"stat_reactions": true, "stat_sho": true, "stat_short_passing": true, "stat_shot_power": true,
 		"stat_sliding_tackle": true, "stat_sprint_speed": true, "stat_standing_tackle": true,
-		"stat_vision": true, "stat_volleys": true,
+		"stat_vision": true, "stat_volleys": true, "price": true,
+		"birthdate": true, "age": true, "height": true, "weight": true,
 	}
 
 	// Default sorting
@@ -140,9 +189,162 @@ func (h *Handler) getPlayers(w http.ResponseWriter, r *http.Request) {
 		sortDirection = "desc"
 	}
 
+	// sort=overall_rating:desc,stat_pac:desc sorts by several columns and
+	// takes precedence over sort_by and sort_direction
+	orderTerms := []string{sortBy + " " + strings.ToUpper(sortDirection)}
+	if sortParam := r.URL.Query().Get("sort"); sortParam != "" {
+		keys, err := parseSortParam(sortParam, validColumns)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sortBy, sortDirection = keys[0].column, keys[0].direction
+		orderTerms = orderTerms[:0]
+		for _, key := range keys {
+			orderTerms = append(orderTerms, key.column+" "+strings.ToUpper(key.direction))
+		}
+	}
+
 	// Build ORDER BY clause with consistent secondary sort
-	orderClause := fmt.Sprintf("ORDER BY %s %s, id ASC", sortBy, strings.ToUpper(sortDirection))
+	orderClause := "ORDER BY " + strings.Join(orderTerms, ", ") + ", id ASC"
+
+	// Keyset pagination: an empty after= starts at the top, later pages pass
+	// the previous response's nextCursor. NULLs sort last so every row has a
+	// stable position.
+	cursorMode := r.URL.Query().Has("after")
+	if cursorMode && len(orderTerms) > 1 {
+		http.Error(w, "Cursor pagination only supports a single sort column", http.StatusBadRequest)
+		return
+	}
+	var cursor *playerCursor
+	if after := r.URL.Query().Get("after"); after != "" {
+		decoded, err := decodePlayerCursor(after)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if decoded.SortBy != sortBy || decoded.SortDirection != sortDirection {
+			http.Error(w, "Cursor was made for a different sort order", http.StatusBadRequest)
+			return
+		}
+		cursor = &decoded
+	}
+	if cursorMode {
+		orderClause = fmt.Sprintf("ORDER BY %s %s NULLS LAST, id ASC", sortBy, strings.ToUpper(sortDirection))
+	}
+
+	conditions, args, argIndex, err := h.playerFilterConditions(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	baseQuery := "FROM " + listedPlayers + " players"
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Get total count
+	countQuery := "SELECT COUNT(*) " + baseQuery + whereClause
+	log.Printf("Count query: %s, args: %v", countQuery, args)
+	var totalCount int
+	err = h.db.Get(&totalCount, countQuery, args...)
+	if err != nil {
+		log.Printf("Count query error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Total count: %d", totalCount)
+
+	// Get paginated results
+	var query string
+	if cursorMode {
+		// Fetch one extra row to know whether there is a next page
+		if cursor != nil {
+			condition, cursorArgs, nextArgIndex := playerCursorCondition(*cursor, argIndex)
+			conditions = append(conditions, condition)
+			args = append(args, cursorArgs...)
+			argIndex = nextArgIndex
+		}
+		whereClause = ""
+		if len(conditions) > 0 {
+			whereClause = " WHERE " + strings.Join(conditions, " AND ")
+		}
+		query = "SELECT * " + baseQuery + whereClause + " " + orderClause + " LIMIT $" + strconv.Itoa(argIndex)
+		args = append(args, limit+1)
+	} else {
+		query = "SELECT * " + baseQuery + whereClause + " " + orderClause + " LIMIT $" + strconv.Itoa(argIndex) + " OFFSET $" + strconv.Itoa(argIndex+1)
+		args = append(args, limit, offset)
+	}
+	log.Printf("Main query: %s, args: %v", query, args)
+
+	var players []database.Player
+	err = h.db.Select(&players, query, args...)
+	if err != nil {
+		log.Printf("Main query error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Found %d players", len(players))
+
+	// Calculate pagination info
+	totalPages := (totalCount + limit - 1) / limit
+	hasNext := page < totalPages
+	hasPrevious := page > 1
+
+	var nextCursor *string
+	if cursorMode {
+		page = 0
+		hasNext = len(players) > limit
+		hasPrevious = cursor != nil
+		if hasNext {
+			players = players[:limit]
+			last := players[len(players)-1]
+			next := encodePlayerCursor(playerCursor{
+				SortBy:        sortBy,
+				SortDirection: sortDirection,
+				Value:         last.ColumnValue(sortBy),
+				ID:            last.ID,
+			})
+			nextCursor = &next
+		}
+	}
+
+	response := GetPlayersResponse{
+		Players: selectPlayerFields(players, fields),
+		Pagination: &Pagination{
+			Page:        page,
+			Limit:       limit,
+			TotalItems:  totalCount,
+			TotalPages:  totalPages,
+			HasNext:     hasNext,
+			HasPrevious: hasPrevious,
+			NextCursor:  nextCursor,
+		},
+	}
+
+	// Draft-scoped listings change with every pick, so only the ETag applies
+	var lastModified *time.Time
+	if r.URL.Query().Get("draft") == "" {
+		updatedAt := h.playerData.lastModified()
+		lastModified = &updatedAt
+	}
+	writeCachableJSON(w, r, response, lastModified)
+}
+
+// nonFilterParams are the player listing parameters that aren't column filters
+var nonFilterParams = map[string]bool{
+	"page": true, "limit": true, "exclude_gk": true, "sort_by": true, "sort_direction": true, "sort": true,
+	"draft": true, "available": true, "after": true, "dataset": true, "count": true,
+	"exclude_positions": true, "fields": true, "per_position": true, "max_rating": true,
+	"filter": true,
+}
 
+// playerFilterConditions builds the WHERE conditions for the column filters,
+// draft scoping and dataset of a player listing request. It returns the next
+// free placeholder index.
+func (h *Handler) playerFilterConditions(r *http.Request) ([]string, []interface{}, int, error) {
 	// Get number columns from the model
 	numberColumns := database.GetNumberColumns()
 
@@ -157,10 +359,12 @@ func (h *Handler) getPlayers(w http.ResponseWriter, r *http.Request) {
 		"league_name":             true,
 		"nationality_label":       true,
 		"player_abilities_labels": true,
+		"gender":                  true,
+		"card_type":               true,
 	}
 
 	for key, values := range r.URL.Query() {
-		if len(values) > 0 && values[0] != "" && key != "page" && key != "limit" && key != "exclude_gk" && key != "sort_by" && key != "sort_direction" {
+		if len(values) > 0 && values[0] != "" && !nonFilterParams[key] {
 			value := values[0]
 
 			if key == "name" {
@@ -176,6 +380,20 @@ func (h *Handler) getPlayers(w http.ResponseWriter, r *http.Request) {
 				args = append(args, "%"+value+"%")
 				argIndex++
 
+			} else if key == "position_group" {
+				// Expand groups such as DEF to their position labels
+				var labels []string
+				for _, group := range strings.Split(value, ",") {
+					group = strings.ToUpper(strings.TrimSpace(group))
+					if _, ok := positionGroups[group]; !ok {
+						return nil, nil, 0, newStatusError(http.StatusBadRequest, "position_group must be GK, DEF, MID or FWD")
+					}
+					labels = append(labels, positionGroups[group]...)
+				}
+				conditions = append(conditions, fmt.Sprintf("position_short_label = ANY($%d)", argIndex))
+				args = append(args, pq.StringArray(labels))
+				argIndex++
+
 			} else if numberColumns[key] {
 				// Handle special case for ID with 'in:' syntax
 				if key == "id" && strings.HasPrefix(value, "in:") {
@@ -265,59 +483,108 @@ func (h *Handler) getPlayers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	baseQuery := "FROM players"
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	// filter= combines conditions across columns with AND, OR and NOT
+	if expression := r.URL.Query().Get("filter"); expression != "" {
+		condition, filterArgs, nextArgIndex, err := parseFilterExpression(expression, argIndex)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		conditions = append(conditions, condition)
+		args = append(args, filterArgs...)
+		argIndex = nextArgIndex
 	}
 
-	// Get total count
-	countQuery := "SELECT COUNT(*) " + baseQuery + whereClause
-	log.Printf("Count query: %s, args: %v", countQuery, args)
-	var totalCount int
-	err := h.db.Get(&totalCount, countQuery, args...)
-	if err != nil {
-		log.Printf("Count query error: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+	// Leave out players by main position, e.g. exclude_gk=true or
+	// exclude_positions=GK,CB for outfield-only searches
+	var excluded []string
+	if r.URL.Query().Get("exclude_gk") == "true" {
+		excluded = append(excluded, "GK")
 	}
-	log.Printf("Total count: %d", totalCount)
-
-	// Get paginated results
-	query := "SELECT * " + baseQuery + whereClause + " " + orderClause + " LIMIT $" + strconv.Itoa(argIndex) + " OFFSET $" + strconv.Itoa(argIndex+1)
-	args = append(args, limit, offset)
-	log.Printf("Main query: %s, args: %v", query, args)
-
-	var players []database.Player
-	err = h.db.Select(&players, query, args...)
-	if err != nil {
-		log.Printf("Main query error: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+	for _, position := range strings.Split(r.URL.Query().Get("exclude_positions"), ",") {
+		if position = strings.ToUpper(strings.TrimSpace(position)); position != "" {
+			excluded = append(excluded, position)
+		}
 	}
-	log.Printf("Found %d players", len(players))
-
-	// Calculate pagination info
-	totalPages := (totalCount + limit - 1) / limit
-	hasNext := page < totalPages
-	hasPrevious := page > 1
+	if len(excluded) > 0 {
+		conditions = append(conditions, fmt.Sprintf("COALESCE(position_short_label, '') <> ALL($%d)", argIndex))
+		args = append(args, pq.StringArray(excluded))
+		argIndex++
+	}
+
+	// Scope the listing to a draft's player pool
+	if draftCode := r.URL.Query().Get("draft"); draftCode != "" {
+		var draft database.Draft
+		err := h.db.Get(&draft, `
+			SELECT `+database.DraftColumns+`
+			FROM drafts WHERE code = $1
+		`, draftCode)
+		if err != nil {
+			log.Printf("Get draft for player listing error: %v", err)
+			return nil, nil, 0, newStatusError(http.StatusNotFound, "Draft not found")
+		}
 
-	response := GetPlayersResponse{
-		Players: players,
-		Pagination: &Pagination{
-			Page:        page,
-			Limit:       limit,
-			TotalItems:  totalCount,
-			TotalPages:  totalPages,
-			HasNext:     hasNext,
-			HasPrevious: hasPrevious,
-		},
+		poolConditions, poolArgs, nextArgIndex := draftPoolConditions(draft, argIndex)
+		conditions = append(conditions, poolConditions...)
+		args = append(args, poolArgs...)
+		argIndex = nextArgIndex
+
+		// Hide players that are already picked or kept by someone
+		if r.URL.Query().Get("available") == "true" {
+			conditions = append(conditions, fmt.Sprintf(`NOT EXISTS (
+				SELECT 1 FROM draft_picks dp WHERE dp.draft_id = $%d AND dp.player_id = players.id
+			) AND NOT EXISTS (
+				SELECT 1 FROM draft_keepers k WHERE k.draft_id = $%d AND k.player_id = players.id
+			)`, argIndex, argIndex))
+			args = append(args, draft.ID)
+			argIndex++
+		}
+	} else if r.URL.Query().Get("available") == "true" {
+		return nil, nil, 0, newStatusError(http.StatusBadRequest, "available requires a draft")
+	} else {
+		dataset, err := h.requestedDataset(r)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		conditions = append(conditions, fmt.Sprintf("dataset = $%d", argIndex))
+		args = append(args, dataset)
+		argIndex++
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return conditions, args, argIndex, nil
 }
 
+// minSearchSimilarity is the lowest trigram similarity a fuzzy search match
+// needs. Lower lets more typos through at the cost of noise.
+const minSearchSimilarity = 0.4
+
+// searchRankExpression scores a player against the search text in $1.
+// An exact alias such as CR7 scores 3, above any name match. Full-text
+// matches on the stored search_vector score above 1 by their ts_rank,
+// accent-insensitive substring matches of names or aliases score 1 and
+// anything else scores its trigram word similarity against the player's names.
+const searchRankExpression = `CASE
+	WHEN EXISTS (
+		SELECT 1 FROM player_aliases pa
+		WHERE pa.player_id = players.id AND lower(unaccent(pa.alias)) = lower(unaccent($1))
+	)
+	THEN 3.0
+	WHEN search_vector @@ plainto_tsquery('simple', unaccent($1))
+	THEN 1.0 + ts_rank(search_vector, plainto_tsquery('simple', unaccent($1)))
+	WHEN unaccent(COALESCE(common_name, '')) ILIKE unaccent('%' || $1 || '%')
+	  OR unaccent(COALESCE(first_name, '')) ILIKE unaccent('%' || $1 || '%')
+	  OR unaccent(COALESCE(last_name, '')) ILIKE unaccent('%' || $1 || '%')
+	  OR unaccent(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')) ILIKE unaccent('%' || $1 || '%')
+	  OR EXISTS (
+		SELECT 1 FROM player_aliases pa
+		WHERE pa.player_id = players.id AND unaccent(pa.alias) ILIKE unaccent('%' || $1 || '%')
+	  )
+	THEN 1.0
+	ELSE word_similarity(
+		lower(unaccent($1)),
+		lower(unaccent(concat_ws(' ', common_name, first_name, last_name)))
+	)
+END`
+
 func (h *Handler) searchPlayers(w http.ResponseWriter, r *http.Request) {
 	log.Printf("GET /api/players/search - Query params: %v", r.URL.Query())
 
@@ -335,6 +602,12 @@ func (h *Handler) searchPlayers(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("Search query: %s", query)
 
+	fields, err := parsePlayerFields(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
 	// Parse pagination
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	if page < 1 {
@@ -348,38 +621,39 @@ func (h *Handler) searchPlayers(w http.ResponseWriter, r *http.Request) {
 
 	offset := (page - 1) * limit
 
-	// Use ILIKE-based search for better partial matching
-	// This handles partial names much better than full-text search
-	searchPattern := "%" + query + "%"
+	dataset, err := h.requestedDataset(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
 
+	// Full-text and substring matches come first, then trigram similarity
+	// catches typos such as "mbape" or "halland". Results are ranked by
+	// relevance, then rating.
 	searchQuery := `
-		SELECT *
-		FROM players 
-		WHERE (
-			unaccent(COALESCE(common_name, '')) ILIKE unaccent($1) OR
-			unaccent(COALESCE(first_name, '')) ILIKE unaccent($1) OR  
-			unaccent(COALESCE(last_name, '')) ILIKE unaccent($1) OR
-			unaccent(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')) ILIKE unaccent($1)
-		)
-		ORDER BY overall_rating DESC, id ASC
-		LIMIT $2 OFFSET $3
+		SELECT * FROM (
+			SELECT players.*, ` + searchRankExpression + ` AS rank
+			FROM ` + listedPlayers + ` players
+			WHERE dataset = $5
+		) ranked
+		WHERE rank >= $2
+		ORDER BY rank DESC, overall_rating DESC, id ASC
+		LIMIT $3 OFFSET $4
 	`
 
 	countQuery := `
-		SELECT COUNT(*) 
-		FROM players 
-		WHERE (
-			unaccent(COALESCE(common_name, '')) ILIKE unaccent($1) OR
-			unaccent(COALESCE(first_name, '')) ILIKE unaccent($1) OR  
-			unaccent(COALESCE(last_name, '')) ILIKE unaccent($1) OR
-			unaccent(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')) ILIKE unaccent($1)
-		)
+		SELECT COUNT(*) FROM (
+			SELECT ` + searchRankExpression + ` AS rank
+			FROM players
+			WHERE dataset = $3
+		) ranked
+		WHERE rank >= $2
 	`
 
 	// Get total count
-	log.Printf("Count query: %s, args: [%s]", countQuery, searchPattern)
+	log.Printf("Count query: %s, args: [%s]", countQuery, query)
 	var totalCount int
-	err := h.db.Get(&totalCount, countQuery, searchPattern)
+	err = h.db.Get(&totalCount, countQuery, query, minSearchSimilarity, dataset)
 	if err != nil {
 		log.Printf("Count query error: %v", err)
 		http.Error(w, "Database error", http.StatusInternalServerError)
@@ -388,9 +662,9 @@ func (h *Handler) searchPlayers(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Search total count: %d", totalCount)
 
 	// Get search results
-	log.Printf("Search query: %s, args: [%s, %d, %d]", searchQuery, searchPattern, limit, offset)
+	log.Printf("Search query: %s, args: [%s, %d, %d]", searchQuery, query, limit, offset)
 	var players []database.Player
-	err = h.db.Select(&players, searchQuery, searchPattern, limit, offset)
+	err = h.db.Select(&players, searchQuery, query, minSearchSimilarity, limit, offset, dataset)
 	if err != nil {
 		log.Printf("Search query error: %v", err)
 		http.Error(w, "Database error", http.StatusInternalServerError)
@@ -404,7 +678,7 @@ func (h *Handler) searchPlayers(w http.ResponseWriter, r *http.Request) {
 	hasPrevious := page > 1
 
 	response := GetPlayersResponse{
-		Players: players,
+		Players: selectPlayerFields(players, fields),
 		Pagination: &Pagination{
 			Page:        page,
 			Limit:       limit,
@@ -415,8 +689,8 @@ func (h *Handler) searchPlayers(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	lastModified := h.playerData.lastModified()
+	writeCachableJSON(w, r, response, &lastModified)
 }
 
 func (h *Handler) getPlayerEnums(w http.ResponseWriter, r *http.Request) {
@@ -428,48 +702,55 @@ func (h *Handler) getPlayerEnums(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response, err := h.playerEnums()
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	lastModified := h.playerData.lastModified()
+	writeCachableJSON(w, r, response, &lastModified)
+}
+
+// loadPlayerEnums collects the distinct filter values across all player datasets
+func loadPlayerEnums(db *sqlx.DB) (GetPlayerEnumsResponse, error) {
 	// Get distinct nationalities
 	var nationalities []string
-	err := h.db.Select(&nationalities, "SELECT DISTINCT nationality_label FROM players WHERE nationality_label IS NOT NULL ORDER BY nationality_label")
+	err := db.Select(&nationalities, "SELECT DISTINCT nationality_label FROM players WHERE nationality_label IS NOT NULL ORDER BY nationality_label")
 	if err != nil {
 		log.Printf("Error fetching nationalities: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return GetPlayerEnumsResponse{}, err
 	}
 
 	// Get distinct leagues
 	var leagues []string
-	err = h.db.Select(&leagues, "SELECT DISTINCT league_name FROM players WHERE league_name IS NOT NULL ORDER BY league_name")
+	err = db.Select(&leagues, "SELECT DISTINCT league_name FROM players WHERE league_name IS NOT NULL ORDER BY league_name")
 	if err != nil {
 		log.Printf("Error fetching leagues: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return GetPlayerEnumsResponse{}, err
 	}
 
 	// Get distinct clubs
 	var clubs []string
-	err = h.db.Select(&clubs, "SELECT DISTINCT team_label FROM players WHERE team_label IS NOT NULL ORDER BY team_label")
+	err = db.Select(&clubs, "SELECT DISTINCT team_label FROM players WHERE team_label IS NOT NULL ORDER BY team_label")
 	if err != nil {
 		log.Printf("Error fetching clubs: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return GetPlayerEnumsResponse{}, err
 	}
 
 	// Get distinct positions (both main and alternate)
 	var mainPositions []string
-	err = h.db.Select(&mainPositions, "SELECT DISTINCT position_short_label FROM players WHERE position_short_label IS NOT NULL ORDER BY position_short_label")
+	err = db.Select(&mainPositions, "SELECT DISTINCT position_short_label FROM players WHERE position_short_label IS NOT NULL ORDER BY position_short_label")
 	if err != nil {
 		log.Printf("Error fetching main positions: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return GetPlayerEnumsResponse{}, err
 	}
 
 	var alternatePositionsData []string
-	err = h.db.Select(&alternatePositionsData, "SELECT DISTINCT alternate_positions FROM players WHERE alternate_positions IS NOT NULL AND alternate_positions != ''")
+	err = db.Select(&alternatePositionsData, "SELECT DISTINCT alternate_positions FROM players WHERE alternate_positions IS NOT NULL AND alternate_positions != ''")
 	if err != nil {
 		log.Printf("Error fetching alternate positions: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return GetPlayerEnumsResponse{}, err
 	}
 
 	// Parse pipe-separated alternate positions
@@ -495,11 +776,10 @@ func (h *Handler) getPlayerEnums(w http.ResponseWriter, r *http.Request) {
 
 	// Get distinct player abilities
 	var playerAbilitiesData []string
-	err = h.db.Select(&playerAbilitiesData, "SELECT DISTINCT player_abilities_labels FROM players WHERE player_abilities_labels IS NOT NULL AND player_abilities_labels != ''")
+	err = db.Select(&playerAbilitiesData, "SELECT DISTINCT player_abilities_labels FROM players WHERE player_abilities_labels IS NOT NULL AND player_abilities_labels != ''")
 	if err != nil {
 		log.Printf("Error fetching player abilities: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return GetPlayerEnumsResponse{}, err
 	}
 
 	// Parse pipe-separated player abilities
@@ -533,8 +813,33 @@ func (h *Handler) getPlayerEnums(w http.ResponseWriter, r *http.Request) {
 		Positions:            allPositions,
 		PlayerAbilities:      allAbilities,
 		PreferredFootOptions: preferredFootOptions,
+		PositionGroups:       positionGroups,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return response, nil
+}
+
+func (h *Handler) handlePlayerOperations(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s", r.Method, r.URL.Path)
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/players/"), "/")
+	parts := strings.Split(path, "/")
+
+	playerID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "similar" {
+		// /api/players/{id}/similar
+		switch r.Method {
+		case http.MethodGet:
+			h.getSimilarPlayers(w, r, playerID)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	} else {
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
 }
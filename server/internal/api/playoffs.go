@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+)
+
+const defaultPlayoffTeams = 4
+
+type StartPlayoffsRequest struct {
+	AdminName string `json:"adminName"`
+	Teams     int    `json:"teams"` // top N of the table go through, default 4
+}
+
+type StartPlayoffsResponse struct {
+	Bracket []database.KnockoutTie `json:"bracket"`
+}
+
+// startPlayoffs ends the league phase of a league tournament and seeds a
+// playoff bracket from the top of the table, 1 v 4 and 2 v 3 for four teams.
+// Unplayed league fixtures are left unplayed, and from then on results
+// settle playoff ties.
+func (h *Handler) startPlayoffs(w http.ResponseWriter, r *http.Request, code string) {
+	var req StartPlayoffsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Start playoffs decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Teams == 0 {
+		req.Teams = defaultPlayoffTeams
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for playoffs error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can start the playoffs", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.Status != "tournament" || draft.TournamentFormat != TournamentFormatLeague {
+		http.Error(w, "Playoffs follow the league phase of a league tournament", http.StatusBadRequest)
+		return
+	}
+
+	if req.Teams < 2 || req.Teams > draft.ParticipantCount {
+		http.Error(w, fmt.Sprintf("teams must be between 2 and %d", draft.ParticipantCount), http.StatusBadRequest)
+		return
+	}
+
+	started, err := inKnockoutPhase(tx, draft)
+	if err != nil {
+		log.Printf("Check knockout phase error: %v", err)
+		http.Error(w, "Failed to start playoffs", http.StatusInternalServerError)
+		return
+	}
+	if started {
+		http.Error(w, "The playoffs have already started", http.StatusConflict)
+		return
+	}
+
+	seeded, err := h.seedTeams(tx, draft, SeedingStandings)
+	if err == nil {
+		err = createBracket(tx, draft.ID, seeded[:req.Teams])
+	}
+	if err != nil {
+		log.Printf("Create playoff bracket error: %v", err)
+		http.Error(w, "Failed to start playoffs", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventKnockoutStarted, req.AdminName, nil); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to start playoffs", http.StatusInternalServerError)
+		return
+	}
+
+	bracket, err := loadBracket(tx, draft.ID)
+	if err != nil {
+		log.Printf("Get playoff bracket error: %v", err)
+		http.Error(w, "Failed to start playoffs", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to start playoffs", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Started playoffs for draft %s with the top %d", code, req.Teams)
+
+	if h.broadcastFunc != nil {
+		BroadcastTournamentStateToRoom(h.db, code)
+	}
+
+	response := StartPlayoffsResponse{
+		Bracket: bracket,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/lib/pq"
+)
+
+// Pool genders: which football a draft's players come from
+const (
+	PoolGenderMen   = "men"
+	PoolGenderWomen = "women"
+	PoolGenderMixed = "mixed"
+)
+
+// Card types. Icons and heroes are special cards drafts can ban.
+const (
+	CardTypeStandard = "standard"
+	CardTypeIcon     = "icon"
+	CardTypeHero     = "hero"
+)
+
+func isValidCardType(cardType string) bool {
+	return cardType == CardTypeStandard || cardType == CardTypeIcon || cardType == CardTypeHero
+}
+
+func isValidPoolGender(gender string) bool {
+	return gender == PoolGenderMen || gender == PoolGenderWomen || gender == PoolGenderMixed
+}
+
+// checkDraftPool returns an error if a player is outside the draft's pool
+func checkDraftPool(draft database.Draft, player database.Player) error {
+	if draft.PoolGender != PoolGenderMixed && player.Gender != draft.PoolGender {
+		return fmt.Errorf("this draft only allows %s's football players", draft.PoolGender)
+	}
+
+	if draft.BanSpecialCards && player.CardType != CardTypeStandard {
+		return fmt.Errorf("this draft bans Icons and Heroes")
+	}
+
+	if len(draft.PoolLeagues) > 0 && (player.LeagueName == nil || !containsString(draft.PoolLeagues, *player.LeagueName)) {
+		return fmt.Errorf("this draft only allows players from: %s", strings.Join(draft.PoolLeagues, ", "))
+	}
+
+	if len(draft.PoolNationalities) > 0 && (player.NationalityLabel == nil || !containsString(draft.PoolNationalities, *player.NationalityLabel)) {
+		return fmt.Errorf("this draft only allows players from: %s", strings.Join(draft.PoolNationalities, ", "))
+	}
+
+	return nil
+}
+
+// draftPoolConditions returns the WHERE conditions restricting a player query
+// to a draft's dataset and pool, numbering placeholders from argIndex
+func draftPoolConditions(draft database.Draft, argIndex int) ([]string, []interface{}, int) {
+	conditions := []string{fmt.Sprintf("dataset = $%d", argIndex)}
+	args := []interface{}{draft.Dataset}
+	argIndex++
+
+	if draft.PoolGender != PoolGenderMixed {
+		conditions = append(conditions, fmt.Sprintf("gender = $%d", argIndex))
+		args = append(args, draft.PoolGender)
+		argIndex++
+	}
+
+	if draft.BanSpecialCards {
+		conditions = append(conditions, "card_type = '"+CardTypeStandard+"'")
+	}
+
+	if len(draft.PoolLeagues) > 0 {
+		conditions = append(conditions, fmt.Sprintf("league_name = ANY($%d)", argIndex))
+		args = append(args, pq.StringArray(draft.PoolLeagues))
+		argIndex++
+	}
+
+	if len(draft.PoolNationalities) > 0 {
+		conditions = append(conditions, fmt.Sprintf("nationality_label = ANY($%d)", argIndex))
+		args = append(args, pq.StringArray(draft.PoolNationalities))
+		argIndex++
+	}
+
+	return conditions, args, argIndex
+}
+
+// cleanStringList trims values and drops empty ones
+func cleanStringList(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// positionLabelsArray returns the position labels of a group as a query argument
+func positionLabelsArray(group string) pq.StringArray {
+	return pq.StringArray(positionGroups[group])
+}
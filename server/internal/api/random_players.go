@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+)
+
+const maxRandomPlayers = 20
+
+type GetRandomPlayersResponse struct {
+	Players interface{} `json:"players"` // []database.Player, or field maps with fields=
+}
+
+// getRandomPlayers returns count (default 1) random players matching the same
+// filters as the player listing, for wheel-spin style challenge drafts
+func (h *Handler) getRandomPlayers(w http.ResponseWriter, r *http.Request) {
+	log.Printf("GET /api/players/random - Query params: %v", r.URL.Query())
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	count := 1
+	if value := r.URL.Query().Get("count"); value != "" {
+		var err error
+		if count, err = strconv.Atoi(value); err != nil || count < 1 || count > maxRandomPlayers {
+			http.Error(w, "count must be between 1 and "+strconv.Itoa(maxRandomPlayers), http.StatusBadRequest)
+			return
+		}
+	}
+
+	fields, err := parsePlayerFields(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	conditions, args, argIndex, err := h.playerFilterConditions(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := "SELECT * FROM " + listedPlayers + " players" + whereClause +
+		" ORDER BY random() LIMIT $" + strconv.Itoa(argIndex)
+	args = append(args, count)
+
+	players := []database.Player{}
+	if err := h.db.Select(&players, query, args...); err != nil {
+		log.Printf("Random players query error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(players) == 0 {
+		http.Error(w, "No players match the filters", http.StatusNotFound)
+		return
+	}
+
+	// Every request is a new spin, so it must never be served from a cache
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GetRandomPlayersResponse{Players: selectPlayerFields(players, fields)})
+}
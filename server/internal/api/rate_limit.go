@@ -0,0 +1,49 @@
+package api
+
+import "time"
+
+// Inbound websocket messages are limited per connection to a burst of
+// wsMessageBurst, refilling at wsMessagesPerSecond. A client whose messages
+// keep getting dropped is disconnected once wsMaxDroppedMessages are within
+// wsDropWindow, so occasional bursts over a long session don't add up.
+const (
+	wsMessageBurst       = 20
+	wsMessagesPerSecond  = 5
+	wsMaxDroppedMessages = 50
+	wsDropWindow         = time.Minute
+	wsMaxMessageSize     = 8 * 1024
+)
+
+// tokenBucket is a rate limiter allowing bursts of up to capacity events,
+// refilled at rate per second. It is not safe for concurrent use.
+type tokenBucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64
+	last     time.Time
+}
+
+func newTokenBucket(capacity, rate float64) *tokenBucket {
+	return &tokenBucket{
+		tokens:   capacity,
+		capacity: capacity,
+		rate:     rate,
+		last:     time.Now(),
+	}
+}
+
+// allow takes a token if one is left
+func (b *tokenBucket) allow() bool {
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
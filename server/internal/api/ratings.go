@@ -0,0 +1,230 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Elo settings: everyone starts at 1500 and a game moves at most 32 points
+const (
+	initialRating = 1500.0
+	ratingK       = 32.0
+
+	defaultRatingsLimit = 50
+	maxRatingsLimit     = 500
+)
+
+const ratingColumns = `name, rating, games_played, wins, draws, losses, updated_at`
+
+type GetRatingsResponse struct {
+	Ratings []database.Rating `json:"ratings"`
+}
+
+// ratingKey is the identity of a participant across drafts: their name,
+// ignoring case and surrounding spaces
+func ratingKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// expectedScore is the score a player rated rating should average against an
+// opponent rated opponentRating, between 0 and 1
+func expectedScore(rating, opponentRating float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponentRating-rating)/400))
+}
+
+// applyRatings updates both teams' server-wide Elo ratings for a confirmed
+// match. The changes are kept per match so an edit can take them back.
+// Walkovers, bots and mock drafts don't count.
+func applyRatings(tx *sqlx.Tx, match database.Match) error {
+	if match.Walkover {
+		return nil
+	}
+
+	var skip bool
+	err := tx.Get(&skip, `
+		SELECT d.is_mock OR h.is_bot OR a.is_bot
+		FROM drafts d
+		JOIN draft_participants h ON h.id = $2
+		JOIN draft_participants a ON a.id = $3
+		WHERE d.id = $1
+	`, match.DraftID, match.HomeTeamID, match.AwayTeamID)
+	if err != nil || skip {
+		return err
+	}
+
+	homeKey, awayKey := ratingKey(match.HomeTeamName), ratingKey(match.AwayTeamName)
+	if homeKey == awayKey {
+		return nil
+	}
+	for _, name := range []string{match.HomeTeamName, match.AwayTeamName} {
+		_, err := tx.Exec(`
+			INSERT INTO ratings (name_key, name, rating) VALUES ($1, $2, $3)
+			ON CONFLICT (name_key) DO NOTHING
+		`, ratingKey(name), strings.TrimSpace(name), initialRating)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Lock both rows in key order so concurrent matches can't deadlock
+	var rows []struct {
+		NameKey string  `db:"name_key"`
+		Rating  float64 `db:"rating"`
+	}
+	err = tx.Select(&rows, `
+		SELECT name_key, rating FROM ratings WHERE name_key IN ($1, $2) ORDER BY name_key FOR UPDATE
+	`, homeKey, awayKey)
+	if err != nil {
+		return err
+	}
+	ratings := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		ratings[row.NameKey] = row.Rating
+	}
+
+	// Extra time counts, a shootout is a draw
+	home, away := match.HomeScore, match.AwayScore
+	if match.HomeScoreAET != nil {
+		home, away = *match.HomeScoreAET, *match.AwayScoreAET
+	}
+	homeScore, homeResult, awayResult := 0.5, "draw", "draw"
+	if home > away {
+		homeScore, homeResult, awayResult = 1, "win", "loss"
+	} else if home < away {
+		homeScore, homeResult, awayResult = 0, "loss", "win"
+	}
+
+	homeDelta := ratingK * (homeScore - expectedScore(ratings[homeKey], ratings[awayKey]))
+	changes := []struct {
+		key    string
+		name   string
+		delta  float64
+		result string
+	}{
+		{homeKey, match.HomeTeamName, homeDelta, homeResult},
+		{awayKey, match.AwayTeamName, -homeDelta, awayResult},
+	}
+	for _, change := range changes {
+		_, err := tx.Exec(`
+			UPDATE ratings
+			SET name = $2, rating = rating + $3, games_played = games_played + 1,
+			    wins = wins + CASE WHEN $4 = 'win' THEN 1 ELSE 0 END,
+			    draws = draws + CASE WHEN $4 = 'draw' THEN 1 ELSE 0 END,
+			    losses = losses + CASE WHEN $4 = 'loss' THEN 1 ELSE 0 END,
+			    updated_at = NOW()
+			WHERE name_key = $1
+		`, change.key, strings.TrimSpace(change.name), change.delta, change.result)
+		if err == nil {
+			_, err = tx.Exec(`
+				INSERT INTO rating_changes (match_id, name_key, delta, result) VALUES ($1, $2, $3, $4)
+			`, match.ID, change.key, change.delta, change.result)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revertRatings takes back the rating changes a match made
+func revertRatings(tx *sqlx.Tx, matchID int) error {
+	_, err := tx.Exec(`
+		UPDATE ratings r
+		SET rating = r.rating - c.delta, games_played = r.games_played - 1,
+		    wins = r.wins - CASE WHEN c.result = 'win' THEN 1 ELSE 0 END,
+		    draws = r.draws - CASE WHEN c.result = 'draw' THEN 1 ELSE 0 END,
+		    losses = r.losses - CASE WHEN c.result = 'loss' THEN 1 ELSE 0 END,
+		    updated_at = NOW()
+		FROM rating_changes c
+		WHERE c.match_id = $1 AND c.name_key = r.name_key
+	`, matchID)
+	if err == nil {
+		_, err = tx.Exec("DELETE FROM rating_changes WHERE match_id = $1", matchID)
+	}
+	return err
+}
+
+// handleRatings serves the server-wide Elo ratings: GET /api/ratings for the
+// table and GET /api/ratings/{name} for one participant
+func (h *Handler) handleRatings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/ratings"), "/")
+	if name == "" {
+		h.getRatings(w, r)
+		return
+	}
+
+	var rating database.Rating
+	err := h.db.Get(&rating, `
+		SELECT `+ratingColumns+` FROM ratings WHERE name_key = $1
+	`, ratingKey(name))
+	if err == sql.ErrNoRows {
+		http.Error(w, "No rated games for this name", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Get rating error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rating)
+}
+
+// getRatings lists the highest rated participants. ?limit= caps the list and
+// ?min_games= leaves out names with too few games to trust.
+func (h *Handler) getRatings(w http.ResponseWriter, r *http.Request) {
+	limit := defaultRatingsLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > maxRatingsLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxRatingsLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	minGames := 0
+	if minGamesParam := r.URL.Query().Get("min_games"); minGamesParam != "" {
+		parsed, err := strconv.Atoi(minGamesParam)
+		if err != nil || parsed < 0 {
+			http.Error(w, "min_games must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		minGames = parsed
+	}
+
+	ratings := []database.Rating{}
+	err := h.db.Select(&ratings, `
+		SELECT `+ratingColumns+` FROM ratings
+		WHERE games_played > 0 AND games_played >= $1
+		ORDER BY rating DESC, name
+		LIMIT $2
+	`, minGames, limit)
+	if err != nil {
+		log.Printf("Get ratings error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := GetRatingsResponse{
+		Ratings: ratings,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Reactions are limited per connection on top of the general message limit,
+// so a client can't fill the room with them: a burst of reactionBurst,
+// refilling one every two seconds.
+const (
+	reactionBurst       = 5
+	reactionsPerSecond  = 0.5
+	maxReactionEmojiLen = 32 // bytes, enough for ZWJ sequences and skin tones
+)
+
+// ReactionMessage is an emoji reaction, optionally to one of the draft's picks
+type ReactionMessage struct {
+	Emoji  string `json:"emoji"`
+	PickID *int   `json:"pickId,omitempty"`
+}
+
+// handleReaction relays a participant's emoji reaction to the room. Reactions
+// are not replayed to clients that reconnect.
+func (h *Handler) handleReaction(client *DraftClient, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Reaction marshal error: %v", err)
+		return fmt.Errorf("invalid reaction message")
+	}
+
+	var reactionMsg ReactionMessage
+	if err := json.Unmarshal(dataBytes, &reactionMsg); err != nil {
+		log.Printf("Reaction unmarshal error: %v", err)
+		return fmt.Errorf("invalid reaction message")
+	}
+
+	if client.ParticipantName == "" {
+		err = fmt.Errorf("join the room before reacting")
+	} else if !client.reactions.allow() {
+		err = fmt.Errorf("too many reactions, slow down")
+	} else {
+		err = validateReactionEmoji(reactionMsg.Emoji)
+	}
+	if err == nil && reactionMsg.PickID != nil {
+		err = h.checkReactionPick(client.Room.DraftCode, *reactionMsg.PickID)
+	}
+	if err != nil {
+		client.sendMessage("reactionError", map[string]string{"error": err.Error()})
+		return err
+	}
+
+	broadcastEphemeralMessage(client.Room.DraftCode, "reaction", map[string]interface{}{
+		"participantName": client.ParticipantName,
+		"emoji":           reactionMsg.Emoji,
+		"pickId":          reactionMsg.PickID,
+		"sentAt":          time.Now(),
+	})
+	return nil
+}
+
+// validateReactionEmoji accepts a single emoji or short run of them, and
+// rejects text so reactions can't stand in for chat
+func validateReactionEmoji(emoji string) error {
+	if emoji == "" {
+		return fmt.Errorf("emoji is required")
+	}
+	if len(emoji) > maxReactionEmojiLen || !utf8.ValidString(emoji) {
+		return fmt.Errorf("invalid emoji")
+	}
+	for _, r := range emoji {
+		if r < utf8.RuneSelf || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("invalid emoji")
+		}
+	}
+	return nil
+}
+
+// checkReactionPick makes sure a reaction's pick belongs to the room's draft
+func (h *Handler) checkReactionPick(draftCode string, pickID int) error {
+	var exists bool
+	err := h.db.Get(&exists, `
+		SELECT EXISTS(
+			SELECT 1 FROM draft_picks dp JOIN drafts d ON dp.draft_id = d.id
+			WHERE dp.id = $1 AND d.code = $2
+		)
+	`, pickID, draftCode)
+	if err != nil {
+		log.Printf("Check reaction pick error: %v", err)
+		return fmt.Errorf("database error")
+	}
+	if !exists {
+		return fmt.Errorf("pick not found")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+)
+
+type ReadyMessage struct {
+	ParticipantName string `json:"participantName"` // optional, must be the client's own name
+	Ready           bool   `json:"ready"`
+}
+
+// handleReady marks a participant as ready (or not) in the waiting lobby
+func (h *Handler) handleReady(client *DraftClient, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Ready marshal error: %v", err)
+		return fmt.Errorf("invalid ready message")
+	}
+
+	var readyMsg ReadyMessage
+	if err := json.Unmarshal(dataBytes, &readyMsg); err != nil {
+		log.Printf("Ready unmarshal error: %v", err)
+		return fmt.Errorf("invalid ready message")
+	}
+
+	if client.ParticipantName == "" || (readyMsg.ParticipantName != "" && readyMsg.ParticipantName != client.ParticipantName) {
+		client.sendMessage("readyError", map[string]string{"error": "you can only ready up yourself"})
+		return fmt.Errorf("you can only ready up yourself")
+	}
+	readyMsg.ParticipantName = client.ParticipantName
+
+	if err := h.setParticipantReady(client.Room.DraftCode, readyMsg, client.token); err != nil {
+		client.sendMessage("readyError", map[string]string{"error": err.Error()})
+		return err
+	}
+
+	BroadcastDraftStateToRoom(h.db, client.Room.DraftCode)
+	return nil
+}
+
+func (h *Handler) setParticipantReady(code string, msg ReadyMessage, token string) error {
+	if err := verifyParticipantToken(h.db, code, msg.ParticipantName, token); err != nil {
+		return err
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE draft_participants p SET is_ready = $1
+		FROM drafts d
+		WHERE d.id = p.draft_id AND d.code = $2 AND d.status = 'waiting' AND p.name = $3
+	`, msg.Ready, code, msg.ParticipantName)
+	if err != nil {
+		log.Printf("Set participant ready error: %v", err)
+		return newStatusError(http.StatusInternalServerError, "Failed to update ready state")
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return newStatusError(http.StatusBadRequest, "Draft has already started")
+	}
+
+	log.Printf("Player %s in draft %s is ready: %t", msg.ParticipantName, code, msg.Ready)
+	return nil
+}
+
+// notReadyParticipants lists the participants who have not readied up yet
+func notReadyParticipants(participants []database.DraftParticipant) []string {
+	names := []string{}
+	for _, participant := range participants {
+		if !participant.IsReady && !participant.IsBot {
+			names = append(names, participant.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"eafc-draft-server/internal/database"
+)
+
+type RecapPick struct {
+	OverallPickNumber int        `json:"overallPickNumber"`
+	RoundNumber       int        `json:"roundNumber"`
+	PickInRound       int        `json:"pickInRound"`
+	PlayerID          int        `json:"playerId"`
+	PlayerName        string     `json:"playerName"`
+	OverallRating     *int       `json:"overallRating"`
+	Position          *string    `json:"position"`
+	Club              *string    `json:"club"`
+	RatingTier        string     `json:"ratingTier"`
+	IsKeeper          bool       `json:"isKeeper"`
+	Comment           *string    `json:"comment"`
+	PickedAt          *time.Time `json:"pickedAt"`
+}
+
+type RecapSquad struct {
+	ParticipantName string                 `json:"participantName"`
+	DraftOrder      int                    `json:"draftOrder"`
+	IsBot           bool                   `json:"isBot"`
+	Picks           []RecapPick            `json:"picks"`
+	ByTier          map[string][]RecapPick `json:"byTier"`
+	ByPosition      map[string][]RecapPick `json:"byPosition"` // GK, DEF, MID, FWD or OTHER
+	AverageRating   *float64               `json:"averageRating"`
+	FirstPick       *RecapPick             `json:"firstPick"`
+	LastPick        *RecapPick             `json:"lastPick"`
+	Chemistry       SquadChemistry         `json:"chemistry"`
+}
+
+type DraftRecapResponse struct {
+	Draft           database.Draft `json:"draft"`
+	DurationSeconds *int64         `json:"durationSeconds"`
+	Squads          []RecapSquad   `json:"squads"`
+}
+
+// getDraftRecap returns a summary of every squad once the draft is over
+func (h *Handler) getDraftRecap(w http.ResponseWriter, r *http.Request, code string) {
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for recap error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.Status != "completed" && draft.Status != "tournament" {
+		http.Error(w, "Draft is not completed yet", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.buildDraftRecap(draft)
+	if err != nil {
+		log.Printf("Build draft recap error: %v", err)
+		http.Error(w, "Failed to build draft recap", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) buildDraftRecap(draft database.Draft) (DraftRecapResponse, error) {
+	response := DraftRecapResponse{Draft: draft, Squads: []RecapSquad{}}
+
+	if draft.StartedAt != nil && draft.CompletedAt != nil {
+		duration := int64(draft.CompletedAt.Sub(*draft.StartedAt).Seconds())
+		response.DurationSeconds = &duration
+	}
+
+	var participants []database.DraftParticipant
+	err := h.db.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		return response, err
+	}
+
+	var picks []struct {
+		ParticipantID     int        `db:"participant_id"`
+		OverallPickNumber int        `db:"overall_pick_number"`
+		RoundNumber       int        `db:"round_number"`
+		PickInRound       int        `db:"pick_in_round"`
+		PlayerID          int        `db:"player_id"`
+		RatingTier        string     `db:"player_rating_tier"`
+		IsKeeper          bool       `db:"is_keeper"`
+		Comment           *string    `db:"comment"`
+		PickedAt          *time.Time `db:"picked_at"`
+		FirstName         *string    `db:"first_name"`
+		LastName          *string    `db:"last_name"`
+		CommonName        *string    `db:"common_name"`
+		OverallRating     *int       `db:"overall_rating"`
+		Position          *string    `db:"position_short_label"`
+		Club              *string    `db:"team_label"`
+	}
+	err = h.db.Select(&picks, `
+		SELECT dp.participant_id, dp.overall_pick_number, dp.round_number, dp.pick_in_round,
+		       dp.player_id, dp.player_rating_tier, dp.is_keeper, dp.comment, dp.picked_at,
+		       p.first_name, p.last_name, p.common_name, p.overall_rating,
+		       p.position_short_label, p.team_label
+		FROM draft_picks dp
+		JOIN drafts d ON dp.draft_id = d.id
+		JOIN players p ON dp.player_id = p.id AND p.dataset = d.dataset
+		WHERE dp.draft_id = $1
+		ORDER BY dp.overall_pick_number
+	`, draft.ID)
+	if err != nil {
+		return response, err
+	}
+
+	squadIndex := make(map[int]int, len(participants))
+	for i, participant := range participants {
+		squadIndex[participant.ID] = i
+		response.Squads = append(response.Squads, RecapSquad{
+			ParticipantName: participant.Name,
+			DraftOrder:      participant.DraftOrder,
+			IsBot:           participant.IsBot,
+			Picks:           []RecapPick{},
+			ByTier:          map[string][]RecapPick{},
+			ByPosition:      map[string][]RecapPick{},
+		})
+	}
+
+	for _, pick := range picks {
+		i, ok := squadIndex[pick.ParticipantID]
+		if !ok {
+			continue
+		}
+		response.Squads[i].Picks = append(response.Squads[i].Picks, RecapPick{
+			OverallPickNumber: pick.OverallPickNumber,
+			RoundNumber:       pick.RoundNumber,
+			PickInRound:       pick.PickInRound,
+			PlayerID:          pick.PlayerID,
+			PlayerName:        playerDisplayName(pick.FirstName, pick.LastName, pick.CommonName),
+			OverallRating:     pick.OverallRating,
+			Position:          pick.Position,
+			Club:              pick.Club,
+			RatingTier:        pick.RatingTier,
+			IsKeeper:          pick.IsKeeper,
+			Comment:           pick.Comment,
+			PickedAt:          pick.PickedAt,
+		})
+	}
+
+	chemistry, err := squadChemistryByParticipant(h.db, draft.ID, participants)
+	if err != nil {
+		return response, err
+	}
+
+	for i := range response.Squads {
+		summarizeRecapSquad(&response.Squads[i])
+		response.Squads[i].Chemistry = chemistry[response.Squads[i].ParticipantName]
+	}
+
+	return response, nil
+}
+
+// summarizeRecapSquad fills in the groupings and stats from a squad's picks,
+// which are in pick order
+func summarizeRecapSquad(squad *RecapSquad) {
+	ratingTotal, rated := 0, 0
+	for _, pick := range squad.Picks {
+		squad.ByTier[pick.RatingTier] = append(squad.ByTier[pick.RatingTier], pick)
+
+		group := "OTHER"
+		if pick.Position != nil {
+			if g := positionGroupOf(*pick.Position); g != "" {
+				group = g
+			}
+		}
+		squad.ByPosition[group] = append(squad.ByPosition[group], pick)
+
+		if pick.OverallRating != nil {
+			ratingTotal += *pick.OverallRating
+			rated++
+		}
+	}
+
+	if rated > 0 {
+		average := roundTo2(float64(ratingTotal) / float64(rated))
+		squad.AverageRating = &average
+	}
+
+	if len(squad.Picks) > 0 {
+		first := squad.Picks[0]
+		last := squad.Picks[len(squad.Picks)-1]
+		squad.FirstPick = &first
+		squad.LastPick = &last
+	}
+}
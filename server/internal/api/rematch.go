@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+)
+
+type RematchRequest struct {
+	AdminName string `json:"adminName"`
+}
+
+type RematchResponse struct {
+	Draft        database.Draft              `json:"draft"`
+	Participants []database.DraftParticipant `json:"participants"`
+}
+
+// createRematch clones a finished draft into a new waiting draft with the same
+// settings and participants. Participants keep their tokens, so everyone can
+// act in the rematch without joining again. A rematch of a league draft is
+// another season of the same league.
+func (h *Handler) createRematch(w http.ResponseWriter, r *http.Request, code string) {
+	if err := h.maintenanceError(); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	var req RematchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Rematch decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	newCode, err := h.generateUniqueDraftCode()
+	if err != nil {
+		log.Printf("Generate unique code error: %v", err)
+		http.Error(w, "Failed to generate draft code", http.StatusInternalServerError)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var original database.Draft
+	err = tx.Get(&original, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for rematch error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if original.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can start a rematch", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if original.Status != "completed" && original.Status != "tournament" {
+		http.Error(w, "Draft is not completed yet", http.StatusBadRequest)
+		return
+	}
+
+	// Copy the settings straight across so nothing is missed
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		INSERT INTO drafts (code, name, admin_name, participant_count, total_rounds, quota_85_89, quota_80_84,
+		                    quota_up_to_79, pick_timer_seconds, pool_leagues, pool_nationalities, max_participants,
+		                    join_password_hash, blind_mode, order_mode, pack_size, quota_gk, dataset, pool_gender,
+		                    ban_special_cards, min_gk, league_id)
+		SELECT $1, name, admin_name, participant_count, total_rounds, quota_85_89, quota_80_84,
+		       quota_up_to_79, pick_timer_seconds, pool_leagues, pool_nationalities, max_participants,
+		       join_password_hash, blind_mode, order_mode, pack_size, quota_gk, dataset, pool_gender,
+		       ban_special_cards, min_gk, league_id
+		FROM drafts WHERE id = $2
+		RETURNING `+database.DraftColumns+`
+	`, newCode, original.ID)
+	if err != nil {
+		log.Printf("Create rematch draft error: %v", err)
+		http.Error(w, "Failed to create rematch", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO draft_participants (draft_id, name, draft_order, is_admin, is_bot, bot_strategy, is_ready, token)
+		SELECT $1, name, draft_order, is_admin, is_bot, bot_strategy, is_bot, token
+		FROM draft_participants WHERE draft_id = $2
+	`, draft.ID, original.ID)
+	if err != nil {
+		log.Printf("Copy rematch participants error: %v", err)
+		http.Error(w, "Failed to create rematch", http.StatusInternalServerError)
+		return
+	}
+
+	var participants []database.DraftParticipant
+	err = tx.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Get rematch participants error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventDraftCreated, req.AdminName, map[string]string{"name": draft.Name, "rematchOf": code}); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to create rematch", http.StatusInternalServerError)
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to create rematch", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Created rematch %s of draft %s", newCode, code)
+
+	// Point everyone still in the old room at the new draft
+	broadcastMessage(code, "rematch", map[string]string{"code": newCode})
+
+	response := RematchResponse{
+		Draft:        draft,
+		Participants: participants,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
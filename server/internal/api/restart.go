@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"eafc-draft-server/internal/database"
+)
+
+type RestartDraftRequest struct {
+	AdminName string `json:"adminName"`
+	// Settings to change before drafting again, nil fields are kept
+	DraftSettingsInput
+}
+
+type RestartDraftResponse struct {
+	Draft database.Draft `json:"draft"`
+}
+
+// restartDraft wipes all picks, and any results a completed draft already
+// has, and puts a started draft back in the lobby. Participants, keepers and
+// the draft order are kept, and the admin can fix the settings at the same
+// time.
+func (h *Handler) restartDraft(w http.ResponseWriter, r *http.Request, code string) {
+	var req RestartDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Restart draft decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AdminName == "" {
+		http.Error(w, "AdminName is required", http.StatusBadRequest)
+		return
+	}
+
+	// Start transaction
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for restart error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can restart the draft", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.Status != "active" && draft.Status != "completed" {
+		http.Error(w, "Only an active or completed draft can be restarted", http.StatusBadRequest)
+		return
+	}
+
+	settings := req.DraftSettingsInput.apply(draft.DraftSettings)
+	if err := validateDraftSettings(settings); err != nil {
+		http.Error(w, "Invalid settings: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var lastKeeperRound int
+	err = tx.Get(&lastKeeperRound, "SELECT COALESCE(MAX(round_number), 0) FROM draft_keepers WHERE draft_id = $1", draft.ID)
+	if err != nil {
+		log.Printf("Get keeper rounds error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if lastKeeperRound > settings.TotalRounds {
+		http.Error(w, fmt.Sprintf("A keeper is assigned to round %d, which the new settings don't have", lastKeeperRound), http.StatusBadRequest)
+		return
+	}
+
+	if _, err = tx.Exec("DELETE FROM draft_picks WHERE draft_id = $1", draft.ID); err != nil {
+		log.Printf("Delete picks for restart error: %v", err)
+		http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err = tx.Exec("DELETE FROM draft_blind_submissions WHERE draft_id = $1", draft.ID); err != nil {
+		log.Printf("Delete blind submissions for restart error: %v", err)
+		http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err = tx.Exec("DELETE FROM draft_packs WHERE draft_id = $1", draft.ID); err != nil {
+		log.Printf("Delete packs for restart error: %v", err)
+		http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+		return
+	}
+
+	// A completed draft can already have results. The squads they were
+	// played with are gone, so the matches go too and their Elo is taken back.
+	var matchIDs []int
+	if err = tx.Select(&matchIDs, "SELECT id FROM matches WHERE draft_id = $1", draft.ID); err != nil {
+		log.Printf("Get matches for restart error: %v", err)
+		http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+		return
+	}
+	for _, matchID := range matchIDs {
+		if err = revertRatings(tx, matchID); err != nil {
+			log.Printf("Revert ratings of match %d for restart error: %v", matchID, err)
+			http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	for _, table := range []string{"fixtures", "knockout_ties", "matches"} {
+		if _, err = tx.Exec("DELETE FROM "+table+" WHERE draft_id = $1", draft.ID); err != nil {
+			log.Printf("Delete %s for restart error: %v", table, err)
+			http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE draft_participants
+		SET picks_85_89 = 0, picks_80_84 = 0, picks_75_79 = 0, picks_up_to_74 = 0, picks_gk = 0, is_ready = is_bot,
+		    tournament_group = NULL
+		WHERE draft_id = $1
+	`, draft.ID)
+	if err != nil {
+		log.Printf("Reset participants for restart error: %v", err)
+		http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+		return
+	}
+
+	err = tx.Get(&draft, `
+		UPDATE drafts
+		SET status = 'waiting', current_round = 1, current_pick_in_round = 1,
+		    started_at = NULL, completed_at = NULL, pick_deadline = NULL,
+		    total_rounds = $1, quota_85_89 = $2, quota_80_84 = $3, quota_up_to_79 = $4,
+		    pick_timer_seconds = $5, pool_leagues = $6, pool_nationalities = $7, order_mode = $8, quota_gk = $9,
+		    pool_gender = $10, ban_special_cards = $11, min_gk = $12
+		WHERE id = $13
+		RETURNING `+database.DraftColumns+`
+	`, settings.TotalRounds, settings.Quota8589, settings.Quota8084, settings.QuotaUpTo79,
+		settings.PickTimerSeconds, settings.PoolLeagues, settings.PoolNationalities, settings.OrderMode,
+		settings.QuotaGK, settings.PoolGender, settings.BanSpecialCards, settings.MinGK, draft.ID)
+	if err != nil {
+		log.Printf("Reset draft for restart error: %v", err)
+		http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventDraftRestarted, req.AdminName, settings); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to restart draft", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Draft %s restarted by %s", code, req.AdminName)
+
+	broadcastStatusChanged(code, draft)
+	if h.broadcastFunc != nil {
+		go h.broadcastFunc(h.db, code)
+	}
+
+	response := RestartDraftResponse{
+		Draft: draft,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
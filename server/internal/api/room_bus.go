@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// redisRoomChannel carries room messages between server instances
+const redisRoomChannel = "eafc-draft:rooms"
+
+// How long the subscriber waits before reconnecting to Redis, doubling up to
+// the maximum while it stays down
+const (
+	redisReconnectDelay    = time.Second
+	redisMaxReconnectDelay = 30 * time.Second
+)
+
+// The subscriber pings Redis every redisPingInterval and counts the
+// connection dead when nothing, not even the pong, arrives for
+// redisSubscribeTimeout. Until it resubscribes this instance delivers its own
+// messages locally.
+const (
+	redisPingInterval     = 5 * time.Second
+	redisSubscribeTimeout = 3 * redisPingInterval
+)
+
+// redisRoomQueueSize is how many received messages may wait for a room that
+// is slow to take them before further ones are dropped
+const redisRoomQueueSize = 256
+
+// Kinds of room envelope, one per way of addressing clients
+const (
+	envelopeBroadcast   = "broadcast"
+	envelopeEphemeral   = "ephemeral"
+	envelopeParticipant = "participant"
+	envelopeAll         = "all"
+)
+
+// roomEnvelope is a room message as published to the other instances. Each
+// instance delivers it to the clients connected to it.
+type roomEnvelope struct {
+	Kind        string          `json:"kind"`
+	DraftCode   string          `json:"draftCode,omitempty"`
+	Participant string          `json:"participant,omitempty"`
+	Message     json.RawMessage `json:"message"`
+}
+
+// redisBus shares room messages between instances through Redis pub/sub, so
+// clients of the same draft connected to different replicas all get them.
+// Every instance, this one included, delivers what it receives on the
+// channel. Presence and spectator counts stay per instance.
+type redisBus struct {
+	url        string
+	mutex      sync.Mutex // guards publisher
+	publisher  *redisConn
+	subscribed atomic.Bool
+
+	// Received messages wait in a queue per draft, delivered in order by a
+	// goroutine that exits once its queue is empty, so one busy room can't
+	// hold up the others
+	queueMutex sync.Mutex
+	queues     map[string]chan roomEnvelope
+}
+
+// StartRedisBroadcasts routes room messages through Redis when REDIS_URL is
+// configured. Without it rooms only reach clients on this instance.
+func (h *Handler) StartRedisBroadcasts() {
+	if h.config.RedisURL == "" {
+		return
+	}
+
+	bus := &redisBus{url: h.config.RedisURL, queues: make(map[string]chan roomEnvelope)}
+	roomManager.bus = bus
+	go bus.subscribe()
+
+	log.Printf("Sharing room broadcasts through Redis")
+}
+
+// publish sends an envelope to every instance. It returns false when this
+// instance won't get it back from Redis, and must deliver it itself.
+func (b *redisBus) publish(envelope roomEnvelope) bool {
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		log.Printf("Failed to marshal room envelope: %v", err)
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// Retry once on a fresh connection, as the old one may have gone stale
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.publisher == nil {
+			if b.publisher, err = dialRedis(b.url); err != nil {
+				break
+			}
+		}
+		if _, err = b.publisher.do("PUBLISH", redisRoomChannel, string(payload)); err == nil {
+			return b.subscribed.Load()
+		}
+		b.publisher.Close()
+		b.publisher = nil
+	}
+
+	log.Printf("Publish room message to Redis error, delivering locally only: %v", err)
+	return false
+}
+
+// subscribe delivers the room messages published by every instance to this
+// instance's clients, reconnecting whenever the connection drops
+func (b *redisBus) subscribe() {
+	delay := redisReconnectDelay
+	for {
+		err := b.receive()
+		b.subscribed.Store(false)
+		log.Printf("Redis room subscription error, reconnecting in %s: %v", delay, err)
+
+		time.Sleep(delay)
+		delay *= 2
+		if delay > redisMaxReconnectDelay {
+			delay = redisMaxReconnectDelay
+		}
+	}
+}
+
+// receive subscribes once and dispatches messages until the connection fails
+func (b *redisBus) receive() error {
+	conn, err := dialRedis(b.url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.do("SUBSCRIBE", redisRoomChannel); err != nil {
+		return err
+	}
+	b.subscribed.Store(true)
+	log.Printf("Subscribed to Redis channel %s", redisRoomChannel)
+
+	// Ping so a connection that died silently is noticed by the read deadline
+	stopPing := make(chan struct{})
+	defer close(stopPing)
+	go func() {
+		ticker := time.NewTicker(redisPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := conn.send("PING"); err != nil {
+					conn.Close()
+					return
+				}
+			case <-stopPing:
+				return
+			}
+		}
+	}()
+
+	for {
+		conn.conn.SetReadDeadline(time.Now().Add(redisSubscribeTimeout))
+		reply, err := conn.receive()
+		if err != nil {
+			return err
+		}
+
+		// Pushed messages are ["message", channel, payload], and pings are
+		// answered with ["pong", ""]
+		items, ok := reply.([]interface{})
+		if !ok || len(items) == 0 {
+			return fmt.Errorf("unexpected redis push %v", reply)
+		}
+		kind, _ := items[0].([]byte)
+		if string(kind) != "message" || len(items) != 3 {
+			continue
+		}
+		payload, _ := items[2].([]byte)
+
+		var envelope roomEnvelope
+		if err := json.Unmarshal(payload, &envelope); err != nil {
+			log.Printf("Invalid room envelope from Redis: %v", err)
+			continue
+		}
+		b.enqueue(envelope)
+	}
+}
+
+// enqueue hands a received envelope to its draft's queue without blocking,
+// starting a goroutine to deliver the queue if it has none
+func (b *redisBus) enqueue(envelope roomEnvelope) {
+	b.queueMutex.Lock()
+	defer b.queueMutex.Unlock()
+
+	queue, ok := b.queues[envelope.DraftCode]
+	if !ok {
+		queue = make(chan roomEnvelope, redisRoomQueueSize)
+		b.queues[envelope.DraftCode] = queue
+		go b.deliver(envelope.DraftCode, queue)
+	}
+
+	select {
+	case queue <- envelope:
+	default:
+		log.Printf("Room %s is too far behind, dropping a %s message from Redis", envelope.DraftCode, envelope.Kind)
+	}
+}
+
+// deliver dispatches a draft's queued envelopes in order until the queue is
+// empty. The queue is only removed once its last envelope has been
+// delivered, so a queue started after it can't overtake it.
+func (b *redisBus) deliver(draftCode string, queue chan roomEnvelope) {
+	for {
+		b.queueMutex.Lock()
+		select {
+		case envelope := <-queue:
+			b.queueMutex.Unlock()
+			roomManager.dispatch(envelope)
+		default:
+			delete(b.queues, draftCode)
+			b.queueMutex.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// shutdownState tracks picks in progress so the server can finish them
+// before exiting. Once draining, no new picks start.
+type shutdownState struct {
+	mutex    sync.Mutex
+	draining bool
+	picks    sync.WaitGroup
+}
+
+// startPick counts a pick in progress. It returns false once the server is
+// shutting down.
+func (s *shutdownState) startPick() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.draining {
+		return false
+	}
+	s.picks.Add(1)
+	return true
+}
+
+func (s *shutdownState) finishPick() {
+	s.picks.Done()
+}
+
+// drain stops new picks and waits for those in progress
+func (s *shutdownState) drain(ctx context.Context) error {
+	s.mutex.Lock()
+	s.draining = true
+	s.mutex.Unlock()
+
+	return waitGroupContext(ctx, &s.picks)
+}
+
+// shuttingDownError is returned for picks attempted while the server stops
+func shuttingDownError() error {
+	return newStatusError(http.StatusServiceUnavailable, "the server is restarting, try again in a moment")
+}
+
+// Shutdown lets in-flight picks commit, then tells every websocket client the
+// server is restarting and closes their connections. The HTTP server should
+// have stopped accepting connections first.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	if err := h.shutdown.drain(ctx); err != nil {
+		log.Printf("Timed out waiting for picks in progress: %v", err)
+	}
+	return roomManager.CloseAll(ctx)
+}
+
+// CloseAll sends serverRestarting to every client in every room and closes
+// their connections, waiting until the messages are written
+func (rm *RoomManager) CloseAll(ctx context.Context) error {
+	message, err := json.Marshal(WSMessage{
+		Type: "serverRestarting",
+		Data: map[string]string{"message": "The server is restarting, reconnect in a moment"},
+	})
+	if err != nil {
+		return err
+	}
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server restarting")
+
+	rm.mutex.RLock()
+	rooms := make([]*DraftRoom, 0, len(rm.rooms))
+	for _, room := range rm.rooms {
+		rooms = append(rooms, room)
+	}
+	rm.mutex.RUnlock()
+
+	for _, room := range rooms {
+		room.do(func() {
+			for client := range room.clients {
+				room.deliver(client, message)
+				client.closeMessage = closeMessage
+				room.removeClient(client)
+			}
+		})
+	}
+
+	log.Printf("Closing websocket connections in %d rooms", len(rooms))
+	return waitGroupContext(ctx, &rm.connections)
+}
+
+// waitGroupContext waits for a WaitGroup, giving up when ctx is done
+func waitGroupContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
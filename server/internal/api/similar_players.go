@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/lib/pq"
+)
+
+// Stats compared when looking for similar players. Goalkeepers are compared
+// on their keeping stats, everyone else on the six face stats.
+var (
+	similarOutfieldStats   = []string{"stat_pac", "stat_sho", "stat_pas", "stat_dri", "stat_def", "stat_phy"}
+	similarGoalkeeperStats = []string{"stat_gk_diving", "stat_gk_handling", "stat_gk_kicking", "stat_gk_positioning", "stat_gk_reflexes"}
+)
+
+const (
+	defaultSimilarRatingBand = 3
+	defaultSimilarLimit      = 10
+	maxSimilarLimit          = 50
+)
+
+// SimilarPlayer is a candidate alternative with its distance from the target
+type SimilarPlayer struct {
+	database.Player
+	Distance float64 `db:"distance" json:"distance"` // euclidean distance over the compared stats, lower is closer
+}
+
+type SimilarPlayersResponse struct {
+	Player  database.Player `json:"player"`
+	Similar []SimilarPlayer `json:"similar"`
+}
+
+// getSimilarPlayers finds players in the same position group and rating band
+// as the target, ordered by how close their stats are. Supports dataset,
+// rating_band and limit parameters.
+func (h *Handler) getSimilarPlayers(w http.ResponseWriter, r *http.Request, playerID int) {
+	dataset, err := h.requestedDataset(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	var player database.Player
+	err = h.db.Get(&player, "SELECT * FROM "+listedPlayers+" players WHERE id = $1 AND dataset = $2", playerID, dataset)
+	if err != nil {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+
+	if player.OverallRating == nil || player.PositionShortLabel == nil {
+		http.Error(w, "Player has no rating or position to compare", http.StatusBadRequest)
+		return
+	}
+
+	ratingBand := defaultSimilarRatingBand
+	if value := r.URL.Query().Get("rating_band"); value != "" {
+		if ratingBand, err = strconv.Atoi(value); err != nil || ratingBand < 0 {
+			http.Error(w, "rating_band must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > maxSimilarLimit {
+		limit = defaultSimilarLimit
+	}
+
+	stats := similarOutfieldStats
+	if *player.PositionShortLabel == "GK" {
+		stats = similarGoalkeeperStats
+	}
+	terms := make([]string, len(stats))
+	for i, stat := range stats {
+		terms[i] = fmt.Sprintf("power(COALESCE(p.%s, 0) - COALESCE(t.%s, 0), 2)", stat, stat)
+	}
+
+	positions := []string{*player.PositionShortLabel}
+	if group := positionGroupOf(*player.PositionShortLabel); group != "" {
+		positions = positionGroups[group]
+	}
+
+	query := `
+		SELECT p.*, sqrt(` + strings.Join(terms, " + ") + `) AS distance
+		FROM ` + listedPlayers + ` p, players t
+		WHERE t.id = $1 AND t.dataset = $5 AND p.dataset = t.dataset AND p.id <> t.id
+		  AND p.overall_rating BETWEEN t.overall_rating - $2 AND t.overall_rating + $2
+		  AND p.position_short_label = ANY($3)
+		ORDER BY distance ASC, p.overall_rating DESC, p.id ASC
+		LIMIT $4
+	`
+
+	similar := []SimilarPlayer{}
+	err = h.db.Select(&similar, query, playerID, ratingBand, pq.StringArray(positions), limit, dataset)
+	if err != nil {
+		log.Printf("Similar players query error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := SimilarPlayersResponse{
+		Player:  player,
+		Similar: similar,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"eafc-draft-server/internal/database"
+)
+
+// TeamRecord is a team's results in a subset of its matches, such as its
+// home games
+type TeamRecord struct {
+	GamesPlayed  int `json:"gamesPlayed"`
+	Wins         int `json:"wins"`
+	Draws        int `json:"draws"`
+	Losses       int `json:"losses"`
+	Points       int `json:"points"`
+	GoalsFor     int `json:"goalsFor"`
+	GoalsAgainst int `json:"goalsAgainst"`
+}
+
+// add counts a match the team scored goalsFor and conceded goalsAgainst in
+func (r *TeamRecord) add(goalsFor, goalsAgainst int, rules database.TournamentSettings) {
+	r.GamesPlayed++
+	r.GoalsFor += goalsFor
+	r.GoalsAgainst += goalsAgainst
+	switch {
+	case goalsFor > goalsAgainst:
+		r.Wins++
+	case goalsFor < goalsAgainst:
+		r.Losses++
+	default:
+		r.Draws++
+	}
+	points, bonus := matchPoints(rules, goalsFor, goalsAgainst)
+	r.Points += points + bonus
+}
+
+// leagueMatchCondition limits a query on matches to those that count towards
+// the league table, leaving out knockout and playoff ties
+const leagueMatchCondition = `id NOT IN (SELECT match_id FROM knockout_ties WHERE match_id IS NOT NULL)`
+
+// leagueMatches leaves out the matches that settled bracket ties, which
+// decide the knockout rounds rather than count towards the league table
+func leagueMatches(matches []database.Match, bracket []database.KnockoutTie) []database.Match {
+	tieMatches := make(map[int]bool)
+	for _, tie := range bracket {
+		if tie.MatchID != nil {
+			tieMatches[*tie.MatchID] = true
+		}
+	}
+
+	league := make([]database.Match, 0, len(matches))
+	for _, match := range matches {
+		if !tieMatches[match.ID] {
+			league = append(league, match)
+		}
+	}
+	return league
+}
+
+// calculateStandings builds the league table from confirmed matches
+func calculateStandings(participants []database.DraftParticipant, matches []database.Match, rules database.TournamentSettings) []TeamStanding {
+	standings := make(map[string]*TeamStanding)
+
+	// Initialize standings for all participants
+	for _, participant := range participants {
+		standings[participant.Name] = &TeamStanding{
+			TeamName:       participant.Name,
+			TeamID:         participant.ID,
+			GamesPlayed:    0,
+			Wins:           0,
+			Draws:          0,
+			Losses:         0,
+			Points:         0,
+			GoalsFor:       0,
+			GoalsAgainst:   0,
+			GoalDifference: 0,
+		}
+	}
+
+	// Process matches
+	for _, match := range matches {
+		homeTeam := standings[match.HomeTeamName]
+		awayTeam := standings[match.AwayTeamName]
+
+		if homeTeam == nil || awayTeam == nil {
+			continue // Skip if team not found
+		}
+
+		// Update games played
+		homeTeam.GamesPlayed++
+		awayTeam.GamesPlayed++
+
+		// Update goals
+		homeTeam.GoalsFor += match.HomeScore
+		homeTeam.GoalsAgainst += match.AwayScore
+		awayTeam.GoalsFor += match.AwayScore
+		awayTeam.GoalsAgainst += match.HomeScore
+
+		// Update results
+		if match.HomeScore > match.AwayScore {
+			// Home team wins
+			homeTeam.Wins++
+			awayTeam.Losses++
+		} else if match.HomeScore < match.AwayScore {
+			// Away team wins
+			awayTeam.Wins++
+			homeTeam.Losses++
+		} else {
+			// Draw
+			homeTeam.Draws++
+			awayTeam.Draws++
+		}
+
+		// Update points under the tournament's rules
+		homePoints, homeBonus := matchPoints(rules, match.HomeScore, match.AwayScore)
+		homeTeam.Points += homePoints + homeBonus
+		homeTeam.BonusPoints += homeBonus
+		awayPoints, awayBonus := matchPoints(rules, match.AwayScore, match.HomeScore)
+		awayTeam.Points += awayPoints + awayBonus
+		awayTeam.BonusPoints += awayBonus
+
+		// Update goal difference
+		homeTeam.GoalDifference = homeTeam.GoalsFor - homeTeam.GoalsAgainst
+		awayTeam.GoalDifference = awayTeam.GoalsFor - awayTeam.GoalsAgainst
+
+		// Update fair play
+		homeTeam.YellowCards += match.HomeYellowCards
+		homeTeam.RedCards += match.HomeRedCards
+		homeTeam.FairPlay = fairPlayPoints(homeTeam.YellowCards, homeTeam.RedCards)
+		awayTeam.YellowCards += match.AwayYellowCards
+		awayTeam.RedCards += match.AwayRedCards
+		awayTeam.FairPlay = fairPlayPoints(awayTeam.YellowCards, awayTeam.RedCards)
+
+		homeTeam.Home.add(match.HomeScore, match.AwayScore, rules)
+		awayTeam.Away.add(match.AwayScore, match.HomeScore, rules)
+	}
+
+	result := make([]TeamStanding, 0, len(standings))
+	for _, standing := range standings {
+		result = append(result, *standing)
+	}
+	sortStandings(result, matches, rules)
+
+	// Add strength of schedule based on the sorted table
+	rankedTeams := make([]string, len(result))
+	for i, standing := range result {
+		rankedTeams[i] = standing.TeamName
+	}
+	strengths := calculateStrengthOfSchedule(rankedTeams, matches)
+	for i := range result {
+		result[i].StrengthOfSchedule = strengths[result[i].TeamName].Faced
+		result[i].RemainingStrengthOfSchedule = strengths[result[i].TeamName].Remaining
+	}
+
+	return result
+}
+
+// sortStandings orders a table by points, goal difference and goals scored.
+// Teams still level are split by a mini-league of the matches between them,
+// then by fair play and finally by name, so the order never depends on the
+// order the teams came in.
+func sortStandings(table []TeamStanding, matches []database.Match, rules database.TournamentSettings) {
+	type tieKey struct{ points, goalDifference, goalsFor int }
+	key := func(standing TeamStanding) tieKey {
+		return tieKey{standing.Points, standing.GoalDifference, standing.GoalsFor}
+	}
+
+	tiedWith := make(map[string]tieKey, len(table))
+	for _, standing := range table {
+		tiedWith[standing.TeamName] = key(standing)
+	}
+
+	headToHead := make(map[string]*TeamRecord, len(table))
+	for _, standing := range table {
+		headToHead[standing.TeamName] = &TeamRecord{}
+	}
+	for _, match := range matches {
+		homeKey, homeOK := tiedWith[match.HomeTeamName]
+		awayKey, awayOK := tiedWith[match.AwayTeamName]
+		if !homeOK || !awayOK || homeKey != awayKey {
+			continue
+		}
+		headToHead[match.HomeTeamName].add(match.HomeScore, match.AwayScore, rules)
+		headToHead[match.AwayTeamName].add(match.AwayScore, match.HomeScore, rules)
+	}
+
+	sort.Slice(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if key(a) != key(b) {
+			if a.Points != b.Points {
+				return a.Points > b.Points
+			}
+			if a.GoalDifference != b.GoalDifference {
+				return a.GoalDifference > b.GoalDifference
+			}
+			return a.GoalsFor > b.GoalsFor
+		}
+		h2hA, h2hB := headToHead[a.TeamName], headToHead[b.TeamName]
+		if h2hA.Points != h2hB.Points {
+			return h2hA.Points > h2hB.Points
+		}
+		if diffA, diffB := h2hA.GoalsFor-h2hA.GoalsAgainst, h2hB.GoalsFor-h2hB.GoalsAgainst; diffA != diffB {
+			return diffA > diffB
+		}
+		if h2hA.GoalsFor != h2hB.GoalsFor {
+			return h2hA.GoalsFor > h2hB.GoalsFor
+		}
+		if a.FairPlay != b.FairPlay {
+			return a.FairPlay < b.FairPlay
+		}
+		return a.TeamName < b.TeamName
+	})
+}
+
+// VenueStanding is a row of the home or away table, counting only a team's
+// matches at that venue
+type VenueStanding struct {
+	TeamName string `json:"teamName"`
+	TeamID   int    `json:"teamId"`
+	TeamRecord
+	GoalDifference int `json:"goalDifference"`
+}
+
+// homeAwayTables splits a table into separate home and away tables
+func homeAwayTables(standings []TeamStanding) ([]VenueStanding, []VenueStanding) {
+	home := make([]VenueStanding, 0, len(standings))
+	away := make([]VenueStanding, 0, len(standings))
+	for _, standing := range standings {
+		home = append(home, newVenueStanding(standing.TeamName, standing.TeamID, standing.Home))
+		away = append(away, newVenueStanding(standing.TeamName, standing.TeamID, standing.Away))
+	}
+	sortVenueTable(home)
+	sortVenueTable(away)
+	return home, away
+}
+
+func newVenueStanding(teamName string, teamID int, record TeamRecord) VenueStanding {
+	return VenueStanding{
+		TeamName:       teamName,
+		TeamID:         teamID,
+		TeamRecord:     record,
+		GoalDifference: record.GoalsFor - record.GoalsAgainst,
+	}
+}
+
+// sortVenueTable orders a venue table by points, goal difference, goals
+// scored and then name
+func sortVenueTable(table []VenueStanding) {
+	sort.Slice(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.GoalDifference != b.GoalDifference {
+			return a.GoalDifference > b.GoalDifference
+		}
+		if a.GoalsFor != b.GoalsFor {
+			return a.GoalsFor > b.GoalsFor
+		}
+		return a.TeamName < b.TeamName
+	})
+}
+
+// Points rules default to 3-1-0 without bonus points
+const (
+	defaultPointsWin  = 3
+	defaultPointsDraw = 1
+	defaultPointsLoss = 0
+	maxMatchPoints    = 10
+	maxBonusGoals     = 20
+
+	defaultForfeitGoals = 3
+	maxForfeitGoals     = 10
+)
+
+// scoringRules reads the points and walkover rules from a start tournament
+// request, filling in the defaults. Errors are statusErrors.
+func scoringRules(req StartTournamentRequest) (database.TournamentSettings, error) {
+	rules := database.TournamentSettings{
+		PointsWin:      defaultPointsWin,
+		PointsDraw:     defaultPointsDraw,
+		PointsLoss:     defaultPointsLoss,
+		BonusGoals:     req.BonusGoals,
+		BonusCloseLoss: req.BonusCloseLoss,
+		ForfeitGoals:   defaultForfeitGoals,
+	}
+	if req.ForfeitGoals != nil {
+		rules.ForfeitGoals = *req.ForfeitGoals
+	}
+	if req.PointsWin != nil {
+		rules.PointsWin = *req.PointsWin
+	}
+	if req.PointsDraw != nil {
+		rules.PointsDraw = *req.PointsDraw
+	}
+	if req.PointsLoss != nil {
+		rules.PointsLoss = *req.PointsLoss
+	}
+
+	for _, points := range []int{rules.PointsWin, rules.PointsDraw, rules.PointsLoss} {
+		if points < 0 || points > maxMatchPoints {
+			return rules, newStatusError(http.StatusBadRequest, fmt.Sprintf("Points per result must be between 0 and %d", maxMatchPoints))
+		}
+	}
+	if rules.PointsWin < rules.PointsDraw || rules.PointsDraw < rules.PointsLoss {
+		return rules, newStatusError(http.StatusBadRequest, "A win must be worth at least a draw, and a draw at least a loss")
+	}
+	if rules.BonusGoals < 0 || rules.BonusGoals > maxBonusGoals {
+		return rules, newStatusError(http.StatusBadRequest, fmt.Sprintf("bonusGoals must be between 0 and %d", maxBonusGoals))
+	}
+	if rules.ForfeitGoals < 1 || rules.ForfeitGoals > maxForfeitGoals {
+		return rules, newStatusError(http.StatusBadRequest, fmt.Sprintf("forfeitGoals must be between 1 and %d", maxForfeitGoals))
+	}
+	return rules, nil
+}
+
+// matchPoints returns the points a team earns for a result under the
+// tournament's rules, and the bonus points on top of them
+func matchPoints(rules database.TournamentSettings, goalsFor, goalsAgainst int) (int, int) {
+	points := rules.PointsDraw
+	if goalsFor > goalsAgainst {
+		points = rules.PointsWin
+	} else if goalsFor < goalsAgainst {
+		points = rules.PointsLoss
+	}
+
+	bonus := 0
+	if rules.BonusGoals > 0 && goalsFor >= rules.BonusGoals {
+		bonus++
+	}
+	if rules.BonusCloseLoss && goalsAgainst-goalsFor == 1 {
+		bonus++
+	}
+	return points, bonus
+}
+
+// scheduleStrength is the average league position of the opponents a team has
+// faced and still has to face. A lower number means a tougher schedule.
+type scheduleStrength struct {
+	Faced     *float64
+	Remaining *float64
+}
+
+// calculateStrengthOfSchedule computes schedule strength for every team given
+// the team names in table order. Opponents still to face are the teams a team
+// hasn't played yet, since every pairing is expected to meet at least once.
+func calculateStrengthOfSchedule(rankedTeams []string, matches []database.Match) map[string]scheduleStrength {
+	positions := make(map[string]int, len(rankedTeams))
+	for i, team := range rankedTeams {
+		positions[team] = i + 1
+	}
+
+	facedTotals := make(map[string]int)
+	facedCounts := make(map[string]int)
+	played := make(map[string]map[string]bool)
+	for _, team := range rankedTeams {
+		played[team] = make(map[string]bool)
+	}
+
+	for _, match := range matches {
+		homePosition, homeOK := positions[match.HomeTeamName]
+		awayPosition, awayOK := positions[match.AwayTeamName]
+		if !homeOK || !awayOK {
+			continue
+		}
+
+		facedTotals[match.HomeTeamName] += awayPosition
+		facedCounts[match.HomeTeamName]++
+		facedTotals[match.AwayTeamName] += homePosition
+		facedCounts[match.AwayTeamName]++
+
+		played[match.HomeTeamName][match.AwayTeamName] = true
+		played[match.AwayTeamName][match.HomeTeamName] = true
+	}
+
+	result := make(map[string]scheduleStrength, len(rankedTeams))
+	for _, team := range rankedTeams {
+		var strength scheduleStrength
+
+		if facedCounts[team] > 0 {
+			faced := roundTo2(float64(facedTotals[team]) / float64(facedCounts[team]))
+			strength.Faced = &faced
+		}
+
+		remainingTotal, remainingCount := 0, 0
+		for _, opponent := range rankedTeams {
+			if opponent != team && !played[team][opponent] {
+				remainingTotal += positions[opponent]
+				remainingCount++
+			}
+		}
+		if remainingCount > 0 {
+			remaining := roundTo2(float64(remainingTotal) / float64(remainingCount))
+			strength.Remaining = &remaining
+		}
+
+		result[team] = strength
+	}
+
+	return result
+}
+
+// roundTo2 rounds a value to two decimal places for display
+func roundTo2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
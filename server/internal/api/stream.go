@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// streamDraft serves a draft room's messages as Server-Sent Events, for
+// clients behind proxies that break websockets. The stream joins the room as
+// a spectator, so it gets the same draftState, tournamentState and other
+// broadcasts a websocket would. Each event's id is its room sequence, so a
+// browser reconnecting with Last-Event-ID gets what it missed replayed.
+func (h *Handler) streamDraft(w http.ResponseWriter, r *http.Request, code string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	var draftID int
+	if err := h.db.Get(&draftID, "SELECT id FROM drafts WHERE code = $1", code); err != nil {
+		log.Printf("Get draft for stream error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	var lastSeq *uint64
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		if seq, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+			lastSeq = &seq
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	client := &DraftClient{
+		Send:      make(chan []byte, 256),
+		Spectator: true,
+	}
+	for {
+		client.Room = roomManager.getRoom(code)
+		if client.Room.register(client) {
+			break
+		}
+	}
+	defer client.Room.unregister(client)
+
+	client.Room.Identify(client, "", true, false)
+	h.syncClient(client, lastSeq)
+	h.sendLatestAnnouncement(client)
+
+	log.Printf("Streaming draft %s to %s", code, r.RemoteAddr)
+
+	// Comments keep proxies from timing out a quiet stream
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message, ok := <-client.Send:
+			if !ok {
+				return
+			}
+			if _, err := w.Write(serverSentEvent(message)); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// serverSentEvent frames a room message as an event named after its type,
+// with its sequence as the id if it has one
+func serverSentEvent(message []byte) []byte {
+	var header struct {
+		Type string  `json:"type"`
+		Seq  *uint64 `json:"seq"`
+	}
+	json.Unmarshal(message, &header)
+
+	event := []byte{}
+	if header.Seq != nil {
+		event = fmt.Appendf(event, "id: %d\n", *header.Seq)
+	}
+	if header.Type != "" {
+		event = fmt.Appendf(event, "event: %s\n", header.Type)
+	}
+	event = fmt.Appendf(event, "data: %s\n\n", message)
+	return event
+}
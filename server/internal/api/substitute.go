@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+)
+
+type SubstituteParticipantRequest struct {
+	AdminName string `json:"adminName"`
+	OldName   string `json:"oldName"`
+	NewName   string `json:"newName"`
+}
+
+type SubstituteParticipantResponse struct {
+	Draft       database.Draft            `json:"draft"`
+	Participant database.DraftParticipant `json:"participant"`
+	Token       string                    `json:"token"` // hand this to the new participant
+}
+
+// substituteParticipant replaces a participant with someone new. The seat
+// keeps its id, so the newcomer inherits the draft order, quotas, keepers and
+// picks. The old token stops working.
+func (h *Handler) substituteParticipant(w http.ResponseWriter, r *http.Request, code string) {
+	var req SubstituteParticipantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Substitute participant decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.NewName = strings.TrimSpace(req.NewName)
+	if req.AdminName == "" || req.OldName == "" || req.NewName == "" {
+		http.Error(w, "AdminName, oldName and newName are required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		log.Printf("Begin transaction error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var draft database.Draft
+	err = tx.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1 FOR UPDATE
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for substitution error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	if draft.AdminName != req.AdminName {
+		http.Error(w, "Only the admin can substitute participants", http.StatusForbidden)
+		return
+	}
+
+	if err := verifyParticipantToken(tx, code, req.AdminName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if draft.Status != "waiting" && draft.Status != "active" {
+		http.Error(w, "Participants can only be substituted before or during the draft", http.StatusBadRequest)
+		return
+	}
+
+	if req.OldName == draft.AdminName {
+		http.Error(w, "Transfer admin rights before substituting the admin", http.StatusBadRequest)
+		return
+	}
+
+	var participant database.DraftParticipant
+	err = tx.Get(&participant, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 AND name = $2
+	`, draft.ID, req.OldName)
+	if err != nil {
+		http.Error(w, "Participant not found", http.StatusNotFound)
+		return
+	}
+
+	var nameTaken bool
+	err = tx.Get(&nameTaken, "SELECT EXISTS(SELECT 1 FROM draft_participants WHERE draft_id = $1 AND name = $2)", draft.ID, req.NewName)
+	if err != nil {
+		log.Printf("Check substitute name error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if nameTaken {
+		http.Error(w, "Name already taken in this draft", http.StatusConflict)
+		return
+	}
+
+	token, err := generateParticipantToken()
+	if err != nil {
+		log.Printf("Generate participant token error: %v", err)
+		http.Error(w, "Failed to substitute participant", http.StatusInternalServerError)
+		return
+	}
+
+	// A bot's seat becomes a human seat
+	err = tx.Get(&participant, `
+		UPDATE draft_participants
+		SET name = $1, token = $2, is_bot = FALSE, bot_strategy = NULL, joined_at = NOW()
+		WHERE id = $3
+		RETURNING `+database.ParticipantColumns+`
+	`, req.NewName, token, participant.ID)
+	if err != nil {
+		log.Printf("Substitute participant error: %v", err)
+		http.Error(w, "Failed to substitute participant", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordDraftEvent(tx, draft.ID, EventParticipantSubstituted, req.AdminName, map[string]interface{}{
+		"oldName":    req.OldName,
+		"newName":    req.NewName,
+		"draftOrder": participant.DraftOrder,
+	}); err != nil {
+		log.Printf("Record draft event error: %v", err)
+		http.Error(w, "Failed to substitute participant", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Commit transaction error: %v", err)
+		http.Error(w, "Failed to substitute participant", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Participant %s replaced by %s in draft %s", req.OldName, req.NewName, code)
+
+	broadcastMessage(code, "participantSubstituted", map[string]string{
+		"oldName": req.OldName,
+		"newName": req.NewName,
+	})
+	if h.broadcastFunc != nil {
+		go h.broadcastFunc(h.db, code)
+	}
+
+	response := SubstituteParticipantResponse{
+		Draft:       draft,
+		Participant: participant,
+		Token:       token,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eafc-draft-server/internal/database"
+
+	"github.com/lib/pq"
+)
+
+const (
+	maxTotalRounds      = 30
+	maxPickTimerSeconds = 600
+)
+
+// templateTokenHeader carries the owner token that deleting a template needs
+const templateTokenHeader = "X-Template-Token"
+
+// DraftSettingsInput holds optional setting overrides. Nil fields keep the
+// default or template value.
+type DraftSettingsInput struct {
+	TotalRounds       *int     `json:"totalRounds,omitempty"`
+	Quota8589         *int     `json:"quota8589,omitempty"`
+	Quota8084         *int     `json:"quota8084,omitempty"`
+	QuotaUpTo79       *int     `json:"quotaUpTo79,omitempty"`
+	PickTimerSeconds  *int     `json:"pickTimerSeconds,omitempty"`
+	QuotaGK           *int     `json:"quotaGk,omitempty"`           // 0 removes the goalkeeper limit
+	MinGK             *int     `json:"minGk,omitempty"`             // 0 requires no goalkeeper
+	PoolLeagues       []string `json:"poolLeagues,omitempty"`       // theme draft: only these leagues
+	PoolNationalities []string `json:"poolNationalities,omitempty"` // theme draft: only these nations
+	OrderMode         *string  `json:"orderMode,omitempty"`
+	PoolGender        *string  `json:"poolGender,omitempty"` // men, women or mixed
+	BanSpecialCards   *bool    `json:"banSpecialCards,omitempty"`
+}
+
+type CreateTemplateRequest struct {
+	Name      string `json:"name"`
+	OwnerName string `json:"ownerName"`
+	DraftCode string `json:"draftCode,omitempty"` // copy the settings of an existing draft
+	DraftSettingsInput
+}
+
+// CreateTemplateResponse is the new template with the owner token for
+// deleting it, which is only ever returned here
+type CreateTemplateResponse struct {
+	database.DraftTemplate
+	OwnerToken string `json:"ownerToken"`
+}
+
+type GetTemplatesResponse struct {
+	Templates []database.DraftTemplate `json:"templates"`
+}
+
+// defaultDraftSettings are the classic rules: 11 rounds with one 85-89, four
+// 80-84 and six ≤79 picks. Goalkeeper limits are off unless a draft or
+// template sets them.
+func defaultDraftSettings() database.DraftSettings {
+	return database.DraftSettings{
+		TotalRounds:       11,
+		Quota8589:         1,
+		Quota8084:         4,
+		QuotaUpTo79:       6,
+		PoolLeagues:       pq.StringArray{},
+		PoolNationalities: pq.StringArray{},
+		OrderMode:         OrderModeRotation,
+		PoolGender:        PoolGenderMen,
+	}
+}
+
+// apply overrides settings with every field set in the input
+func (input DraftSettingsInput) apply(settings database.DraftSettings) database.DraftSettings {
+	if input.TotalRounds != nil {
+		settings.TotalRounds = *input.TotalRounds
+	}
+	if input.Quota8589 != nil {
+		settings.Quota8589 = *input.Quota8589
+	}
+	if input.Quota8084 != nil {
+		settings.Quota8084 = *input.Quota8084
+	}
+	if input.QuotaUpTo79 != nil {
+		settings.QuotaUpTo79 = *input.QuotaUpTo79
+	}
+	if input.PickTimerSeconds != nil {
+		settings.PickTimerSeconds = *input.PickTimerSeconds
+	}
+	if input.QuotaGK != nil {
+		settings.QuotaGK = *input.QuotaGK
+	}
+	if input.MinGK != nil {
+		settings.MinGK = *input.MinGK
+	}
+	if input.PoolLeagues != nil {
+		settings.PoolLeagues = pq.StringArray(cleanStringList(input.PoolLeagues))
+	}
+	if input.PoolNationalities != nil {
+		settings.PoolNationalities = pq.StringArray(cleanStringList(input.PoolNationalities))
+	}
+	if input.OrderMode != nil {
+		settings.OrderMode = *input.OrderMode
+	}
+	if input.PoolGender != nil {
+		settings.PoolGender = *input.PoolGender
+	}
+	if input.BanSpecialCards != nil {
+		settings.BanSpecialCards = *input.BanSpecialCards
+	}
+	return settings
+}
+
+// validateDraftSettings checks that a draft with these settings can be completed
+func validateDraftSettings(settings database.DraftSettings) error {
+	if settings.TotalRounds < 1 || settings.TotalRounds > maxTotalRounds {
+		return fmt.Errorf("total rounds must be between 1 and %d", maxTotalRounds)
+	}
+	if settings.Quota8589 < 0 || settings.Quota8084 < 0 || settings.QuotaUpTo79 < 0 {
+		return fmt.Errorf("quotas must be non-negative")
+	}
+	if settings.Quota8589+settings.Quota8084+settings.QuotaUpTo79 < settings.TotalRounds {
+		return fmt.Errorf("quotas only cover %d of %d rounds", settings.Quota8589+settings.Quota8084+settings.QuotaUpTo79, settings.TotalRounds)
+	}
+	if settings.QuotaGK < 0 {
+		return fmt.Errorf("goalkeeper quota must be non-negative")
+	}
+	if settings.MinGK < 0 || settings.MinGK > settings.TotalRounds {
+		return fmt.Errorf("goalkeeper minimum must be between 0 and the number of rounds")
+	}
+	if settings.QuotaGK > 0 && settings.MinGK > settings.QuotaGK {
+		return fmt.Errorf("goalkeeper minimum of %d is above the quota of %d", settings.MinGK, settings.QuotaGK)
+	}
+	if settings.PickTimerSeconds < 0 || settings.PickTimerSeconds > maxPickTimerSeconds {
+		return fmt.Errorf("pick timer must be between 0 and %d seconds", maxPickTimerSeconds)
+	}
+	if !isValidOrderMode(settings.OrderMode) {
+		return fmt.Errorf("order mode must be rotation, snake or 3rr")
+	}
+	if !isValidPoolGender(settings.PoolGender) {
+		return fmt.Errorf("pool gender must be men, women or mixed")
+	}
+	return nil
+}
+
+// resolveDraftSettings builds the settings for a new draft from the defaults,
+// an optional template and the explicit overrides, in that order
+func (h *Handler) resolveDraftSettings(templateID *int, input DraftSettingsInput) (database.DraftSettings, error) {
+	settings := defaultDraftSettings()
+
+	if templateID != nil {
+		var template database.DraftTemplate
+		err := h.db.Get(&template, "SELECT id, name, owner_name, created_at, "+database.DraftSettingsColumns+" FROM draft_templates WHERE id = $1", *templateID)
+		if err != nil {
+			return settings, newStatusError(http.StatusBadRequest, "Template not found")
+		}
+		settings = template.DraftSettings
+	}
+
+	settings = input.apply(settings)
+	if err := validateDraftSettings(settings); err != nil {
+		return settings, newStatusError(http.StatusBadRequest, "Invalid settings: "+err.Error())
+	}
+
+	return settings, nil
+}
+
+func (h *Handler) handleTemplates(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s", r.Method, r.URL.Path)
+
+	idPart := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/templates"), "/")
+	if idPart == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.getTemplates(w, r)
+		case http.MethodPost:
+			h.createTemplate(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getTemplate(w, r, id)
+	case http.MethodDelete:
+		h.deleteTemplate(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) getTemplates(w http.ResponseWriter, r *http.Request) {
+	owner := r.URL.Query().Get("owner")
+	if owner == "" {
+		http.Error(w, "Missing owner parameter", http.StatusBadRequest)
+		return
+	}
+
+	templates := []database.DraftTemplate{}
+	err := h.db.Select(&templates, "SELECT id, name, owner_name, created_at, "+database.DraftSettingsColumns+" FROM draft_templates WHERE owner_name = $1 ORDER BY name", owner)
+	if err != nil {
+		log.Printf("Get templates error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GetTemplatesResponse{Templates: templates})
+}
+
+func (h *Handler) getTemplate(w http.ResponseWriter, r *http.Request, id int) {
+	var template database.DraftTemplate
+	err := h.db.Get(&template, "SELECT id, name, owner_name, created_at, "+database.DraftSettingsColumns+" FROM draft_templates WHERE id = $1", id)
+	if err != nil {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *Handler) createTemplate(w http.ResponseWriter, r *http.Request) {
+	var req CreateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Create template decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" || req.OwnerName == "" {
+		http.Error(w, "Name and ownerName are required", http.StatusBadRequest)
+		return
+	}
+
+	settings := defaultDraftSettings()
+	if req.DraftCode != "" {
+		var draft database.Draft
+		err := h.db.Get(&draft, `
+			SELECT `+database.DraftColumns+`
+			FROM drafts WHERE code = $1
+		`, req.DraftCode)
+		if err != nil {
+			http.Error(w, "Draft not found", http.StatusNotFound)
+			return
+		}
+		settings = draft.DraftSettings
+	}
+
+	settings = req.DraftSettingsInput.apply(settings)
+	if err := validateDraftSettings(settings); err != nil {
+		http.Error(w, "Invalid settings: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ownerToken, err := generateParticipantToken()
+	if err != nil {
+		log.Printf("Generate template owner token error: %v", err)
+		http.Error(w, "Failed to create template", http.StatusInternalServerError)
+		return
+	}
+
+	var template database.DraftTemplate
+	err = h.db.Get(&template, `
+		INSERT INTO draft_templates (name, owner_name, total_rounds, quota_85_89, quota_80_84, quota_up_to_79,
+		                             pick_timer_seconds, pool_leagues, pool_nationalities, order_mode, quota_gk, pool_gender,
+		                             ban_special_cards, min_gk, owner_token)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, name, owner_name, created_at, `+database.DraftSettingsColumns+`
+	`, req.Name, req.OwnerName, settings.TotalRounds, settings.Quota8589, settings.Quota8084, settings.QuotaUpTo79,
+		settings.PickTimerSeconds, settings.PoolLeagues, settings.PoolNationalities, settings.OrderMode, settings.QuotaGK,
+		settings.PoolGender, settings.BanSpecialCards, settings.MinGK, ownerToken)
+	if err != nil {
+		log.Printf("Create template error: %v", err)
+		http.Error(w, "Failed to create template", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Created draft template %s (%d) for %s", template.Name, template.ID, template.OwnerName)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CreateTemplateResponse{DraftTemplate: template, OwnerToken: ownerToken})
+}
+
+// deleteTemplate deletes a template for whoever holds its owner token, sent in
+// X-Template-Token, or for the operator
+func (h *Handler) deleteTemplate(w http.ResponseWriter, r *http.Request, id int) {
+	var ownerToken *string
+	err := h.db.Get(&ownerToken, "SELECT owner_token FROM draft_templates WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Get template owner token error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	token := r.Header.Get(templateTokenHeader)
+	isOwner := ownerToken != nil && token != "" && subtle.ConstantTimeCompare([]byte(*ownerToken), []byte(token)) == 1
+	if !isOwner && !h.isOperator(r) {
+		http.Error(w, "Only the template's owner can delete it", http.StatusForbidden)
+		return
+	}
+
+	if _, err := h.db.Exec("DELETE FROM draft_templates WHERE id = $1", id); err != nil {
+		log.Printf("Delete template error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Deleted draft template %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
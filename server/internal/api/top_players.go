@@ -0,0 +1,138 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"eafc-draft-server/internal/database"
+)
+
+const (
+	defaultTopPerPosition = 5
+	maxTopPerPosition     = 50
+)
+
+// positionOrder lists positions from the goalkeeper forwards, for ordering
+// per-position results the way a squad sheet reads
+var positionOrder = []string{"GK", "CB", "LB", "RB", "LWB", "RWB", "CDM", "CM", "CAM", "LM", "RM", "ST", "CF", "LW", "RW"}
+
+type TopPosition struct {
+	Position string      `json:"position"`
+	Players  interface{} `json:"players"` // []database.Player, or field maps with fields=
+}
+
+type GetTopPlayersResponse struct {
+	Positions []TopPosition `json:"positions"`
+}
+
+// getTopPlayers returns the per_position (default 5) highest rated players at
+// each main position, optionally no better than max_rating. It takes the same
+// filters as the player listing, so draft=...&available=true gives the best
+// players still on the board.
+func (h *Handler) getTopPlayers(w http.ResponseWriter, r *http.Request) {
+	log.Printf("GET /api/players/top - Query params: %v", r.URL.Query())
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	perPosition := defaultTopPerPosition
+	if value := r.URL.Query().Get("per_position"); value != "" {
+		var err error
+		if perPosition, err = strconv.Atoi(value); err != nil || perPosition < 1 || perPosition > maxTopPerPosition {
+			http.Error(w, "per_position must be between 1 and "+strconv.Itoa(maxTopPerPosition), http.StatusBadRequest)
+			return
+		}
+	}
+
+	fields, err := parsePlayerFields(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	conditions, args, argIndex, err := h.playerFilterConditions(r)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+	conditions = append(conditions, "position_short_label IS NOT NULL", "overall_rating IS NOT NULL")
+
+	if value := r.URL.Query().Get("max_rating"); value != "" {
+		maxRating, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "max_rating must be an integer", http.StatusBadRequest)
+			return
+		}
+		conditions = append(conditions, "overall_rating <= $"+strconv.Itoa(argIndex))
+		args = append(args, maxRating)
+		argIndex++
+	}
+
+	query := `
+		SELECT * FROM (
+			SELECT players.*, ROW_NUMBER() OVER (
+				PARTITION BY position_short_label ORDER BY overall_rating DESC, id ASC
+			) AS position_rank
+			FROM ` + listedPlayers + ` players
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		) ranked
+		WHERE position_rank <= $` + strconv.Itoa(argIndex) + `
+		ORDER BY position_short_label, position_rank
+	`
+	args = append(args, perPosition)
+
+	var rows []struct {
+		database.Player
+		PositionRank int `db:"position_rank"`
+	}
+	if err := h.db.Select(&rows, query, args...); err != nil {
+		log.Printf("Top players query error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	byPosition := make(map[string][]database.Player)
+	var positions []string
+	for _, row := range rows {
+		position := *row.PositionShortLabel
+		if _, ok := byPosition[position]; !ok {
+			positions = append(positions, position)
+		}
+		byPosition[position] = append(byPosition[position], row.Player)
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positionSortIndex(positions[i]) < positionSortIndex(positions[j])
+	})
+
+	response := GetTopPlayersResponse{Positions: []TopPosition{}}
+	for _, position := range positions {
+		response.Positions = append(response.Positions, TopPosition{
+			Position: position,
+			Players:  selectPlayerFields(byPosition[position], fields),
+		})
+	}
+
+	// Draft-scoped results change with every pick, so only the ETag applies
+	var lastModified *time.Time
+	if r.URL.Query().Get("draft") == "" {
+		updatedAt := h.playerData.lastModified()
+		lastModified = &updatedAt
+	}
+	writeCachableJSON(w, r, response, lastModified)
+}
+
+// positionSortIndex places unknown positions after the known ones
+func positionSortIndex(position string) int {
+	for i, known := range positionOrder {
+		if known == position {
+			return i
+		}
+	}
+	return len(positionOrder)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+
+	"eafc-draft-server/internal/database"
+)
+
+// WinStreak is a run of consecutive wins by one team
+type WinStreak struct {
+	TeamName string `json:"teamName"`
+	Length   int    `json:"length"`
+}
+
+// TeamCleanSheets counts the matches a team didn't concede in
+type TeamCleanSheets struct {
+	TeamName    string `json:"teamName"`
+	CleanSheets int    `json:"cleanSheets"`
+}
+
+type TournamentStats struct {
+	MatchesPlayed    int               `json:"matchesPlayed"`
+	TotalGoals       int               `json:"totalGoals"`
+	AverageGoals     float64           `json:"averageGoals"`
+	BiggestWin       *database.Match   `json:"biggestWin"`       // nil until someone wins
+	HighestScoring   *database.Match   `json:"highestScoring"`   // nil before any matches
+	LongestWinStreak *WinStreak        `json:"longestWinStreak"` // nil until someone wins
+	CleanSheets      []TeamCleanSheets `json:"cleanSheets"`      // most first
+}
+
+// calculateTournamentStats computes the records of a tournament from its
+// confirmed matches in the order they were played. Like the tables they go
+// by the 90 minute score, and walkovers are left out since nobody played.
+// Earlier matches win ties for a record.
+func calculateTournamentStats(participants []database.DraftParticipant, matches []database.Match) TournamentStats {
+	stats := TournamentStats{CleanSheets: []TeamCleanSheets{}}
+
+	cleanSheets := make(map[string]int, len(participants))
+	streaks := make(map[string]int, len(participants))
+	for _, participant := range participants {
+		cleanSheets[participant.Name] = 0
+	}
+
+	for i := range matches {
+		match := &matches[i]
+		if match.Walkover {
+			continue
+		}
+
+		goals := match.HomeScore + match.AwayScore
+		stats.MatchesPlayed++
+		stats.TotalGoals += goals
+
+		if stats.HighestScoring == nil || goals > stats.HighestScoring.HomeScore+stats.HighestScoring.AwayScore {
+			stats.HighestScoring = match
+		}
+
+		if margin := winMargin(*match); margin > 0 && (stats.BiggestWin == nil || margin > winMargin(*stats.BiggestWin)) {
+			stats.BiggestWin = match
+		}
+
+		if match.AwayScore == 0 {
+			cleanSheets[match.HomeTeamName]++
+		}
+		if match.HomeScore == 0 {
+			cleanSheets[match.AwayTeamName]++
+		}
+
+		for _, side := range []struct {
+			team   string
+			scored int
+			let    int
+		}{
+			{match.HomeTeamName, match.HomeScore, match.AwayScore},
+			{match.AwayTeamName, match.AwayScore, match.HomeScore},
+		} {
+			if side.scored <= side.let {
+				streaks[side.team] = 0
+				continue
+			}
+			streaks[side.team]++
+			if stats.LongestWinStreak == nil || streaks[side.team] > stats.LongestWinStreak.Length {
+				stats.LongestWinStreak = &WinStreak{TeamName: side.team, Length: streaks[side.team]}
+			}
+		}
+	}
+
+	if stats.MatchesPlayed > 0 {
+		stats.AverageGoals = roundTo2(float64(stats.TotalGoals) / float64(stats.MatchesPlayed))
+	}
+
+	for team, count := range cleanSheets {
+		stats.CleanSheets = append(stats.CleanSheets, TeamCleanSheets{TeamName: team, CleanSheets: count})
+	}
+	sort.Slice(stats.CleanSheets, func(i, j int) bool {
+		a, b := stats.CleanSheets[i], stats.CleanSheets[j]
+		if a.CleanSheets != b.CleanSheets {
+			return a.CleanSheets > b.CleanSheets
+		}
+		return a.TeamName < b.TeamName
+	})
+
+	return stats
+}
+
+// winMargin is how many goals a match was won by, 0 for a draw
+func winMargin(match database.Match) int {
+	if match.HomeScore > match.AwayScore {
+		return match.HomeScore - match.AwayScore
+	}
+	return match.AwayScore - match.HomeScore
+}
+
+// getTournamentStats returns the tournament's records and averages
+func (h *Handler) getTournamentStats(w http.ResponseWriter, r *http.Request, code string) {
+	var draftID int
+	err := h.db.Get(&draftID, "SELECT id FROM drafts WHERE code = $1", code)
+	if err != nil {
+		log.Printf("Get draft for stats error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	var participants []database.DraftParticipant
+	err = h.db.Select(&participants, `
+		SELECT `+database.ParticipantColumns+`
+		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
+	`, draftID)
+	if err != nil {
+		log.Printf("Get participants for stats error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var matches []database.Match
+	err = h.db.Select(&matches, `
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE draft_id = $1 AND status = 'confirmed' ORDER BY played_at, id
+	`, draftID)
+	if err != nil {
+		log.Printf("Get matches for stats error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calculateTournamentStats(participants, matches))
+}
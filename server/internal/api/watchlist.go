@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eafc-draft-server/internal/database"
+)
+
+// maxWatchlistSize caps how many players a participant can watch in a draft
+const maxWatchlistSize = 100
+
+// WatchlistEntry is a watched player and whether someone has taken them
+type WatchlistEntry struct {
+	PickPlayer
+	AddedAt time.Time `db:"added_at" json:"addedAt"`
+	Taken   bool      `db:"taken" json:"taken"`
+	TakenBy *string   `db:"taken_by" json:"takenBy"` // participant who picked or kept the player
+}
+
+type WatchlistResponse struct {
+	Watchlist []WatchlistEntry `json:"watchlist"`
+}
+
+type AddToWatchlistRequest struct {
+	ParticipantName string `json:"participantName"`
+	PlayerID        int    `json:"playerId"`
+}
+
+// watchlistTakenMessage tells a participant a watched player is gone
+type watchlistTakenMessage struct {
+	PlayerID int    `json:"playerId"`
+	TakenBy  string `json:"takenBy"`
+}
+
+// handleWatchlist serves a participant's private watchlist. Every request
+// needs the participant's token.
+func (h *Handler) handleWatchlist(w http.ResponseWriter, r *http.Request, code string) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getWatchlist(w, r, code)
+	case http.MethodPost:
+		h.addToWatchlist(w, r, code)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) getWatchlist(w http.ResponseWriter, r *http.Request, code string) {
+	participantName := r.URL.Query().Get("participant")
+	if participantName == "" {
+		http.Error(w, "participant is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := verifyParticipantToken(h.db, code, participantName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	watchlist, err := h.loadWatchlist(code, participantName)
+	if err != nil {
+		log.Printf("Get watchlist error: %v", err)
+		http.Error(w, "Failed to fetch watchlist", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WatchlistResponse{Watchlist: watchlist})
+}
+
+func (h *Handler) addToWatchlist(w http.ResponseWriter, r *http.Request, code string) {
+	var req AddToWatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Add to watchlist decode error: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.ParticipantName == "" || req.PlayerID <= 0 {
+		http.Error(w, "participantName and playerId are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := verifyParticipantToken(h.db, code, req.ParticipantName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	var draft database.Draft
+	err := h.db.Get(&draft, `
+		SELECT `+database.DraftColumns+`
+		FROM drafts WHERE code = $1
+	`, code)
+	if err != nil {
+		log.Printf("Get draft for watchlist error: %v", err)
+		http.Error(w, "Draft not found", http.StatusNotFound)
+		return
+	}
+
+	var exists bool
+	err = h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM players WHERE id = $1 AND dataset = $2)", req.PlayerID, draft.Dataset)
+	if err != nil {
+		log.Printf("Check watchlist player error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Player not found", http.StatusBadRequest)
+		return
+	}
+
+	var participantID, watched int
+	err = h.db.Get(&participantID, "SELECT id FROM draft_participants WHERE draft_id = $1 AND name = $2", draft.ID, req.ParticipantName)
+	if err == nil {
+		err = h.db.Get(&watched, "SELECT COUNT(*) FROM draft_watchlist WHERE participant_id = $1", participantID)
+	}
+	if err != nil {
+		log.Printf("Get watchlist participant error: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if watched >= maxWatchlistSize {
+		http.Error(w, "Watchlist is full", http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.db.Exec(`
+		INSERT INTO draft_watchlist (draft_id, participant_id, player_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (participant_id, player_id) DO NOTHING
+	`, draft.ID, participantID, req.PlayerID)
+	if err != nil {
+		log.Printf("Add to watchlist error: %v", err)
+		http.Error(w, "Failed to update watchlist", http.StatusInternalServerError)
+		return
+	}
+
+	h.respondWithWatchlist(w, code, req.ParticipantName)
+}
+
+// removeFromWatchlist handles DELETE /api/drafts/{code}/watchlist/{playerId}?participant=
+func (h *Handler) removeFromWatchlist(w http.ResponseWriter, r *http.Request, code, playerParam string) {
+	playerID, err := strconv.Atoi(playerParam)
+	if err != nil {
+		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+		return
+	}
+
+	participantName := r.URL.Query().Get("participant")
+	if participantName == "" {
+		http.Error(w, "participant is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := verifyParticipantToken(h.db, code, participantName, participantToken(r)); err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	_, err = h.db.Exec(`
+		DELETE FROM draft_watchlist wl
+		USING draft_participants part, drafts d
+		WHERE wl.participant_id = part.id AND part.draft_id = d.id
+		  AND d.code = $1 AND part.name = $2 AND wl.player_id = $3
+	`, code, participantName, playerID)
+	if err != nil {
+		log.Printf("Remove from watchlist error: %v", err)
+		http.Error(w, "Failed to update watchlist", http.StatusInternalServerError)
+		return
+	}
+
+	h.respondWithWatchlist(w, code, participantName)
+}
+
+// respondWithWatchlist returns the updated watchlist and syncs it to the
+// participant's other connected clients
+func (h *Handler) respondWithWatchlist(w http.ResponseWriter, code, participantName string) {
+	watchlist, err := h.loadWatchlist(code, participantName)
+	if err != nil {
+		log.Printf("Get watchlist error: %v", err)
+		http.Error(w, "Failed to fetch watchlist", http.StatusInternalServerError)
+		return
+	}
+
+	sendParticipantMessage(code, participantName, "watchlist", WatchlistResponse{Watchlist: watchlist})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WatchlistResponse{Watchlist: watchlist})
+}
+
+func (h *Handler) loadWatchlist(code, participantName string) ([]WatchlistEntry, error) {
+	watchlist := []WatchlistEntry{}
+	err := h.db.Select(&watchlist, `
+		SELECT p.id, p.first_name, p.last_name, p.common_name, p.overall_rating, p.position_short_label,
+		       p.alternate_positions, p.team_label, p.team_image_url, p.league_name, p.nationality_label,
+		       p.nationality_image_url, p.avatar_url, p.shield_url, wl.added_at,
+		       COALESCE(picker.name, keeper.name) IS NOT NULL AS taken,
+		       COALESCE(picker.name, keeper.name) AS taken_by
+		FROM draft_watchlist wl
+		JOIN draft_participants part ON wl.participant_id = part.id
+		JOIN drafts d ON part.draft_id = d.id
+		JOIN players p ON wl.player_id = p.id AND p.dataset = d.dataset
+		LEFT JOIN draft_picks dp ON dp.draft_id = d.id AND dp.player_id = wl.player_id
+		LEFT JOIN draft_participants picker ON dp.participant_id = picker.id
+		LEFT JOIN draft_keepers k ON k.draft_id = d.id AND k.player_id = wl.player_id
+		LEFT JOIN draft_participants keeper ON k.participant_id = keeper.id
+		WHERE d.code = $1 AND part.name = $2
+		ORDER BY wl.added_at, p.id
+	`, code, participantName)
+	return watchlist, err
+}
+
+// notifyWatchlists tells everyone but the picker who was watching a player
+// that it has just been picked
+func (h *Handler) notifyWatchlists(draftCode string, draftID, playerID int, pickedBy string) {
+	var watchers []string
+	err := h.db.Select(&watchers, `
+		SELECT part.name
+		FROM draft_watchlist wl
+		JOIN draft_participants part ON wl.participant_id = part.id
+		WHERE wl.draft_id = $1 AND wl.player_id = $2 AND part.name <> $3
+	`, draftID, playerID, pickedBy)
+	if err != nil {
+		log.Printf("Get watchlist watchers error: %v", err)
+		return
+	}
+
+	for _, watcher := range watchers {
+		sendParticipantMessage(draftCode, watcher, "watchlistTaken", watchlistTakenMessage{
+			PlayerID: playerID,
+			TakenBy:  pickedBy,
+		})
+	}
+}
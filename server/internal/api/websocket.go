@@ -2,11 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"eafc-draft-server/internal/database"
 
@@ -14,8 +19,36 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// Heartbeat timings. The server pings every pingPeriod and drops a client
+// that hasn't answered within pongWait, so connections left behind by
+// sleeping laptops don't pile up in rooms.
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = (pongWait * 9) / 10
+)
+
+// Messages smaller than compressionThreshold are sent uncompressed, as
+// deflating them costs more than it saves. Draft states with dozens of picks
+// shrink several times over.
+const (
+	compressionThreshold = 512
+	compressionLevel     = 5
+)
+
+// replayBufferSize is how many recent broadcasts a room keeps for clients
+// resuming after a dropped connection
+const replayBufferSize = 256
+
+// emptyRoomGracePeriod is how long a room outlives its last client, so a
+// refresh doesn't lose the replay history. Rooms of finished drafts close as
+// soon as they are empty.
+const emptyRoomGracePeriod = 2 * time.Minute
+
 func createUpgrader(allowedOrigin string) websocket.Upgrader {
 	return websocket.Upgrader{
+		Subprotocols:      []string{msgpackSubprotocol},
+		EnableCompression: true, // permessage-deflate, if the client offers it
 		CheckOrigin: func(r *http.Request) bool {
 			origin := r.Header.Get("Origin")
 			// Allow configured origin, local files, and development
@@ -24,37 +57,100 @@ func createUpgrader(allowedOrigin string) websocket.Upgrader {
 	}
 }
 
-// DraftRoom manages all connections for a specific draft
+// DraftRoom manages all connections for a specific draft. The clients map is
+// owned by the run goroutine; every other goroutine talks to the room through
+// its channels, so client send channels are only ever written and closed there.
+// Once the room closes, done is closed and anything sent to it is dropped.
 type DraftRoom struct {
 	DraftCode  string
-	Clients    map[*websocket.Conn]*DraftClient
 	Broadcast  chan []byte
 	Register   chan *DraftClient
 	Unregister chan *DraftClient
-	mutex      sync.RWMutex
+	commands   chan func()
+	clients    map[*DraftClient]*roomMember
+	done       chan struct{}
+
+	// Closes the room when it has stayed empty, and whether its draft is
+	// finished so it needn't wait
+	idleTimer *time.Timer
+	finished  bool
+
+	// Every broadcast is stamped with the next sequence number and kept in
+	// history, indexed by sequence modulo replayBufferSize
+	seq     uint64
+	history [replayBufferSize][]byte
+
+	// The participants last announced as online
+	presence []string
+}
+
+// roomMember is the room's own view of a client's identity
+type roomMember struct {
+	participantName string
+	spectator       bool
+	verified        bool   // joined with the participant's token
+	joinedSeq       uint64 // the room's sequence when the client connected
 }
 
-// DraftClient represents a connected client
+// DraftClient represents a connected client. ParticipantName, Spectator and
+// token are only touched by the client's readPump goroutine.
 type DraftClient struct {
 	Conn            *websocket.Conn
 	Room            *DraftRoom
 	ParticipantName string
+	Spectator       bool // read-only client watching the draft
 	Send            chan []byte
+
+	// The token the client joined with. Picks are made as ParticipantName
+	// while it stays valid, so it isn't sent with each message.
+	token string
+
+	// The close frame writePump sends once Send is closed, set by the run
+	// goroutine before closing it
+	closeMessage []byte
+
+	// Set when the connection negotiated the msgpack subprotocol. Messages
+	// are then sent as MessagePack binary frames, while the client still
+	// sends JSON.
+	msgpack bool
+
+	// Limits the client's emoji reactions, only used by readPump
+	reactions *tokenBucket
 }
 
 // WebSocket message types
 type WSMessage struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
+	// Set by clients that want an ack or nack for a makePick, ready,
+	// reaction or announce message, and echoed back in it
+	ID string `json:"id,omitempty"`
+}
+
+// AckMessage is the data of an ack or nack, telling a client whether the
+// message with ID was carried out
+type AckMessage struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Error string `json:"error,omitempty"`
 }
 
 type JoinRoomMessage struct {
 	ParticipantName string `json:"participantName"`
+	Token           string `json:"token"` // from joining the draft; watch without one by spectating
+	// The last sequence a reconnecting client saw. The room replays what it
+	// missed instead of sending the full state, if it still can.
+	LastSeq *uint64 `json:"lastSeq,omitempty"`
+}
+
+type SpectateMessage struct {
+	LastSeq *uint64 `json:"lastSeq,omitempty"`
 }
 
 type MakePickMessage struct {
-	ParticipantName string `json:"participantName"`
+	ParticipantName string `json:"participantName"` // optional, must be the client's own name
 	PlayerID        int    `json:"playerId"`
+	Comment         string `json:"comment,omitempty"` // e.g. "revenge pick!"
 }
 
 // Global room manager
@@ -65,6 +161,12 @@ var roomManager = &RoomManager{
 type RoomManager struct {
 	rooms map[string]*DraftRoom
 	mutex sync.RWMutex
+
+	// Open connections, done when their writePump has finished
+	connections sync.WaitGroup
+
+	// Shares messages with other instances, if Redis is configured
+	bus *redisBus
 }
 
 func (rm *RoomManager) getRoom(draftCode string) *DraftRoom {
@@ -75,10 +177,16 @@ func (rm *RoomManager) getRoom(draftCode string) *DraftRoom {
 	if !exists {
 		room = &DraftRoom{
 			DraftCode:  draftCode,
-			Clients:    make(map[*websocket.Conn]*DraftClient),
-			Broadcast:  make(chan []byte),
+			Broadcast:  make(chan []byte, 64),
 			Register:   make(chan *DraftClient),
 			Unregister: make(chan *DraftClient),
+			commands:   make(chan func(), 64),
+			clients:    make(map[*DraftClient]*roomMember),
+			done:       make(chan struct{}),
+			// Starting from the clock means a room recreated after a restart
+			// never reuses a sequence a client has seen. Microseconds stay
+			// below 2^53, so JavaScript clients read them exactly.
+			seq: uint64(time.Now().UnixMicro()),
 		}
 		rm.rooms[draftCode] = room
 		go room.run()
@@ -87,63 +195,600 @@ func (rm *RoomManager) getRoom(draftCode string) *DraftRoom {
 	return room
 }
 
+// route sends an envelope through Redis to every instance, or straight to
+// this instance's clients without it
+func (rm *RoomManager) route(envelope roomEnvelope) {
+	if rm.bus == nil || !rm.bus.publish(envelope) {
+		rm.dispatch(envelope)
+	}
+}
+
+// dispatch delivers an envelope to the clients connected to this instance
+func (rm *RoomManager) dispatch(envelope roomEnvelope) {
+	switch envelope.Kind {
+	case envelopeBroadcast:
+		rm.broadcastLocal(envelope.DraftCode, envelope.Message)
+	case envelopeEphemeral:
+		rm.broadcastEphemeralLocal(envelope.DraftCode, envelope.Message)
+	case envelopeParticipant:
+		rm.sendToParticipantLocal(envelope.DraftCode, envelope.Participant, envelope.Message)
+	case envelopeAll:
+		rm.broadcastToAllLocal(envelope.Message)
+	default:
+		log.Printf("Unknown room envelope kind %q", envelope.Kind)
+	}
+}
+
 // BroadcastToRoom sends a message to all clients in a specific room
 func (rm *RoomManager) BroadcastToRoom(draftCode string, message []byte) {
+	rm.route(roomEnvelope{Kind: envelopeBroadcast, DraftCode: draftCode, Message: message})
+}
+
+func (rm *RoomManager) broadcastLocal(draftCode string, message []byte) {
 	rm.mutex.RLock()
 	room, exists := rm.rooms[draftCode]
 	rm.mutex.RUnlock()
 
 	if exists {
-		select {
-		case room.Broadcast <- message:
-		default:
-			log.Printf("Failed to broadcast to room %s", draftCode)
+		room.send(message)
+	}
+}
+
+// BroadcastToAll sends a message to every client in every room
+func (rm *RoomManager) BroadcastToAll(message []byte) {
+	rm.route(roomEnvelope{Kind: envelopeAll, Message: message})
+}
+
+func (rm *RoomManager) broadcastToAllLocal(message []byte) {
+	rm.mutex.RLock()
+	rooms := make([]*DraftRoom, 0, len(rm.rooms))
+	for _, room := range rm.rooms {
+		rooms = append(rooms, room)
+	}
+	rm.mutex.RUnlock()
+
+	for _, room := range rooms {
+		room.send(message)
+	}
+}
+
+// removeRoom forgets a room that is closing, unless it has been replaced
+func (rm *RoomManager) removeRoom(room *DraftRoom) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	if rm.rooms[room.DraftCode] == room {
+		delete(rm.rooms, room.DraftCode)
+	}
+}
+
+// SetFinished marks whether a draft is over, so its room closes as soon as
+// the last client leaves
+func (rm *RoomManager) SetFinished(draftCode string, finished bool) {
+	rm.mutex.RLock()
+	room, exists := rm.rooms[draftCode]
+	rm.mutex.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	room.do(func() {
+		room.finished = finished
+		if finished && len(room.clients) == 0 {
+			room.close()
 		}
+	})
+}
+
+// send queues a message to broadcast, dropping it if the room has closed
+func (room *DraftRoom) send(message []byte) {
+	select {
+	case room.Broadcast <- message:
+	case <-room.done:
+	}
+}
+
+// register adds a client to the room. It returns false if the room has
+// closed, and the client needs a new one.
+func (room *DraftRoom) register(client *DraftClient) bool {
+	select {
+	case room.Register <- client:
+		return true
+	case <-room.done:
+		return false
 	}
 }
 
+// unregister removes a client, if the room is still open
+func (room *DraftRoom) unregister(client *DraftClient) {
+	select {
+	case room.Unregister <- client:
+	case <-room.done:
+	}
+}
+
+// do queues a command for the run goroutine. It returns false if the room
+// has closed and the command won't run.
+func (room *DraftRoom) do(command func()) bool {
+	select {
+	case room.commands <- command:
+		return true
+	case <-room.done:
+		return false
+	}
+}
+
+// BroadcastEphemeralToRoom sends a message to all clients in a room without
+// sequencing it or keeping it for replay, for messages such as clock ticks
+// that are stale by the time a client could resume
+func (rm *RoomManager) BroadcastEphemeralToRoom(draftCode string, message []byte) {
+	rm.route(roomEnvelope{Kind: envelopeEphemeral, DraftCode: draftCode, Message: message})
+}
+
+func (rm *RoomManager) broadcastEphemeralLocal(draftCode string, message []byte) {
+	rm.mutex.RLock()
+	room, exists := rm.rooms[draftCode]
+	rm.mutex.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	room.do(func() {
+		for client := range room.clients {
+			room.deliver(client, message)
+		}
+	})
+}
+
+// SendToClient queues a message for a single client of the room. The message
+// is dropped if the client has already left.
+func (room *DraftRoom) SendToClient(client *DraftClient, message []byte) {
+	room.do(func() {
+		room.deliver(client, message)
+	})
+}
+
+// SendToParticipant queues a message for every client verified as the named
+// participant, such as their other tabs and devices
+func (room *DraftRoom) SendToParticipant(participantName string, message []byte) {
+	room.do(func() {
+		for client, member := range room.clients {
+			if member.verified && member.participantName == participantName {
+				room.deliver(client, message)
+			}
+		}
+	})
+}
+
+// Identify records who a client is so the room can address and count it.
+// Verified clients proved their identity with the participant's token.
+func (room *DraftRoom) Identify(client *DraftClient, participantName string, spectator, verified bool) {
+	room.do(func() {
+		member, ok := room.clients[client]
+		if !ok {
+			return
+		}
+
+		wasSpectator := member.spectator
+		member.participantName = participantName
+		member.spectator = spectator
+		member.verified = verified
+		if wasSpectator != spectator {
+			room.broadcastSpectatorCount()
+		}
+		room.updatePresence()
+	})
+}
+
+// Online returns the names of the participants with a connection open to the
+// room
+func (room *DraftRoom) Online() []string {
+	result := make(chan []string, 1)
+	room.do(func() {
+		result <- room.onlineParticipants()
+	})
+	select {
+	case online := <-result:
+		return online
+	case <-room.done:
+		return []string{}
+	}
+}
+
+// onlineParticipants must only be called from the run goroutine
+func (room *DraftRoom) onlineParticipants() []string {
+	online := []string{}
+	for _, member := range room.clients {
+		if member.spectator || member.participantName == "" || slices.Contains(online, member.participantName) {
+			continue
+		}
+		online = append(online, member.participantName)
+	}
+	sort.Strings(online)
+	return online
+}
+
+// updatePresence tells every client who is online when that has changed since
+// it was last announced. Must only be called from the run goroutine.
+func (room *DraftRoom) updatePresence() {
+	online := room.onlineParticipants()
+	if slices.Equal(online, room.presence) {
+		return
+	}
+
+	// Announce who came and went, for join and leave toasts. Further tabs of
+	// a participant already online don't count.
+	for _, name := range online {
+		if !slices.Contains(room.presence, name) {
+			room.broadcastParticipantConnection("participantConnected", name)
+		}
+	}
+	for _, name := range room.presence {
+		if !slices.Contains(online, name) {
+			room.broadcastParticipantConnection("participantDisconnected", name)
+		}
+	}
+	room.presence = online
+
+	data, err := json.Marshal(WSMessage{
+		Type: "presence",
+		Data: map[string][]string{"online": online},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal presence: %v", err)
+		return
+	}
+	room.broadcast(data)
+}
+
+// SpectatorCount returns the number of spectators watching the room
+func (room *DraftRoom) SpectatorCount() int {
+	result := make(chan int, 1)
+	room.do(func() {
+		result <- room.spectatorCount()
+	})
+	select {
+	case count := <-result:
+		return count
+	case <-room.done:
+		return 0
+	}
+}
+
+// spectatorCount must only be called from the run goroutine
+func (room *DraftRoom) spectatorCount() int {
+	count := 0
+	for _, member := range room.clients {
+		if member.spectator {
+			count++
+		}
+	}
+	return count
+}
+
+// broadcastSpectatorCount tells every client how many spectators are
+// watching. Must only be called from the run goroutine.
+func (room *DraftRoom) broadcastSpectatorCount() {
+	data, err := json.Marshal(WSMessage{
+		Type: "spectators",
+		Data: map[string]int{"spectatorCount": room.spectatorCount()},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal spectator count: %v", err)
+		return
+	}
+	room.broadcast(data)
+}
+
+// broadcastParticipantConnection sends a participantConnected or
+// participantDisconnected event. Must only be called from the run goroutine.
+func (room *DraftRoom) broadcastParticipantConnection(msgType, participantName string) {
+	data, err := json.Marshal(WSMessage{
+		Type: msgType,
+		Data: map[string]string{"participantName": participantName},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal %s: %v", msgType, err)
+		return
+	}
+	room.broadcast(data)
+}
+
+// broadcast stamps a message with the room's next sequence, keeps it for
+// replay and sends it to every client. Must only be called from the run
+// goroutine.
+func (room *DraftRoom) broadcast(message []byte) {
+	room.seq++
+	message = stampSequence(message, room.seq)
+	room.history[room.seq%replayBufferSize] = message
+
+	// Transcode once for all the MessagePack clients
+	var packed []byte
+	for client := range room.clients {
+		if !client.msgpack {
+			room.push(client, message)
+			continue
+		}
+		if packed == nil {
+			var err error
+			if packed, err = jsonToMsgpack(message); err != nil {
+				log.Printf("Failed to encode broadcast as msgpack: %v", err)
+				continue
+			}
+		}
+		room.push(client, packed)
+	}
+}
+
+// stampSequence adds a seq field to a marshaled WSMessage without decoding
+// it again, as state broadcasts can be large
+func stampSequence(message []byte, seq uint64) []byte {
+	if len(message) < 2 || message[0] != '{' {
+		return message
+	}
+	prefix := fmt.Sprintf(`{"seq":%d,`, seq)
+	if message[1] == '}' {
+		prefix = fmt.Sprintf(`{"seq":%d`, seq)
+	}
+	return append([]byte(prefix), message[1:]...)
+}
+
+// Replay resends the broadcasts a reconnecting client missed between lastSeq
+// and connecting again. It returns false when they are no longer all kept,
+// and the client needs the full state instead. Broadcasts since the client
+// connected have already reached it, so clients order messages by seq.
+func (room *DraftRoom) Replay(client *DraftClient, lastSeq uint64) bool {
+	result := make(chan bool, 1)
+	room.do(func() {
+		member, ok := room.clients[client]
+		if !ok || lastSeq > member.joinedSeq || member.joinedSeq-lastSeq > replayBufferSize {
+			result <- false
+			return
+		}
+		for seq := lastSeq + 1; seq <= member.joinedSeq; seq++ {
+			if room.history[seq%replayBufferSize] == nil {
+				result <- false
+				return
+			}
+		}
+		for seq := lastSeq + 1; seq <= member.joinedSeq; seq++ {
+			room.deliver(client, room.history[seq%replayBufferSize])
+		}
+		result <- true
+	})
+	select {
+	case replayed := <-result:
+		return replayed
+	case <-room.done:
+		return false
+	}
+}
+
+// SendToParticipant queues a message for every client that joined a room as
+// the participant with their token. Private data such as watchlists is only
+// sent this way.
+func (rm *RoomManager) SendToParticipant(draftCode, participantName string, message []byte) {
+	rm.route(roomEnvelope{Kind: envelopeParticipant, DraftCode: draftCode, Participant: participantName, Message: message})
+}
+
+func (rm *RoomManager) sendToParticipantLocal(draftCode, participantName string, message []byte) {
+	rm.mutex.RLock()
+	room, exists := rm.rooms[draftCode]
+	rm.mutex.RUnlock()
+
+	if !exists {
+		return
+	}
+	room.SendToParticipant(participantName, message)
+}
+
+// Online returns the participants connected to a draft's room, if any
+func (rm *RoomManager) Online(draftCode string) []string {
+	rm.mutex.RLock()
+	room, exists := rm.rooms[draftCode]
+	rm.mutex.RUnlock()
+
+	if !exists {
+		return []string{}
+	}
+	return room.Online()
+}
+
+// SpectatorCount returns the number of spectators in a draft's room, if any
+func (rm *RoomManager) SpectatorCount(draftCode string) int {
+	rm.mutex.RLock()
+	room, exists := rm.rooms[draftCode]
+	rm.mutex.RUnlock()
+
+	if !exists {
+		return 0
+	}
+	return room.SpectatorCount()
+}
+
+// sendMessage marshals a typed message and queues it for this client only
+func (client *DraftClient) sendMessage(msgType string, data interface{}) {
+	msg := WSMessage{Type: msgType, Data: data}
+	if msgData, err := json.Marshal(msg); err == nil {
+		client.Room.SendToClient(client, msgData)
+	} else {
+		log.Printf("Failed to marshal %s message: %v", msgType, err)
+	}
+}
+
+// acknowledge answers a message that asked for it with an ack, or a nack
+// carrying the error if it failed
+func (client *DraftClient) acknowledge(message WSMessage, err error) {
+	if message.ID == "" {
+		return
+	}
+	if err != nil {
+		client.sendMessage("nack", AckMessage{ID: message.ID, Type: message.Type, Error: err.Error()})
+		return
+	}
+	client.sendMessage("ack", AckMessage{ID: message.ID, Type: message.Type})
+}
+
+// broadcastMessage marshals a typed message and sends it to every client in a room
+func broadcastMessage(draftCode, msgType string, data interface{}) {
+	msg := WSMessage{Type: msgType, Data: data}
+	if msgData, err := json.Marshal(msg); err == nil {
+		roomManager.BroadcastToRoom(draftCode, msgData)
+	} else {
+		log.Printf("Failed to marshal %s message: %v", msgType, err)
+	}
+}
+
+// broadcastEphemeralMessage marshals a typed message and sends it to every
+// client in a room, outside the replayed sequence
+func broadcastEphemeralMessage(draftCode, msgType string, data interface{}) {
+	msg := WSMessage{Type: msgType, Data: data}
+	if msgData, err := json.Marshal(msg); err == nil {
+		roomManager.BroadcastEphemeralToRoom(draftCode, msgData)
+	} else {
+		log.Printf("Failed to marshal %s message: %v", msgType, err)
+	}
+}
+
+// sendParticipantMessage marshals a typed message and sends it to one
+// participant's verified clients
+func sendParticipantMessage(draftCode, participantName, msgType string, data interface{}) {
+	msg := WSMessage{Type: msgType, Data: data}
+	if msgData, err := json.Marshal(msg); err == nil {
+		roomManager.SendToParticipant(draftCode, participantName, msgData)
+	} else {
+		log.Printf("Failed to marshal %s message: %v", msgType, err)
+	}
+}
+
+// deliver sends a message to a client without blocking, dropping clients that
+// can't keep up. Must only be called from the run goroutine.
+func (room *DraftRoom) deliver(client *DraftClient, message []byte) {
+	if _, ok := room.clients[client]; !ok {
+		return
+	}
+
+	if client.msgpack {
+		packed, err := jsonToMsgpack(message)
+		if err != nil {
+			log.Printf("Failed to encode message as msgpack: %v", err)
+			return
+		}
+		message = packed
+	}
+	room.push(client, message)
+}
+
+// push queues an encoded message for a client, dropping the client if its
+// queue is full. Must only be called from the run goroutine.
+func (room *DraftRoom) push(client *DraftClient, message []byte) {
+	if _, ok := room.clients[client]; !ok {
+		return
+	}
+
+	select {
+	case client.Send <- message:
+	default:
+		log.Printf("Client %s in draft room %s is not keeping up, dropping", room.clients[client].participantName, room.DraftCode)
+		room.removeClient(client)
+	}
+}
+
+// removeClient deletes a client and closes its send channel, which stops its
+// writePump. Must only be called from the run goroutine.
+func (room *DraftRoom) removeClient(client *DraftClient) {
+	if _, ok := room.clients[client]; !ok {
+		return
+	}
+
+	member := room.clients[client]
+	log.Printf("Client %s left draft room %s", member.participantName, room.DraftCode)
+	delete(room.clients, client)
+	close(client.Send)
+
+	if member.spectator {
+		room.broadcastSpectatorCount()
+	}
+	room.updatePresence()
+
+	if len(room.clients) == 0 {
+		room.closeWhenIdle()
+	}
+}
+
+// closeWhenIdle closes an empty room straight away if its draft is finished,
+// or after the grace period if nobody has joined by then. Must only be called
+// from the run goroutine.
+func (room *DraftRoom) closeWhenIdle() {
+	if room.finished {
+		room.close()
+		return
+	}
+
+	room.idleTimer = time.AfterFunc(emptyRoomGracePeriod, func() {
+		room.do(func() {
+			if len(room.clients) == 0 {
+				room.close()
+			}
+		})
+	})
+}
+
+// close removes the room from the manager and stops its run goroutine. Must
+// only be called from the run goroutine.
+func (room *DraftRoom) close() {
+	select {
+	case <-room.done:
+		return
+	default:
+	}
+
+	roomManager.removeRoom(room)
+	close(room.done)
+	if room.idleTimer != nil {
+		room.idleTimer.Stop()
+	}
+	log.Printf("Closed empty draft room %s", room.DraftCode)
+}
+
 func (room *DraftRoom) run() {
 	for {
+		// Stop as soon as the room has closed, so no client can register
+		// with it afterwards
+		select {
+		case <-room.done:
+			return
+		default:
+		}
+
 		select {
 		case client := <-room.Register:
-			room.mutex.Lock()
-			room.Clients[client.Conn] = client
-			room.mutex.Unlock()
-			log.Printf("Client %s joined draft room %s", client.ParticipantName, room.DraftCode)
+			if room.idleTimer != nil {
+				room.idleTimer.Stop()
+				room.idleTimer = nil
+			}
+			room.clients[client] = &roomMember{participantName: client.ParticipantName, joinedSeq: room.seq}
+			log.Printf("Client joined draft room %s", room.DraftCode)
 
 			// Send join confirmation
 			joinMsg := WSMessage{
 				Type: "joined",
-				Data: map[string]string{"participantName": client.ParticipantName},
+				Data: map[string]interface{}{"participantName": client.ParticipantName, "seq": room.seq},
 			}
 			if data, err := json.Marshal(joinMsg); err == nil {
-				select {
-				case client.Send <- data:
-				default:
-					close(client.Send)
-				}
+				room.deliver(client, data)
 			}
 
 		case client := <-room.Unregister:
-			room.mutex.Lock()
-			if _, ok := room.Clients[client.Conn]; ok {
-				delete(room.Clients, client.Conn)
-				close(client.Send)
-				log.Printf("Client %s left draft room %s", client.ParticipantName, room.DraftCode)
-			}
-			room.mutex.Unlock()
+			room.removeClient(client)
 
 		case message := <-room.Broadcast:
-			room.mutex.RLock()
-			for conn, client := range room.Clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(room.Clients, conn)
-				}
-			}
-			room.mutex.RUnlock()
+			room.broadcast(message)
+
+		case command := <-room.commands:
+			command()
 		}
 	}
 }
@@ -173,48 +818,101 @@ func (h *Handler) handleDraftWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("WebSocket upgraded successfully for draft %s", draftCode)
 
-	// Get or create room for this draft
-	room := roomManager.getRoom(draftCode)
+	if err := conn.SetCompressionLevel(compressionLevel); err != nil {
+		log.Printf("Set websocket compression level error: %v", err)
+	}
 
 	// Create client
 	client := &DraftClient{
-		Conn: conn,
-		Room: room,
-		Send: make(chan []byte, 256),
+		Conn:      conn,
+		Send:      make(chan []byte, 256),
+		msgpack:   conn.Subprotocol() == msgpackSubprotocol,
+		reactions: newTokenBucket(reactionBurst, reactionsPerSecond),
+	}
+
+	// Register client with the draft's room before reading, so its
+	// Unregister can never overtake the Register. A room that closed in the
+	// meantime has been replaced by the next getRoom.
+	for {
+		client.Room = roomManager.getRoom(draftCode)
+		if client.Room.register(client) {
+			break
+		}
 	}
 
 	// Start client goroutines
+	roomManager.connections.Add(1)
 	go client.writePump()
 	go client.readPump(h)
-
-	// Register client with room
-	room.Register <- client
 }
 
 func (client *DraftClient) readPump(h *Handler) {
 	defer func() {
 		log.Printf("Closing readPump for client %s", client.ParticipantName)
-		client.Room.Unregister <- client
+		client.Room.unregister(client)
 		client.Conn.Close()
 	}()
 
+	client.Conn.SetReadLimit(wsMaxMessageSize)
+
+	// Any pong proves the client is still there
+	client.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	client.Conn.SetPongHandler(func(string) error {
+		return client.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	limiter := newTokenBucket(wsMessageBurst, wsMessagesPerSecond)
+	dropped := 0
+	var droppedSince time.Time
+
 	for {
 		var message WSMessage
 		err := client.Conn.ReadJSON(&message)
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				log.Printf("Client %s in draft room %s stopped answering pings, dropping", client.ParticipantName, client.Room.DraftCode)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
 
+		// Drop messages over the limit without logging them, and disconnect a
+		// client that keeps flooding the room
+		if !limiter.allow() {
+			if time.Since(droppedSince) > wsDropWindow {
+				dropped, droppedSince = 0, time.Now()
+			}
+			dropped++
+			if dropped >= wsMaxDroppedMessages {
+				log.Printf("Client %s in draft room %s sent too many messages, disconnecting", client.ParticipantName, client.Room.DraftCode)
+				client.Conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too many messages"),
+					time.Now().Add(writeWait))
+				break
+			}
+			client.acknowledge(message, fmt.Errorf("too many messages, slow down"))
+			continue
+		}
+
 		log.Printf("Received message type: %s from %s", message.Type, client.ParticipantName)
 
 		switch message.Type {
 		case "join":
 			h.handleJoinRoom(client, message.Data)
+		case "spectate":
+			h.handleSpectate(client, message.Data)
 		case "makePick":
-			h.handleMakePick(client, message.Data, h)
+			client.acknowledge(message, h.handleMakePick(client, message.Data, h))
+		case "chat":
+			h.handleChat(client, message.Data)
+		case "ready":
+			client.acknowledge(message, h.handleReady(client, message.Data))
+		case "reaction":
+			client.acknowledge(message, h.handleReaction(client, message.Data))
+		case "announce":
+			client.acknowledge(message, h.handleAnnounce(client, message.Data))
 		default:
 			log.Printf("Unknown message type: %s", message.Type)
 		}
@@ -222,23 +920,45 @@ func (client *DraftClient) readPump(h *Handler) {
 }
 
 func (client *DraftClient) writePump() {
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
-		log.Printf("Closing writePump for client %s", client.ParticipantName)
+		ticker.Stop()
+		log.Printf("Closing writePump for client in draft %s", client.Room.DraftCode)
 		client.Conn.Close()
+		roomManager.connections.Done()
 	}()
 
 	for {
 		select {
 		case message, ok := <-client.Send:
+			client.Conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
-				client.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				closeMessage := client.closeMessage
+				if closeMessage == nil {
+					closeMessage = []byte{}
+				}
+				client.Conn.WriteMessage(websocket.CloseMessage, closeMessage)
 				return
 			}
 
-			if err := client.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			frameType := websocket.TextMessage
+			if client.msgpack {
+				frameType = websocket.BinaryMessage
+			}
+			client.Conn.EnableWriteCompression(len(message) >= compressionThreshold)
+			if err := client.Conn.WriteMessage(frameType, message); err != nil {
 				log.Printf("Write message error: %v", err)
 				return
 			}
+
+		case <-ticker.C:
+			// Closing the connection ends the readPump, which unregisters
+			// the client
+			client.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := client.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("Ping client in draft %s error: %v", client.Room.DraftCode, err)
+				return
+			}
 		}
 	}
 }
@@ -256,31 +976,112 @@ func (h *Handler) handleJoinRoom(client *DraftClient, data interface{}) {
 		return
 	}
 
+	if joinMsg.Token == "" {
+		client.sendMessage("joinError", map[string]string{"error": "a participant token is required to join, spectate to watch"})
+		return
+	}
+	if err := verifyParticipantToken(h.db, client.Room.DraftCode, joinMsg.ParticipantName, joinMsg.Token); err != nil {
+		client.sendMessage("joinError", map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Bind the connection to the participant it proved to be
 	client.ParticipantName = joinMsg.ParticipantName
+	client.Spectator = false
+	client.token = joinMsg.Token
+	client.Room.Identify(client, joinMsg.ParticipantName, false, true)
 	log.Printf("Client identified as %s in draft %s", client.ParticipantName, client.Room.DraftCode)
 
 	// Send current draft state to the newly joined client
+	h.syncClient(client, joinMsg.LastSeq)
+
+	// Show the maintenance banner to clients joining mid-maintenance
+	if enabled, message := h.maintenance.get(); enabled {
+		client.Room.SendToClient(client, maintenanceMessage(enabled, message))
+	}
+
+	// Likewise the admin's announcement, if one is still up
+	h.sendLatestAnnouncement(client)
+}
+
+// syncClient catches a client up, replaying what it missed if it is resuming
+// and the room still has it, or sending the full draft state otherwise
+func (h *Handler) syncClient(client *DraftClient, lastSeq *uint64) {
+	if lastSeq != nil && client.Room.Replay(client, *lastSeq) {
+		log.Printf("Client %s resumed draft %s from seq %d", client.ParticipantName, client.Room.DraftCode, *lastSeq)
+		return
+	}
 	h.sendDraftState(client)
 }
 
-func (h *Handler) handleMakePick(client *DraftClient, data interface{}, handler *Handler) {
+// handleSpectate marks a client as a read-only spectator of the draft
+func (h *Handler) handleSpectate(client *DraftClient, data interface{}) {
+	var spectateMsg SpectateMessage
+	if dataBytes, err := json.Marshal(data); err == nil {
+		if err := json.Unmarshal(dataBytes, &spectateMsg); err != nil {
+			log.Printf("Spectate unmarshal error: %v", err)
+		}
+	}
+
+	client.ParticipantName = ""
+	client.Spectator = true
+	client.token = ""
+	client.Room.Identify(client, "", true, false)
+	log.Printf("Client is spectating draft %s", client.Room.DraftCode)
+
+	h.syncClient(client, spectateMsg.LastSeq)
+
+	if enabled, message := h.maintenance.get(); enabled {
+		client.Room.SendToClient(client, maintenanceMessage(enabled, message))
+	}
+	h.sendLatestAnnouncement(client)
+}
+
+func (h *Handler) handleMakePick(client *DraftClient, data interface{}, handler *Handler) error {
+	if client.Spectator {
+		client.sendMessage("pickError", map[string]string{"error": "spectators cannot make picks"})
+		return fmt.Errorf("spectators cannot make picks")
+	}
+
 	dataBytes, err := json.Marshal(data)
 	if err != nil {
 		log.Printf("Make pick marshal error: %v", err)
-		return
+		return fmt.Errorf("invalid pick message")
 	}
 
 	var pickMsg MakePickMessage
 	if err := json.Unmarshal(dataBytes, &pickMsg); err != nil {
 		log.Printf("Make pick unmarshal error: %v", err)
-		return
+		return fmt.Errorf("invalid pick message")
+	}
+
+	// Clients can only pick as the participant they joined as
+	if client.ParticipantName == "" {
+		err = fmt.Errorf("join the room before picking")
+	} else if pickMsg.ParticipantName != "" && pickMsg.ParticipantName != client.ParticipantName {
+		err = fmt.Errorf("you can only pick for yourself")
+	} else {
+		pickMsg.ParticipantName = client.ParticipantName
 	}
 
 	log.Printf("Pick attempt: %s wants to pick player %d in draft %s",
-		pickMsg.ParticipantName, pickMsg.PlayerID, client.Room.DraftCode)
+		client.ParticipantName, pickMsg.PlayerID, client.Room.DraftCode)
 
-	// Process the pick
-	err = h.processPick(client.Room.DraftCode, pickMsg.ParticipantName, pickMsg.PlayerID)
+	// Process the pick while the token the client joined with is still valid,
+	// as substituting a participant revokes it
+	if err == nil {
+		err = verifyParticipantToken(h.db, client.Room.DraftCode, client.ParticipantName, client.token)
+	}
+	if err == nil {
+		err = validatePickComment(pickMsg.Comment)
+	}
+	if err == nil && h.isBlindDraft(client.Room.DraftCode) {
+		return h.handleBlindPick(client, pickMsg)
+	}
+	var made *madePick
+	if err == nil {
+		made, err = h.processPick(client.Room.DraftCode, pickMsg.ParticipantName, pickMsg.PlayerID, pickMsg.Comment)
+	}
 	if err != nil {
 		// Send error to the specific client
 		errorMsg := WSMessage{
@@ -288,116 +1089,167 @@ func (h *Handler) handleMakePick(client *DraftClient, data interface{}, handler
 			Data: map[string]string{"error": err.Error()},
 		}
 		if errorData, marshalErr := json.Marshal(errorMsg); marshalErr == nil {
-			select {
-			case client.Send <- errorData:
-			default:
-				log.Printf("Failed to send error to client")
-			}
+			client.Room.SendToClient(client, errorData)
 		}
-		return
+		return err
 	}
 
-	// If pick successful, broadcast updated draft state to all clients
-	BroadcastDraftStateToRoom(h.db, client.Room.DraftCode)
+	// If pick successful, send it to all clients
+	broadcastPickMade(h.db, client.Room.DraftCode, made)
+
+	// Bots on the clock pick next
+	go h.runBotPicks(client.Room.DraftCode)
+	return nil
 }
 
-func (h *Handler) processPick(draftCode, participantName string, playerID int) error {
+func (h *Handler) processPick(draftCode, participantName string, playerID int, comment string) (*madePick, error) {
+	if err := h.maintenanceError(); err != nil {
+		return nil, err
+	}
+
+	// Let the pick commit before the server shuts down
+	if !h.shutdown.startPick() {
+		return nil, shuttingDownError()
+	}
+	defer h.shutdown.finishPick()
+
 	// Start transaction
 	tx, err := h.db.Beginx()
 	if err != nil {
 		log.Printf("Begin pick transaction error: %v", err)
-		return fmt.Errorf("database error")
+		return nil, fmt.Errorf("database error")
 	}
 	defer tx.Rollback()
 
 	// Get draft with lock
 	var draft database.Draft
 	err = tx.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1 FOR UPDATE
 	`, draftCode)
 	if err != nil {
 		log.Printf("Get draft for pick error: %v", err)
-		return fmt.Errorf("draft not found")
+		return nil, fmt.Errorf("draft not found")
 	}
 
 	if draft.Status != "active" {
-		return fmt.Errorf("draft is not active")
+		return nil, fmt.Errorf("draft is not active")
 	}
 
 	// Get participant making the pick
 	var participant database.DraftParticipant
 	err = tx.Get(&participant, `
-		SELECT id, draft_id, name, draft_order, is_admin, joined_at, 
-		       picks_85_89, picks_80_84, picks_75_79, picks_up_to_74
+		SELECT `+database.ParticipantColumns+`
 		FROM draft_participants WHERE draft_id = $1 AND name = $2
 	`, draft.ID, participantName)
 	if err != nil {
-		return fmt.Errorf("participant not found")
+		return nil, fmt.Errorf("participant not found")
 	}
 
 	// Calculate whose turn it is
-	currentPicker := h.calculateCurrentPicker(draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+	currentPicker := h.calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
 	if participant.DraftOrder != currentPicker {
-		return fmt.Errorf("not your turn (it's player %d's turn)", currentPicker)
+		return nil, fmt.Errorf("not your turn (it's player %d's turn)", currentPicker)
 	}
 
 	// Get player details
 	var player database.Player
-	err = tx.Get(&player, "SELECT id, overall_rating FROM players WHERE id = $1", playerID)
+	err = tx.Get(&player, `
+		SELECT id, overall_rating, position_short_label, league_name, nationality_label, gender, card_type,
+		       first_name, last_name, common_name, alternate_positions, team_label, team_image_url,
+		       nationality_image_url, avatar_url, shield_url
+		FROM players WHERE id = $1 AND dataset = $2
+	`, playerID, draft.Dataset)
 	if err != nil {
-		return fmt.Errorf("player not found")
+		return nil, fmt.Errorf("player not found")
 	}
 
 	if player.OverallRating == nil {
-		return fmt.Errorf("player has no rating")
+		return nil, fmt.Errorf("player has no rating")
+	}
+
+	// Enforce theme draft restrictions
+	if err := checkDraftPool(draft, player); err != nil {
+		return nil, err
 	}
 
 	// Check if player already picked in this draft
 	var alreadyPicked bool
 	err = tx.Get(&alreadyPicked, "SELECT EXISTS(SELECT 1 FROM draft_picks WHERE draft_id = $1 AND player_id = $2)", draft.ID, playerID)
 	if err != nil {
-		return fmt.Errorf("database error checking duplicates")
+		return nil, fmt.Errorf("database error checking duplicates")
 	}
 	if alreadyPicked {
-		return fmt.Errorf("player already picked in this draft")
+		return nil, fmt.Errorf("player already picked in this draft")
 	}
 
 	// Determine rating tier and validate quota
 	ratingTier := h.getRatingTier(*player.OverallRating)
 	if ratingTier == "invalid" {
-		return fmt.Errorf("cannot pick players rated 90+")
+		return nil, fmt.Errorf("cannot pick players rated 90+")
+	}
+
+	if !h.canPickFromTier(draft, participant, ratingTier) {
+		return nil, h.formatQuotaError(draft, participant, ratingTier)
 	}
 
-	if !h.canPickFromTier(participant, ratingTier) {
-		return h.formatQuotaError(participant, ratingTier)
+	if isGoalkeeper(player.PositionShortLabel) && !canPickGoalkeeper(draft, participant) {
+		return nil, goalkeeperQuotaError(draft, participant)
+	}
+	if !isGoalkeeper(player.PositionShortLabel) && mustPickGoalkeeper(draft, participant) {
+		return nil, goalkeeperMinimumError(draft, participant)
 	}
 
 	// Calculate pick numbers
 	overallPickNumber := (draft.CurrentRound-1)*draft.ParticipantCount + draft.CurrentPickInRound
 
+	// In pack mode only the players dealt for this turn can be picked
+	if draft.PackSize != nil {
+		if err := checkPackPick(tx, draft.ID, overallPickNumber, playerID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Insert pick
-	_, err = tx.Exec(`
+	var pick database.DraftPick
+	err = tx.Get(&pick, `
 		INSERT INTO draft_picks (draft_id, participant_id, player_id, round_number, pick_in_round, 
-		                        overall_pick_number, player_rating_tier) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		                        overall_pick_number, player_rating_tier, comment) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+database.PickColumns+`
 	`, draft.ID, participant.ID, playerID, draft.CurrentRound, draft.CurrentPickInRound,
-		overallPickNumber, ratingTier)
+		overallPickNumber, ratingTier, pickComment(comment))
 	if err != nil {
 		log.Printf("Insert pick error: %v", err)
-		return fmt.Errorf("failed to save pick")
+		return nil, fmt.Errorf("failed to save pick")
+	}
+
+	// The turn's pack has been used
+	if _, err = tx.Exec("DELETE FROM draft_packs WHERE draft_id = $1 AND overall_pick_number = $2", draft.ID, overallPickNumber); err != nil {
+		log.Printf("Delete pack error: %v", err)
+		return nil, fmt.Errorf("failed to save pick")
 	}
 
 	// Update participant quota
 	err = h.updateParticipantQuota(tx, participant.ID, ratingTier)
 	if err != nil {
-		return fmt.Errorf("failed to update quota")
+		return nil, fmt.Errorf("failed to update quota")
+	}
+	if isGoalkeeper(player.PositionShortLabel) {
+		if err := updateGoalkeeperQuota(tx, participant.ID); err != nil {
+			return nil, fmt.Errorf("failed to update quota")
+		}
 	}
 
-	// Calculate next turn
+	// Calculate next turn, skipping slots already filled by keepers
 	nextRound, nextPickInRound := h.calculateNextTurn(draft.CurrentRound, draft.CurrentPickInRound,
 		draft.ParticipantCount, draft.TotalRounds)
+	nextRound, nextPickInRound, err = h.nextOpenSlot(tx, draft.ID, nextRound, nextPickInRound,
+		draft.ParticipantCount, draft.TotalRounds)
+	if err != nil {
+		log.Printf("Find next open slot error: %v", err)
+		return nil, fmt.Errorf("failed to update draft state")
+	}
 
 	// Update draft state
 	var status string
@@ -425,25 +1277,74 @@ func (h *Handler) processPick(draftCode, participantName string, playerID int) e
 	}
 	if err != nil {
 		log.Printf("Update draft state error: %v", err)
-		return fmt.Errorf("failed to update draft state")
+		return nil, fmt.Errorf("failed to update draft state")
+	}
+
+	err = recordDraftEvent(tx, draft.ID, EventPick, participant.Name, pickEvent{
+		ParticipantName:   participant.Name,
+		PlayerID:          playerID,
+		RoundNumber:       draft.CurrentRound,
+		PickInRound:       draft.CurrentPickInRound,
+		OverallPickNumber: overallPickNumber,
+		RatingTier:        ratingTier,
+		Comment:           pickComment(comment),
+	})
+	if err != nil {
+		log.Printf("Record pick event error: %v", err)
+		return nil, fmt.Errorf("failed to save pick")
+	}
+
+	if status == "completed" {
+		if err := recordDraftEvent(tx, draft.ID, EventDraftCompleted, "", nil); err != nil {
+			log.Printf("Record draft event error: %v", err)
+			return nil, fmt.Errorf("failed to update draft state")
+		}
+	}
+
+	// Start the clock for the next pick
+	deadline, err := h.setPickDeadline(tx, draft, status == "active")
+	if err != nil {
+		log.Printf("Update pick deadline error: %v", err)
+		return nil, fmt.Errorf("failed to update draft state")
 	}
 
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		log.Printf("Commit pick transaction error: %v", err)
-		return fmt.Errorf("failed to complete pick")
+		return nil, fmt.Errorf("failed to complete pick")
 	}
 
+	if deadline != nil {
+		h.schedulePickTimer(draftCode, nextRound, nextPickInRound, *deadline)
+	}
+
+	h.notifyWatchlists(draftCode, draft.ID, playerID, participant.Name)
+
 	log.Printf("Pick successful: %s picked player %d (round %d, pick %d)",
 		participantName, playerID, draft.CurrentRound, draft.CurrentPickInRound)
 
-	return nil
+	// Everything the pickMade broadcast needs is already to hand, so it
+	// can go out without reading the pick back
+	countParticipantPick(&participant, ratingTier, isGoalkeeper(player.PositionShortLabel))
+	draft.CurrentRound, draft.CurrentPickInRound = nextRound, nextPickInRound
+	draft.Status = status
+	draft.PickDeadline = deadline
+	if status == "completed" {
+		completed := time.Now()
+		draft.CompletedAt = &completed
+	}
+
+	made := &madePick{
+		Pick:        pickPayload(pick, player, participant.Name),
+		Participant: participant,
+		Draft:       draft,
+	}
+	return made, nil
 }
 
 // calculateCurrentPicker determines whose turn it is based on round and pick
-func (h *Handler) calculateCurrentPicker(round, pickInRound, participantCount int) int {
-	startingPlayer := ((round - 1) % participantCount) + 1
-	return ((startingPlayer + pickInRound - 2) % participantCount) + 1
+func (h *Handler) calculateCurrentPicker(orderMode string, round, pickInRound, participantCount int) int {
+	return calculateCurrentPicker(orderMode, round, pickInRound, participantCount)
 }
 
 // calculateNextTurn determines the next round and pick
@@ -467,15 +1368,15 @@ func (h *Handler) getRatingTier(rating int) string {
 }
 
 // canPickFromTier checks if participant can pick from rating tier
-func (h *Handler) canPickFromTier(participant database.DraftParticipant, tier string) bool {
+func (h *Handler) canPickFromTier(draft database.Draft, participant database.DraftParticipant, tier string) bool {
 	switch tier {
 	case "85-89":
-		return participant.Picks8589 < 1
+		return participant.Picks8589 < draft.Quota8589
 	case "80-84":
-		return participant.Picks8084 < 4
+		return participant.Picks8084 < draft.Quota8084
 	case "75-79":
-		// Combined quota: existing picks from both tiers should not exceed 6
-		return (participant.Picks7579 + participant.PicksUpTo74) < 6
+		// Combined quota: existing picks from both tiers should not exceed the ≤79 quota
+		return (participant.Picks7579 + participant.PicksUpTo74) < draft.QuotaUpTo79
 	default:
 		return false
 	}
@@ -500,16 +1401,67 @@ func (h *Handler) updateParticipantQuota(tx *sqlx.Tx, participantID int, tier st
 	return err
 }
 
+// countParticipantPick mirrors updateParticipantQuota and
+// updateGoalkeeperQuota on an already loaded participant
+func countParticipantPick(participant *database.DraftParticipant, tier string, goalkeeper bool) {
+	switch tier {
+	case "85-89":
+		participant.Picks8589++
+	case "80-84":
+		participant.Picks8084++
+	case "75-79":
+		participant.Picks7579++
+	}
+	if goalkeeper {
+		participant.PicksGK++
+	}
+}
+
+// isGoalkeeper reports whether a position label is a goalkeeper
+func isGoalkeeper(position *string) bool {
+	return position != nil && *position == "GK"
+}
+
+// canPickGoalkeeper checks the goalkeeper quota, which applies on top of the
+// rating tiers
+func canPickGoalkeeper(draft database.Draft, participant database.DraftParticipant) bool {
+	return draft.QuotaGK == 0 || participant.PicksGK < draft.QuotaGK
+}
+
+// mustPickGoalkeeper reports whether every pick the participant has left is
+// needed to reach the draft's goalkeeper minimum. Keepers count as picks.
+func mustPickGoalkeeper(draft database.Draft, participant database.DraftParticipant) bool {
+	if draft.MinGK == 0 {
+		return false
+	}
+	picksMade := participant.Picks8589 + participant.Picks8084 + participant.Picks7579 + participant.PicksUpTo74
+	return draft.MinGK-participant.PicksGK >= draft.TotalRounds-picksMade
+}
+
+// updateGoalkeeperQuota counts a goalkeeper pick against the participant's quota
+func updateGoalkeeperQuota(tx *sqlx.Tx, participantID int) error {
+	_, err := tx.Exec("UPDATE draft_participants SET picks_gk = picks_gk + 1 WHERE id = $1", participantID)
+	return err
+}
+
+func goalkeeperQuotaError(draft database.Draft, participant database.DraftParticipant) error {
+	return fmt.Errorf("quota exceeded: you have %d/%d goalkeepers", participant.PicksGK, draft.QuotaGK)
+}
+
+func goalkeeperMinimumError(draft database.Draft, participant database.DraftParticipant) error {
+	return fmt.Errorf("you must pick a goalkeeper: you have %d of the %d required", participant.PicksGK, draft.MinGK)
+}
+
 // formatQuotaError returns a detailed error message about quota limits
-func (h *Handler) formatQuotaError(participant database.DraftParticipant, tier string) error {
+func (h *Handler) formatQuotaError(draft database.Draft, participant database.DraftParticipant, tier string) error {
 	switch tier {
 	case "85-89":
-		return fmt.Errorf("quota exceeded: you have %d/1 picks for 85-89 rated players", participant.Picks8589)
+		return fmt.Errorf("quota exceeded: you have %d/%d picks for 85-89 rated players", participant.Picks8589, draft.Quota8589)
 	case "80-84":
-		return fmt.Errorf("quota exceeded: you have %d/4 picks for 80-84 rated players", participant.Picks8084)
+		return fmt.Errorf("quota exceeded: you have %d/%d picks for 80-84 rated players", participant.Picks8084, draft.Quota8084)
 	case "75-79":
 		current := participant.Picks7579 + participant.PicksUpTo74
-		return fmt.Errorf("quota exceeded: you have %d/6 picks for players rated 79 or below", current)
+		return fmt.Errorf("quota exceeded: you have %d/%d picks for players rated 79 or below", current, draft.QuotaUpTo79)
 	default:
 		return fmt.Errorf("quota exceeded for rating tier %s", tier)
 	}
@@ -520,8 +1472,7 @@ func BroadcastTournamentStateToRoom(db *sqlx.DB, draftCode string) {
 	// Get current draft state from database
 	var draft database.Draft
 	err := db.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1
 	`, draftCode)
 	if err != nil {
@@ -539,8 +1490,7 @@ func BroadcastTournamentStateToRoom(db *sqlx.DB, draftCode string) {
 	// Get participants
 	var participants []database.DraftParticipant
 	err = db.Select(&participants, `
-		SELECT id, draft_id, name, draft_order, is_admin, joined_at, 
-		       picks_85_89, picks_80_84, picks_75_79, picks_up_to_74
+		SELECT `+database.ParticipantColumns+`
 		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
 	`, draft.ID)
 	if err != nil {
@@ -551,25 +1501,72 @@ func BroadcastTournamentStateToRoom(db *sqlx.DB, draftCode string) {
 	// Get matches
 	var matches []database.Match
 	err = db.Select(&matches, `
-		SELECT id, draft_id, home_team_id, away_team_id, home_team_name, away_team_name,
-		       home_score, away_score, played_at, recorded_by
-		FROM matches WHERE draft_id = $1 ORDER BY played_at DESC
+		SELECT `+database.MatchColumns+`
+		FROM matches WHERE draft_id = $1 AND status = 'confirmed' ORDER BY played_at DESC
 	`, draft.ID)
 	if err != nil {
 		log.Printf("Get matches for tournament broadcast error: %v", err)
 		return
 	}
 
-	// Calculate standings
-	standings := calculateStandingsForBroadcast(participants, matches)
+	pendingMatches, err := loadPendingMatches(db, draft.ID)
+	if err != nil {
+		log.Printf("Get pending matches for tournament broadcast error: %v", err)
+		return
+	}
+
+	goals, err := loadMatchGoals(db, draft.ID, 0)
+	if err != nil {
+		log.Printf("Get match goals for tournament broadcast error: %v", err)
+		return
+	}
+
+	fixtures, err := loadFixtures(db, draft.ID)
+	if err != nil {
+		log.Printf("Get fixtures for tournament broadcast error: %v", err)
+		return
+	}
+
+	bracket, err := loadBracket(db, draft.ID)
+	if err != nil {
+		log.Printf("Get bracket for tournament broadcast error: %v", err)
+		return
+	}
+
+	// Calculate standings, without the matches played in the bracket
+	league := leagueMatches(matches, bracket)
+	standings := calculateStandings(participants, league, draft.TournamentSettings)
+	homeTable, awayTable := homeAwayTables(standings)
+	groups := []map[string]interface{}{}
+	for _, group := range splitGroups(participants, league, fixtures) {
+		groups = append(groups, map[string]interface{}{
+			"name":      group.Name,
+			"standings": calculateStandings(group.Participants, group.Matches, draft.TournamentSettings),
+		})
+	}
+
+	chemistry, err := squadChemistryByParticipant(db, draft.ID, participants)
+	if err != nil {
+		log.Printf("Get squad chemistry for tournament broadcast error: %v", err)
+		return
+	}
 
 	tournamentMsg := WSMessage{
 		Type: "tournamentState",
 		Data: map[string]interface{}{
-			"draft":        draft,
-			"participants": participants,
-			"matches":      matches,
-			"standings":    standings,
+			"draft":          draft,
+			"participants":   participants,
+			"matches":        matches,
+			"pendingMatches": pendingMatches,
+			"goals":          goals,
+			"leaders":        tournamentLeaders(goals),
+			"fixtures":       fixtures,
+			"bracket":        bracket,
+			"groups":         groups,
+			"standings":      standings,
+			"homeTable":      homeTable,
+			"awayTable":      awayTable,
+			"chemistry":      chemistry,
 		},
 	}
 
@@ -585,8 +1582,7 @@ func BroadcastDraftStateToRoom(db *sqlx.DB, draftCode string) {
 	// Get current draft state from database
 	var draft database.Draft
 	err := db.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1
 	`, draftCode)
 	if err != nil {
@@ -597,8 +1593,7 @@ func BroadcastDraftStateToRoom(db *sqlx.DB, draftCode string) {
 	// Get participants
 	var participants []database.DraftParticipant
 	err = db.Select(&participants, `
-		SELECT id, draft_id, name, draft_order, is_admin, joined_at, 
-		       picks_85_89, picks_80_84, picks_75_79, picks_up_to_74
+		SELECT `+database.ParticipantColumns+`
 		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
 	`, draft.ID)
 	if err != nil {
@@ -607,6 +1602,50 @@ func BroadcastDraftStateToRoom(db *sqlx.DB, draftCode string) {
 	}
 
 	// Get picks with player details
+	picks, err := loadDraftPicks(db, draft.ID)
+	if err != nil {
+		log.Printf("Get picks for broadcast error: %v", err)
+		return
+	}
+
+	// Calculate whose turn it is next
+	var currentPicker *int
+	if draft.Status == "active" {
+		picker := calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+		currentPicker = &picker
+	}
+
+	// Show who is holding up the lobby
+	var notReady []string
+	if draft.Status == "waiting" {
+		notReady = notReadyParticipants(participants)
+	}
+
+	stateMsg := WSMessage{
+		Type: "draftState",
+		Data: map[string]interface{}{
+			"draft":          draft,
+			"participants":   participants,
+			"picks":          picks,
+			"currentPicker":  currentPicker,
+			"spectatorCount": roomManager.SpectatorCount(draftCode),
+			"online":         roomManager.Online(draftCode),
+			"notReady":       notReady,
+			"remainingSeats": remainingSeats(draft),
+		},
+	}
+
+	if data, err := json.Marshal(stateMsg); err == nil {
+		roomManager.BroadcastToRoom(draftCode, data)
+		log.Printf("Broadcasted draft state to room %s", draftCode)
+	} else {
+		log.Printf("Failed to marshal draft state: %v", err)
+	}
+}
+
+// loadDraftPicks returns a draft's picks with the player details clients show
+// on the board
+func loadDraftPicks(db *sqlx.DB, draftID int) ([]map[string]interface{}, error) {
 	var picks []map[string]interface{}
 	rows, err := db.Query(`
 		SELECT dp.id, dp.draft_id, dp.participant_id, dp.player_id, dp.round_number, 
@@ -614,45 +1653,49 @@ func BroadcastDraftStateToRoom(db *sqlx.DB, draftCode string) {
 		       p.first_name, p.last_name, p.common_name, p.overall_rating, p.position_short_label,
 		       p.team_label, p.team_image_url, p.nationality_label, p.nationality_image_url, 
 		       p.avatar_url, p.shield_url,
-		       part.name as participant_name
+		       part.name as participant_name, dp.is_keeper, dp.comment
 		FROM draft_picks dp
-		JOIN players p ON dp.player_id = p.id
+		JOIN drafts d ON dp.draft_id = d.id
+		JOIN players p ON dp.player_id = p.id AND p.dataset = d.dataset
 		JOIN draft_participants part ON dp.participant_id = part.id
-		WHERE dp.draft_id = $1 
+		WHERE dp.draft_id = $1
 		ORDER BY dp.overall_pick_number
-	`, draft.ID)
+	`, draftID)
 	if err != nil {
-		log.Printf("Get picks for broadcast error: %v", err)
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var pick map[string]interface{}
-		var id, draftID, participantID, playerID, roundNumber, pickInRound, overallPickNumber int
+		var id, pickDraftID, participantID, pickPlayerID, roundNumber, pickInRound, overallPickNumber int
 		var playerRatingTier, participantName string
+		var isKeeper bool
+		var comment *string
 		var pickedAt interface{}
 		var firstName, lastName, commonName, positionShortLabel, teamLabel, nationalityLabel, avatarURL, teamImageURL, nationalityImageURL, shieldURL *string
 		var overallRating *int
 
-		err := rows.Scan(&id, &draftID, &participantID, &playerID, &roundNumber, &pickInRound,
+		err := rows.Scan(&id, &pickDraftID, &participantID, &pickPlayerID, &roundNumber, &pickInRound,
 			&overallPickNumber, &playerRatingTier, &pickedAt, &firstName, &lastName, &commonName,
-			&overallRating, &positionShortLabel, &teamLabel, &teamImageURL, &nationalityLabel, &nationalityImageURL, &avatarURL, &shieldURL, &participantName)
+			&overallRating, &positionShortLabel, &teamLabel, &teamImageURL, &nationalityLabel, &nationalityImageURL, &avatarURL, &shieldURL, &participantName, &isKeeper, &comment)
 		if err != nil {
 			continue
 		}
 
 		pick = map[string]interface{}{
 			"id":                id,
-			"draftId":           draftID,
+			"draftId":           pickDraftID,
 			"participantId":     participantID,
-			"playerId":          playerID,
+			"playerId":          pickPlayerID,
 			"roundNumber":       roundNumber,
 			"pickInRound":       pickInRound,
 			"overallPickNumber": overallPickNumber,
 			"playerRatingTier":  playerRatingTier,
 			"pickedAt":          pickedAt,
 			"participantName":   participantName,
+			"isKeeper":          isKeeper,
+			"comment":           comment,
 			"player": map[string]interface{}{
 				"firstName":           firstName,
 				"lastName":            lastName,
@@ -670,43 +1713,37 @@ func BroadcastDraftStateToRoom(db *sqlx.DB, draftCode string) {
 		picks = append(picks, pick)
 	}
 
-	// Calculate whose turn it is next
-	var currentPicker *int
-	if draft.Status == "active" {
-		picker := calculateCurrentPicker(draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
-		currentPicker = &picker
-	}
-
-	stateMsg := WSMessage{
-		Type: "draftState",
-		Data: map[string]interface{}{
-			"draft":         draft,
-			"participants":  participants,
-			"picks":         picks,
-			"currentPicker": currentPicker,
-		},
-	}
-
-	if data, err := json.Marshal(stateMsg); err == nil {
-		roomManager.BroadcastToRoom(draftCode, data)
-		log.Printf("Broadcasted draft state to room %s", draftCode)
-	} else {
-		log.Printf("Failed to marshal draft state: %v", err)
-	}
+	return picks, rows.Err()
 }
 
-// Helper function for calculating current picker
-func calculateCurrentPicker(round, pickInRound, participantCount int) int {
-	startingPlayer := ((round - 1) % participantCount) + 1
-	return ((startingPlayer + pickInRound - 2) % participantCount) + 1
+// Helper function for calculating current picker. Returns the draft order of
+// the participant on the clock.
+func calculateCurrentPicker(orderMode string, round, pickInRound, participantCount int) int {
+	switch orderMode {
+	case OrderModeSnake:
+		// Every other round runs in reverse
+		if round%2 == 0 {
+			return participantCount - pickInRound + 1
+		}
+		return pickInRound
+	case OrderModeThirdRoundReversal:
+		// Round 2 reverses, round 3 repeats it, then snake from there
+		if round == 2 || (round > 2 && round%2 == 1) {
+			return participantCount - pickInRound + 1
+		}
+		return pickInRound
+	default:
+		// Rotation: the first pick moves one seat along every round
+		startingPlayer := ((round - 1) % participantCount) + 1
+		return ((startingPlayer + pickInRound - 2) % participantCount) + 1
+	}
 }
 
 func (h *Handler) sendDraftState(client *DraftClient) {
 	// Get current draft state from database
 	var draft database.Draft
 	err := h.db.Get(&draft, `
-		SELECT id, code, name, admin_name, status, current_round, current_pick_in_round, 
-		       total_rounds, participant_count, created_at, started_at, completed_at
+		SELECT `+database.DraftColumns+`
 		FROM drafts WHERE code = $1
 	`, client.Room.DraftCode)
 	if err != nil {
@@ -717,8 +1754,7 @@ func (h *Handler) sendDraftState(client *DraftClient) {
 	// Get participants
 	var participants []database.DraftParticipant
 	err = h.db.Select(&participants, `
-		SELECT id, draft_id, name, draft_order, is_admin, joined_at, 
-		       picks_85_89, picks_80_84, picks_75_79, picks_up_to_74
+		SELECT `+database.ParticipantColumns+`
 		FROM draft_participants WHERE draft_id = $1 ORDER BY draft_order
 	`, draft.ID)
 	if err != nil {
@@ -727,174 +1763,40 @@ func (h *Handler) sendDraftState(client *DraftClient) {
 	}
 
 	// Get picks with player details
-	var picks []map[string]interface{}
-	rows, err := h.db.Query(`
-		SELECT dp.id, dp.draft_id, dp.participant_id, dp.player_id, dp.round_number, 
-		       dp.pick_in_round, dp.overall_pick_number, dp.player_rating_tier, dp.picked_at,
-		       p.first_name, p.last_name, p.common_name, p.overall_rating, p.position_short_label,
-		       p.team_label, p.team_image_url, p.nationality_label, p.nationality_image_url, 
-		       p.avatar_url, p.shield_url,
-		       part.name as participant_name
-		FROM draft_picks dp
-		JOIN players p ON dp.player_id = p.id
-		JOIN draft_participants part ON dp.participant_id = part.id
-		WHERE dp.draft_id = $1 
-		ORDER BY dp.overall_pick_number
-	`, draft.ID)
+	picks, err := loadDraftPicks(h.db, draft.ID)
 	if err != nil {
 		log.Printf("Get picks for state error: %v", err)
 		return
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var pick map[string]interface{}
-		var id, draftID, participantID, playerID, roundNumber, pickInRound, overallPickNumber int
-		var playerRatingTier, participantName string
-		var pickedAt interface{}
-		var firstName, lastName, commonName, positionShortLabel, teamLabel, nationalityLabel, avatarURL, teamImageURL, nationalityImageURL, shieldURL *string
-		var overallRating *int
-
-		err := rows.Scan(&id, &draftID, &participantID, &playerID, &roundNumber, &pickInRound,
-			&overallPickNumber, &playerRatingTier, &pickedAt, &firstName, &lastName, &commonName,
-			&overallRating, &positionShortLabel, &teamLabel, &teamImageURL, &nationalityLabel, &nationalityImageURL, &avatarURL, &shieldURL, &participantName)
-		if err != nil {
-			continue
-		}
-
-		pick = map[string]interface{}{
-			"id":                id,
-			"draftId":           draftID,
-			"participantId":     participantID,
-			"playerId":          playerID,
-			"roundNumber":       roundNumber,
-			"pickInRound":       pickInRound,
-			"overallPickNumber": overallPickNumber,
-			"playerRatingTier":  playerRatingTier,
-			"pickedAt":          pickedAt,
-			"participantName":   participantName,
-			"player": map[string]interface{}{
-				"firstName":           firstName,
-				"lastName":            lastName,
-				"commonName":          commonName,
-				"overallRating":       overallRating,
-				"positionShortLabel":  positionShortLabel,
-				"teamLabel":           teamLabel,
-				"teamImageUrl":        teamImageURL,
-				"nationalityLabel":    nationalityLabel,
-				"nationalityImageUrl": nationalityImageURL,
-				"avatarUrl":           avatarURL,
-				"shieldUrl":           shieldURL,
-			},
-		}
-		picks = append(picks, pick)
-	}
 
 	// Calculate whose turn it is next (ADD THIS PART)
 	var currentPicker *int
 	if draft.Status == "active" {
-		picker := calculateCurrentPicker(draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
+		picker := calculateCurrentPicker(draft.OrderMode, draft.CurrentRound, draft.CurrentPickInRound, draft.ParticipantCount)
 		currentPicker = &picker
 	}
 
+	// Show who is holding up the lobby
+	var notReady []string
+	if draft.Status == "waiting" {
+		notReady = notReadyParticipants(participants)
+	}
+
 	stateMsg := WSMessage{
 		Type: "draftState",
 		Data: map[string]interface{}{
-			"draft":         draft,
-			"participants":  participants,
-			"picks":         picks,
-			"currentPicker": currentPicker, // ADD THIS LINE
+			"draft":          draft,
+			"participants":   participants,
+			"picks":          picks,
+			"currentPicker":  currentPicker, // ADD THIS LINE
+			"spectatorCount": client.Room.SpectatorCount(),
+			"online":         client.Room.Online(),
+			"notReady":       notReady,
+			"remainingSeats": remainingSeats(draft),
 		},
 	}
 
 	if data, err := json.Marshal(stateMsg); err == nil {
-		select {
-		case client.Send <- data:
-		default:
-			log.Printf("Failed to send draft state to client")
-		}
-	}
-}
-
-// Helper function for calculating standings in WebSocket broadcasts
-func calculateStandingsForBroadcast(participants []database.DraftParticipant, matches []database.Match) []map[string]interface{} {
-	standings := make(map[string]*map[string]interface{})
-
-	// Initialize standings for all participants
-	for _, participant := range participants {
-		standings[participant.Name] = &map[string]interface{}{
-			"teamName":       participant.Name,
-			"teamId":         participant.ID,
-			"gamesPlayed":    0,
-			"wins":           0,
-			"draws":          0,
-			"losses":         0,
-			"points":         0,
-			"goalsFor":       0,
-			"goalsAgainst":   0,
-			"goalDifference": 0,
-		}
-	}
-
-	// Process matches
-	for _, match := range matches {
-		homeTeam := standings[match.HomeTeamName]
-		awayTeam := standings[match.AwayTeamName]
-
-		if homeTeam == nil || awayTeam == nil {
-			continue // Skip if team not found
-		}
-
-		// Update games played
-		(*homeTeam)["gamesPlayed"] = (*homeTeam)["gamesPlayed"].(int) + 1
-		(*awayTeam)["gamesPlayed"] = (*awayTeam)["gamesPlayed"].(int) + 1
-
-		// Update goals
-		(*homeTeam)["goalsFor"] = (*homeTeam)["goalsFor"].(int) + match.HomeScore
-		(*homeTeam)["goalsAgainst"] = (*homeTeam)["goalsAgainst"].(int) + match.AwayScore
-		(*awayTeam)["goalsFor"] = (*awayTeam)["goalsFor"].(int) + match.AwayScore
-		(*awayTeam)["goalsAgainst"] = (*awayTeam)["goalsAgainst"].(int) + match.HomeScore
-
-		// Update results and points
-		if match.HomeScore > match.AwayScore {
-			// Home team wins
-			(*homeTeam)["wins"] = (*homeTeam)["wins"].(int) + 1
-			(*homeTeam)["points"] = (*homeTeam)["points"].(int) + 3
-			(*awayTeam)["losses"] = (*awayTeam)["losses"].(int) + 1
-		} else if match.HomeScore < match.AwayScore {
-			// Away team wins
-			(*awayTeam)["wins"] = (*awayTeam)["wins"].(int) + 1
-			(*awayTeam)["points"] = (*awayTeam)["points"].(int) + 3
-			(*homeTeam)["losses"] = (*homeTeam)["losses"].(int) + 1
-		} else {
-			// Draw
-			(*homeTeam)["draws"] = (*homeTeam)["draws"].(int) + 1
-			(*homeTeam)["points"] = (*homeTeam)["points"].(int) + 1
-			(*awayTeam)["draws"] = (*awayTeam)["draws"].(int) + 1
-			(*awayTeam)["points"] = (*awayTeam)["points"].(int) + 1
-		}
-
-		// Update goal difference
-		(*homeTeam)["goalDifference"] = (*homeTeam)["goalsFor"].(int) - (*homeTeam)["goalsAgainst"].(int)
-		(*awayTeam)["goalDifference"] = (*awayTeam)["goalsFor"].(int) - (*awayTeam)["goalsAgainst"].(int)
-	}
-
-	// Convert to slice and sort by points (desc), then goal difference (desc), then goals for (desc)
-	result := make([]map[string]interface{}, 0, len(standings))
-	for _, standing := range standings {
-		result = append(result, *standing)
-	}
-
-	// Sort standings
-	for i := 0; i < len(result); i++ {
-		for j := i + 1; j < len(result); j++ {
-			if result[i]["points"].(int) < result[j]["points"].(int) ||
-				(result[i]["points"].(int) == result[j]["points"].(int) && result[i]["goalDifference"].(int) < result[j]["goalDifference"].(int)) ||
-				(result[i]["points"].(int) == result[j]["points"].(int) && result[i]["goalDifference"].(int) == result[j]["goalDifference"].(int) && result[i]["goalsFor"].(int) < result[j]["goalsFor"].(int)) {
-				result[i], result[j] = result[j], result[i]
-			}
-		}
+		client.Room.SendToClient(client, data)
 	}
-
-	return result
 }
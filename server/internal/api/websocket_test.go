@@ -0,0 +1,196 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"eafc-draft-server/internal/config"
+
+	"github.com/gorilla/websocket"
+)
+
+// These tests churn clients in and out of a room while it is broadcasting,
+// and are meant to be run with -race
+
+func quietLogs(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+// keepBroadcasting sends every kind of room message to draftCode until stop
+// is closed
+func keepBroadcasting(draftCode string, stop <-chan struct{}, wg *sync.WaitGroup) {
+	senders := []func(i int){
+		func(i int) { broadcastMessage(draftCode, "test", map[string]int{"n": i}) },
+		func(i int) { broadcastEphemeralMessage(draftCode, "tick", map[string]int{"n": i}) },
+		func(i int) { sendParticipantMessage(draftCode, fmt.Sprintf("p%d", i%5), "private", nil) },
+		func(i int) {
+			roomManager.Online(draftCode)
+			roomManager.SpectatorCount(draftCode)
+		},
+	}
+
+	for _, send := range senders {
+		wg.Add(1)
+		go func(send func(int)) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				send(i)
+			}
+		}(send)
+	}
+}
+
+// waitForEmptyRoom waits for every client to have left the room. It looks the
+// room up without getRoom, which would stand in a fresh empty one for a room
+// that has closed; a room that is gone closed once it had no clients.
+func waitForEmptyRoom(t *testing.T, draftCode string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		roomManager.mutex.RLock()
+		room, ok := roomManager.rooms[draftCode]
+		roomManager.mutex.RUnlock()
+		if !ok {
+			return
+		}
+
+		count := make(chan int, 1)
+		if room.do(func() { count <- len(room.clients) }) && <-count == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("clients still in room %s", draftCode)
+}
+
+func TestRoomChurnDuringBroadcast(t *testing.T) {
+	quietLogs(t)
+	const draftCode = "CHURN1"
+
+	stop := make(chan struct{})
+	var broadcasters sync.WaitGroup
+	keepBroadcasting(draftCode, stop, &broadcasters)
+
+	var clients sync.WaitGroup
+	for c := 0; c < 50; c++ {
+		clients.Add(1)
+		go func(c int) {
+			defer clients.Done()
+			for i := 0; i < 20; i++ {
+				client := &DraftClient{Send: make(chan []byte, 256)}
+				for {
+					client.Room = roomManager.getRoom(draftCode)
+					if client.Room.register(client) {
+						break
+					}
+				}
+
+				// Drain like writePump until the room closes Send
+				drained := make(chan struct{})
+				go func() {
+					defer close(drained)
+					for range client.Send {
+					}
+				}()
+
+				if c%2 == 0 {
+					client.Room.Identify(client, fmt.Sprintf("p%d", c%5), false, true)
+				} else {
+					client.Room.Identify(client, "", true, false)
+				}
+				client.Room.Replay(client, 0)
+				client.Room.unregister(client)
+				<-drained
+			}
+		}(c)
+	}
+
+	clients.Wait()
+	close(stop)
+	broadcasters.Wait()
+
+	waitForEmptyRoom(t, draftCode)
+}
+
+func TestWebSocketChurnDuringBroadcast(t *testing.T) {
+	quietLogs(t)
+	const draftCode = "CHURN2"
+
+	h := NewHandler(nil, &config.Config{AllowedOrigin: "http://localhost"})
+	server := httptest.NewServer(http.HandlerFunc(h.handleDraftWebSocket))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/drafts/" + draftCode
+
+	stop := make(chan struct{})
+	var broadcasters sync.WaitGroup
+	keepBroadcasting(draftCode, stop, &broadcasters)
+
+	var received atomic.Int64
+	var clients sync.WaitGroup
+	for c := 0; c < 20; c++ {
+		clients.Add(1)
+		go func(c int) {
+			defer clients.Done()
+			for i := 0; i < 10; i++ {
+				dialer := websocket.Dialer{EnableCompression: c%3 == 0}
+				if c%2 == 0 {
+					dialer.Subprotocols = []string{msgpackSubprotocol}
+				}
+				conn, _, err := dialer.Dial(url, nil)
+				if err != nil {
+					t.Errorf("dial: %v", err)
+					return
+				}
+
+				// Sequenced JSON messages must arrive in order
+				var lastSeq uint64
+				for n := 0; n < 5; n++ {
+					frameType, data, err := conn.ReadMessage()
+					if err != nil {
+						break
+					}
+					received.Add(1)
+					if frameType != websocket.TextMessage {
+						continue
+					}
+					var message struct {
+						Seq uint64 `json:"seq"`
+					}
+					if err := json.Unmarshal(data, &message); err == nil && message.Seq != 0 {
+						if message.Seq <= lastSeq {
+							t.Errorf("seq %d after %d", message.Seq, lastSeq)
+						}
+						lastSeq = message.Seq
+					}
+				}
+				conn.Close()
+			}
+		}(c)
+	}
+
+	clients.Wait()
+	close(stop)
+	broadcasters.Wait()
+
+	if received.Load() == 0 {
+		t.Fatal("no messages received")
+	}
+	waitForEmptyRoom(t, draftCode)
+}
@@ -2,8 +2,80 @@ package database
 
 import (
 	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
 )
 
+// DraftColumns is the column list matching the Draft struct, for SELECT and RETURNING clauses
+const DraftColumns = `id, code, name, admin_name, status, current_round, current_pick_in_round,
+	participant_count, created_at, started_at, completed_at, order_locked, is_mock, pick_deadline,
+	max_participants, (join_password_hash IS NOT NULL) AS is_private, blind_mode, pack_size, dataset,
+	league_id, ` + DraftSettingsColumns + `, ` + TournamentSettingsColumns
+
+// DraftSettingsColumns is the column list matching the DraftSettings struct
+const DraftSettingsColumns = `total_rounds, quota_85_89, quota_80_84, quota_up_to_79, pick_timer_seconds,
+	pool_leagues, pool_nationalities, order_mode, quota_gk, pool_gender,
+	ban_special_cards, min_gk`
+
+// TournamentSettingsColumns is the column list matching the TournamentSettings struct
+const TournamentSettingsColumns = `double_round_robin, tournament_format, group_count, group_qualifiers,
+	points_win, points_draw, points_loss, bonus_goals, bonus_close_loss, forfeit_goals`
+
+// ParticipantColumns is the column list matching the DraftParticipant struct
+const ParticipantColumns = `id, draft_id, name, draft_order, is_admin, joined_at,
+	picks_85_89, picks_80_84, picks_75_79, picks_up_to_74, picks_gk, is_bot, bot_strategy, is_ready,
+	tournament_group`
+
+// PickColumns is the column list matching the DraftPick struct
+const PickColumns = `id, draft_id, participant_id, player_id, round_number, pick_in_round,
+	overall_pick_number, player_rating_tier, picked_at, is_keeper, comment`
+
+// MatchColumns is the column list matching the Match struct
+const MatchColumns = `id, draft_id, home_team_id, away_team_id, home_team_name, away_team_name,
+	home_score, away_score, played_at, recorded_by, status, confirmed_by, dispute_reason,
+	home_score_aet, away_score_aet, home_penalties, away_penalties, walkover,
+	home_yellow_cards, away_yellow_cards, home_red_cards, away_red_cards`
+
+// DraftSettings are the configurable rules of a draft, shared by drafts and
+// draft templates
+type DraftSettings struct {
+	TotalRounds      int `db:"total_rounds" json:"totalRounds"`
+	Quota8589        int `db:"quota_85_89" json:"quota8589"`
+	Quota8084        int `db:"quota_80_84" json:"quota8084"`
+	QuotaUpTo79      int `db:"quota_up_to_79" json:"quotaUpTo79"`
+	PickTimerSeconds int `db:"pick_timer_seconds" json:"pickTimerSeconds"` // 0 means no pick timer
+	QuotaGK          int `db:"quota_gk" json:"quotaGk"`                    // most goalkeepers per squad, 0 means no limit
+	MinGK            int `db:"min_gk" json:"minGk"`                        // fewest goalkeepers per squad, 0 means none required
+
+	// Theme draft pool restrictions, empty means unrestricted
+	PoolLeagues       pq.StringArray `db:"pool_leagues" json:"poolLeagues"`
+	PoolNationalities pq.StringArray `db:"pool_nationalities" json:"poolNationalities"`
+	PoolGender        string         `db:"pool_gender" json:"poolGender"`            // men, women or mixed
+	BanSpecialCards   bool           `db:"ban_special_cards" json:"banSpecialCards"` // no Icons or Heroes
+
+	// OrderMode is how the pick order changes between rounds: rotation, snake or 3rr
+	OrderMode string `db:"order_mode" json:"orderMode"`
+}
+
+// TournamentSettings are the rules of the tournament played after a draft,
+// chosen when the admin starts it
+type TournamentSettings struct {
+	DoubleRoundRobin bool   `db:"double_round_robin" json:"doubleRoundRobin"` // every pairing meets home and away
+	TournamentFormat string `db:"tournament_format" json:"tournamentFormat"`  // league, knockout or groups
+	GroupCount       int    `db:"group_count" json:"groupCount"`              // groups format: number of groups
+	GroupQualifiers  int    `db:"group_qualifiers" json:"groupQualifiers"`    // groups format: teams per group reaching the knockouts
+
+	// Points rules, 3-1-0 unless the admin chose otherwise
+	PointsWin      int  `db:"points_win" json:"pointsWin"`
+	PointsDraw     int  `db:"points_draw" json:"pointsDraw"`
+	PointsLoss     int  `db:"points_loss" json:"pointsLoss"`
+	BonusGoals     int  `db:"bonus_goals" json:"bonusGoals"`          // a bonus point for scoring at least this many, 0 means off
+	BonusCloseLoss bool `db:"bonus_close_loss" json:"bonusCloseLoss"` // a bonus point for losing by one goal
+
+	ForfeitGoals int `db:"forfeit_goals" json:"forfeitGoals"` // a walkover is recorded as a win by this many goals to nil
+}
+
 // Draft represents a draft from the database
 type Draft struct {
 	ID                 int        `db:"id" json:"id"`
@@ -13,11 +85,32 @@ type Draft struct {
 	Status             string     `db:"status" json:"status"`
 	CurrentRound       int        `db:"current_round" json:"currentRound"`
 	CurrentPickInRound int        `db:"current_pick_in_round" json:"currentPickInRound"`
-	TotalRounds        int        `db:"total_rounds" json:"totalRounds"`
 	ParticipantCount   int        `db:"participant_count" json:"participantCount"`
 	CreatedAt          *time.Time `db:"created_at" json:"createdAt"`
 	StartedAt          *time.Time `db:"started_at" json:"startedAt"`
 	CompletedAt        *time.Time `db:"completed_at" json:"completedAt"`
+	OrderLocked        bool       `db:"order_locked" json:"orderLocked"` // draft_order was set by the admin and is used as-is at start
+	IsMock             bool       `db:"is_mock" json:"isMock"`           // solo practice draft against bots
+	PickDeadline       *time.Time `db:"pick_deadline" json:"pickDeadline"`
+	MaxParticipants    *int       `db:"max_participants" json:"maxParticipants"` // nil means no cap
+	IsPrivate          bool       `db:"is_private" json:"isPrivate"`             // joining requires a password
+	BlindMode          bool       `db:"blind_mode" json:"blindMode"`             // each round is submitted secretly and revealed at once
+	PackSize           *int       `db:"pack_size" json:"packSize"`               // pack mode: each turn picks from this many dealt players, nil when off
+	Dataset            string     `db:"dataset" json:"dataset"`                  // player dataset the draft picks from, e.g. FC25
+	LeagueID           *int       `db:"league_id" json:"leagueId"`               // the league this draft is a season of
+
+	DraftSettings
+	TournamentSettings
+}
+
+// DraftTemplate is a named, reusable set of draft settings
+type DraftTemplate struct {
+	ID        int        `db:"id" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	OwnerName string     `db:"owner_name" json:"ownerName"`
+	CreatedAt *time.Time `db:"created_at" json:"createdAt"`
+
+	DraftSettings
 }
 
 // DraftParticipant represents a participant in a draft
@@ -32,6 +125,12 @@ type DraftParticipant struct {
 	Picks8084   int        `db:"picks_80_84" json:"picks8084"`
 	Picks7579   int        `db:"picks_75_79" json:"picks7579"`
 	PicksUpTo74 int        `db:"picks_up_to_74" json:"picksUpTo74"`
+	PicksGK     int        `db:"picks_gk" json:"picksGk"`
+	IsBot       bool       `db:"is_bot" json:"isBot"`
+	BotStrategy *string    `db:"bot_strategy" json:"botStrategy"`
+	IsReady     bool       `db:"is_ready" json:"isReady"`
+
+	TournamentGroup *string `db:"tournament_group" json:"tournamentGroup"` // group stage group, e.g. A
 }
 
 // DraftPick represents a pick made in a draft
@@ -45,18 +144,144 @@ type DraftPick struct {
 	OverallPickNumber int        `db:"overall_pick_number" json:"overallPickNumber"`
 	PlayerRatingTier  string     `db:"player_rating_tier" json:"playerRatingTier"`
 	PickedAt          *time.Time `db:"picked_at" json:"pickedAt"`
+	IsKeeper          bool       `db:"is_keeper" json:"isKeeper"`
+	Comment           *string    `db:"comment" json:"comment"`
+}
+
+// DraftKeeper is a player assigned to a participant before the draft starts
+type DraftKeeper struct {
+	ID              int    `db:"id" json:"id"`
+	DraftID         int    `db:"draft_id" json:"draftId"`
+	ParticipantID   int    `db:"participant_id" json:"participantId"`
+	ParticipantName string `db:"participant_name" json:"participantName"`
+	PlayerID        int    `db:"player_id" json:"playerId"`
+	RoundNumber     int    `db:"round_number" json:"roundNumber"`
 }
 
 // Match represents a match played in the tournament phase
 type Match struct {
-	ID           int        `db:"id" json:"id"`
-	DraftID      int        `db:"draft_id" json:"draftId"`
-	HomeTeamID   int        `db:"home_team_id" json:"homeTeamId"`
-	AwayTeamID   int        `db:"away_team_id" json:"awayTeamId"`
-	HomeTeamName string     `db:"home_team_name" json:"homeTeamName"`
-	AwayTeamName string     `db:"away_team_name" json:"awayTeamName"`
-	HomeScore    int        `db:"home_score" json:"homeScore"`
-	AwayScore    int        `db:"away_score" json:"awayScore"`
-	PlayedAt     *time.Time `db:"played_at" json:"playedAt"`
-	RecordedBy   string     `db:"recorded_by" json:"recordedBy"`
+	ID            int        `db:"id" json:"id"`
+	DraftID       int        `db:"draft_id" json:"draftId"`
+	HomeTeamID    int        `db:"home_team_id" json:"homeTeamId"`
+	AwayTeamID    int        `db:"away_team_id" json:"awayTeamId"`
+	HomeTeamName  string     `db:"home_team_name" json:"homeTeamName"`
+	AwayTeamName  string     `db:"away_team_name" json:"awayTeamName"`
+	HomeScore     int        `db:"home_score" json:"homeScore"`
+	AwayScore     int        `db:"away_score" json:"awayScore"`
+	PlayedAt      *time.Time `db:"played_at" json:"playedAt"`
+	RecordedBy    string     `db:"recorded_by" json:"recordedBy"`
+	Status        string     `db:"status" json:"status"`                // confirmed, pending until the opponent confirms, disputed or rejected
+	ConfirmedBy   *string    `db:"confirmed_by" json:"confirmedBy"`     // nil until confirmed
+	DisputeReason *string    `db:"dispute_reason" json:"disputeReason"` // the opponent's objection to a disputed result
+	Walkover      bool       `db:"walkover" json:"walkover"`            // a forfeit, scored with the tournament's forfeit goals
+
+	// HomeScore and AwayScore are the score after 90 minutes, which is what
+	// the league table counts
+	ExtraTimeResult
+	MatchCards
+}
+
+// MatchCards are the bookings in a match, for the fair play table
+type MatchCards struct {
+	HomeYellowCards int `db:"home_yellow_cards" json:"homeYellowCards"`
+	AwayYellowCards int `db:"away_yellow_cards" json:"awayYellowCards"`
+	HomeRedCards    int `db:"home_red_cards" json:"homeRedCards"`
+	AwayRedCards    int `db:"away_red_cards" json:"awayRedCards"`
+}
+
+// ExtraTimeResult is how a match drawn in 90 minutes was decided. The scores
+// after extra time include the goals in 90 minutes. Each pair is nil when the
+// match didn't go that far.
+type ExtraTimeResult struct {
+	HomeScoreAET  *int `db:"home_score_aet" json:"homeScoreAet"`
+	AwayScoreAET  *int `db:"away_score_aet" json:"awayScoreAet"`
+	HomePenalties *int `db:"home_penalties" json:"homePenalties"`
+	AwayPenalties *int `db:"away_penalties" json:"awayPenalties"`
+}
+
+// MatchGoal is a goal scored in a match by one of the teams' drafted players
+type MatchGoal struct {
+	ID             int     `db:"id" json:"id"`
+	MatchID        int     `db:"match_id" json:"matchId"`
+	ParticipantID  int     `db:"participant_id" json:"participantId"`
+	TeamName       string  `db:"team_name" json:"teamName"`
+	PlayerID       int     `db:"player_id" json:"playerId"`
+	PlayerName     string  `db:"player_name" json:"playerName"`
+	Minute         int     `db:"minute" json:"minute"`
+	AssistPlayerID *int    `db:"assist_player_id" json:"assistPlayerId"`
+	AssistName     *string `db:"assist_name" json:"assistName"`
+}
+
+// League groups the drafts of one friend group, each draft a season
+type League struct {
+	ID        int       `db:"id" json:"id"`
+	Code      string    `db:"code" json:"code"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// Rating is a participant's Elo rating across every draft on the server, by
+// name
+type Rating struct {
+	Name        string    `db:"name" json:"name"`
+	Rating      float64   `db:"rating" json:"rating"`
+	GamesPlayed int       `db:"games_played" json:"gamesPlayed"`
+	Wins        int       `db:"wins" json:"wins"`
+	Draws       int       `db:"draws" json:"draws"`
+	Losses      int       `db:"losses" json:"losses"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Fixture is a scheduled tournament match, played once a match is linked
+type Fixture struct {
+	ID           int     `db:"id" json:"id"`
+	DraftID      int     `db:"draft_id" json:"draftId"`
+	RoundNumber  int     `db:"round_number" json:"roundNumber"`
+	HomeTeamID   int     `db:"home_team_id" json:"homeTeamId"`
+	AwayTeamID   int     `db:"away_team_id" json:"awayTeamId"`
+	HomeTeamName string  `db:"home_team_name" json:"homeTeamName"`
+	AwayTeamName string  `db:"away_team_name" json:"awayTeamName"`
+	MatchID      *int    `db:"match_id" json:"matchId"`     // nil until played
+	GroupName    *string `db:"group_name" json:"groupName"` // group stage fixtures only
+}
+
+// KnockoutTie is one tie of a knockout bracket. Round 1 is the first round,
+// slot is the tie's position within its round. Teams are nil until the
+// previous round's winners advance, and a first round tie without an away
+// team is a bye.
+type KnockoutTie struct {
+	ID           int     `db:"id" json:"id"`
+	DraftID      int     `db:"draft_id" json:"draftId"`
+	RoundNumber  int     `db:"round_number" json:"roundNumber"`
+	Slot         int     `db:"slot" json:"slot"`
+	HomeTeamID   *int    `db:"home_team_id" json:"homeTeamId"`
+	AwayTeamID   *int    `db:"away_team_id" json:"awayTeamId"`
+	HomeTeamName *string `db:"home_team_name" json:"homeTeamName"`
+	AwayTeamName *string `db:"away_team_name" json:"awayTeamName"`
+	HomeSeed     *int    `db:"home_seed" json:"homeSeed"`
+	AwaySeed     *int    `db:"away_seed" json:"awaySeed"`
+	MatchID      *int    `db:"match_id" json:"matchId"`
+	WinnerID     *int    `db:"winner_id" json:"winnerId"`
+	WinnerName   *string `db:"winner_name" json:"winnerName"`
+}
+
+// DraftEvent is one entry in a draft's timeline, used to replay the draft
+type DraftEvent struct {
+	ID        int            `db:"id" json:"id"`
+	DraftID   int            `db:"draft_id" json:"draftId"`
+	EventType string         `db:"event_type" json:"eventType"`
+	Actor     *string        `db:"actor" json:"actor"` // participant who caused the event, nil for the server
+	Payload   types.JSONText `db:"payload" json:"payload"`
+	CreatedAt *time.Time     `db:"created_at" json:"createdAt"`
+}
+
+// DraftPack is the set of players dealt to a participant for one turn of a
+// pack mode draft
+type DraftPack struct {
+	DraftID           int           `db:"draft_id" json:"draftId"`
+	OverallPickNumber int           `db:"overall_pick_number" json:"overallPickNumber"`
+	ParticipantID     int           `db:"participant_id" json:"participantId"`
+	PlayerIDs         pq.Int64Array `db:"player_ids" json:"playerIds"`
+	DealtAt           *time.Time    `db:"dealt_at" json:"dealtAt"`
+	ExpiresAt         *time.Time    `db:"expires_at" json:"expiresAt"` // the turn's pick deadline, nil without a timer
 }
@@ -2,11 +2,14 @@ package database
 
 import (
 	"reflect"
+	"strings"
+	"time"
 )
 
 // Player represents a player from the database
 type Player struct {
 	ID                    int     `db:"id" json:"id"`
+	Dataset               string  `db:"dataset" json:"dataset"` // game release the ratings are from, part of the key
 	OverallRating         *int    `db:"overall_rating" json:"overallRating"`
 	FirstName             *string `db:"first_name" json:"firstName"`
 	LastName              *string `db:"last_name" json:"lastName"`
@@ -25,6 +28,14 @@ type Player struct {
 	TeamLabel             *string `db:"team_label" json:"teamLabel"`
 	TeamImageURL          *string `db:"team_image_url" json:"teamImageUrl"`
 	PositionShortLabel    *string `db:"position_short_label" json:"positionShortLabel"`
+	Gender                string  `db:"gender" json:"gender"`      // men or women, which football the card is from
+	CardType              string  `db:"card_type" json:"cardType"` // standard, icon or hero
+
+	// Physical
+	Birthdate *time.Time `db:"birthdate" json:"birthdate"`
+	Height    *int       `db:"height" json:"height"` // cm
+	Weight    *int       `db:"weight" json:"weight"` // kg
+	Age       *int       `db:"age" json:"age"`       // derived from birthdate in player listings
 
 	// Stats
 	StatAcceleration       *int `db:"stat_acceleration" json:"statAcceleration"`
@@ -68,9 +79,21 @@ type Player struct {
 	StatVision             *int `db:"stat_vision" json:"statVision"`
 	StatVolleys            *int `db:"stat_volleys" json:"statVolleys"`
 
+	// Market price from player_prices, nil when not loaded or unknown
+	Price          *int       `db:"price" json:"price"`
+	PriceUpdatedAt *time.Time `db:"price_updated_at" json:"priceUpdatedAt"`
+
 	// Search vector for full-text search
 	SearchVector *string  `db:"search_vector" json:"-"`
-	Rank         *float64 `db:"rank" json:"-"`
+	Rank         *float64 `db:"rank" json:"rank,omitempty"` // search relevance, only set by searchPlayers
+}
+
+// PlayerAlias is a nickname that finds a player in search, such as CR7
+type PlayerAlias struct {
+	ID        int        `db:"id" json:"id"`
+	PlayerID  int        `db:"player_id" json:"playerId"`
+	Alias     string     `db:"alias" json:"alias"`
+	CreatedAt *time.Time `db:"created_at" json:"createdAt"`
 }
 
 // GetNumberColumns returns a map of column names that are integer types
@@ -93,3 +116,83 @@ func GetNumberColumns() map[string]bool {
 
 	return numberColumns
 }
+
+// ColumnValue returns the value of the field mapped to a column, or nil if
+// the column is unknown or the value is NULL
+func (p Player) ColumnValue(column string) interface{} {
+	v := reflect.ValueOf(p)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("db") != column {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return nil
+			}
+			return field.Elem().Interface()
+		}
+		return field.Interface()
+	}
+	return nil
+}
+
+// derivedPlayerColumns are Player fields that aren't stored on players: the
+// search vector is generated, the rank only exists in search results, prices
+// live in player_prices and the age follows from the birthdate
+var derivedPlayerColumns = map[string]bool{
+	"search_vector":    true,
+	"rank":             true,
+	"price":            true,
+	"price_updated_at": true,
+	"age":              true,
+}
+
+// GetPlayerColumns returns the stored player columns, in struct order
+func GetPlayerColumns() []string {
+	var columns []string
+
+	t := reflect.TypeOf(Player{})
+	for i := 0; i < t.NumField(); i++ {
+		dbTag := t.Field(i).Tag.Get("db")
+
+		if dbTag != "" && !derivedPlayerColumns[dbTag] {
+			columns = append(columns, dbTag)
+		}
+	}
+
+	return columns
+}
+
+// IsPlayerField reports whether a column is part of a player's JSON
+func IsPlayerField(column string) bool {
+	field, ok := playerFieldByColumn(column)
+	return ok && field.Tag.Get("json") != "-"
+}
+
+// SelectFields returns the player as a JSON object holding only the given
+// columns, keyed by their JSON names
+func (p Player) SelectFields(columns []string) map[string]interface{} {
+	v := reflect.ValueOf(p)
+	result := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		field, ok := playerFieldByColumn(column)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		result[name] = v.FieldByIndex(field.Index).Interface()
+	}
+	return result
+}
+
+func playerFieldByColumn(column string) (reflect.StructField, bool) {
+	t := reflect.TypeOf(Player{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("db") == column {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultDataset is the dataset players and drafts from before dataset
+// versions are assigned to
+const DefaultDataset = "FC25"
+
+// migrations are applied in order on every startup. The base tables are
+// created outside the server, so each statement must be idempotent.
+var migrations = []string{
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS order_locked BOOLEAN NOT NULL DEFAULT false`,
+	`CREATE TABLE IF NOT EXISTS draft_keepers (
+		id SERIAL PRIMARY KEY,
+		draft_id INTEGER NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+		participant_id INTEGER NOT NULL REFERENCES draft_participants(id) ON DELETE CASCADE,
+		player_id INTEGER NOT NULL REFERENCES players(id),
+		round_number INTEGER NOT NULL,
+		UNIQUE (draft_id, player_id),
+		UNIQUE (participant_id, round_number)
+	)`,
+	`ALTER TABLE draft_picks ADD COLUMN IF NOT EXISTS is_keeper BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS pool_leagues TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS pool_nationalities TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE draft_participants ADD COLUMN IF NOT EXISTS is_bot BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE draft_participants ADD COLUMN IF NOT EXISTS bot_strategy TEXT`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS is_mock BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS quota_85_89 INTEGER NOT NULL DEFAULT 1`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS quota_80_84 INTEGER NOT NULL DEFAULT 4`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS quota_up_to_79 INTEGER NOT NULL DEFAULT 6`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS pick_timer_seconds INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS pick_deadline TIMESTAMPTZ`,
+	`CREATE TABLE IF NOT EXISTS draft_templates (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		owner_name TEXT NOT NULL,
+		total_rounds INTEGER NOT NULL,
+		quota_85_89 INTEGER NOT NULL,
+		quota_80_84 INTEGER NOT NULL,
+		quota_up_to_79 INTEGER NOT NULL,
+		pick_timer_seconds INTEGER NOT NULL DEFAULT 0,
+		pool_leagues TEXT[] NOT NULL DEFAULT '{}',
+		pool_nationalities TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	// Participants created by the server get a crypto/rand token; the default
+	// only covers bots. Rows that predate tokens are left without one, for the
+	// participant to claim on their next join. Databases that added the
+	// column as NOT NULL backfilled those rows with the default instead; an
+	// operator resets them through /api/admin/drafts/{code}/participants/{name}/token.
+	`ALTER TABLE draft_participants ADD COLUMN IF NOT EXISTS token TEXT`,
+	`ALTER TABLE draft_participants ALTER COLUMN token DROP NOT NULL`,
+	`ALTER TABLE draft_participants ALTER COLUMN token SET DEFAULT md5(random()::text || clock_timestamp()::text)`,
+	`ALTER TABLE draft_participants ADD COLUMN IF NOT EXISTS is_ready BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS max_participants INTEGER`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS join_password_hash TEXT`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS blind_mode BOOLEAN NOT NULL DEFAULT false`,
+	`CREATE TABLE IF NOT EXISTS draft_blind_submissions (
+		draft_id INTEGER NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+		round_number INTEGER NOT NULL,
+		participant_id INTEGER NOT NULL REFERENCES draft_participants(id) ON DELETE CASCADE,
+		player_id INTEGER NOT NULL REFERENCES players(id),
+		submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (draft_id, round_number, participant_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS draft_events (
+		id SERIAL PRIMARY KEY,
+		draft_id INTEGER NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+		event_type TEXT NOT NULL,
+		actor TEXT,
+		payload JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS draft_events_draft_id_idx ON draft_events (draft_id, id)`,
+	`ALTER TABLE draft_picks ADD COLUMN IF NOT EXISTS comment TEXT`,
+	`ALTER TABLE draft_blind_submissions ADD COLUMN IF NOT EXISTS comment TEXT`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS order_mode TEXT NOT NULL DEFAULT 'rotation'`,
+	`ALTER TABLE draft_templates ADD COLUMN IF NOT EXISTS order_mode TEXT NOT NULL DEFAULT 'rotation'`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS pack_size INTEGER`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS quota_gk INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE draft_templates ADD COLUMN IF NOT EXISTS quota_gk INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE draft_participants ADD COLUMN IF NOT EXISTS picks_gk INTEGER NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS draft_packs (
+		draft_id INTEGER NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+		overall_pick_number INTEGER NOT NULL,
+		participant_id INTEGER NOT NULL REFERENCES draft_participants(id) ON DELETE CASCADE,
+		player_ids INTEGER[] NOT NULL,
+		dealt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		expires_at TIMESTAMPTZ,
+		PRIMARY KEY (draft_id, overall_pick_number)
+	)`,
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE players ADD COLUMN IF NOT EXISTS dataset TEXT NOT NULL DEFAULT '` + DefaultDataset + `'`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS dataset TEXT NOT NULL DEFAULT '` + DefaultDataset + `'`,
+	// Player ids repeat across game releases, so players are keyed by id and
+	// dataset. Picks only store the id and join through their draft's dataset,
+	// which means the foreign keys on players(id) have to go.
+	`DO $$
+	DECLARE
+		fk RECORD;
+	BEGIN
+		FOR fk IN
+			SELECT conrelid::regclass AS table_name, conname
+			FROM pg_constraint
+			WHERE contype = 'f' AND confrelid = 'players'::regclass
+		LOOP
+			EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', fk.table_name, fk.conname);
+		END LOOP;
+	END $$`,
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (
+			SELECT 1 FROM pg_constraint c
+			JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = ANY(c.conkey)
+			WHERE c.conrelid = 'players'::regclass AND c.contype = 'p' AND a.attname = 'dataset'
+		) THEN
+			ALTER TABLE players DROP CONSTRAINT IF EXISTS players_pkey;
+			ALTER TABLE players ADD PRIMARY KEY (id, dataset);
+		END IF;
+	END $$`,
+	`CREATE TABLE IF NOT EXISTS player_prices (
+		player_id INTEGER NOT NULL,
+		dataset TEXT NOT NULL,
+		price INTEGER NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (player_id, dataset)
+	)`,
+	`CREATE TABLE IF NOT EXISTS draft_watchlist (
+		draft_id INTEGER NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+		participant_id INTEGER NOT NULL REFERENCES draft_participants(id) ON DELETE CASCADE,
+		player_id INTEGER NOT NULL,
+		added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (participant_id, player_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS draft_watchlist_player_idx ON draft_watchlist (draft_id, player_id)`,
+	`ALTER TABLE players ADD COLUMN IF NOT EXISTS birthdate DATE`,
+	`ALTER TABLE players ADD COLUMN IF NOT EXISTS height INTEGER`,
+	`ALTER TABLE players ADD COLUMN IF NOT EXISTS weight INTEGER`,
+	`ALTER TABLE players ADD COLUMN IF NOT EXISTS gender TEXT NOT NULL DEFAULT 'men'`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS pool_gender TEXT NOT NULL DEFAULT 'men'`,
+	`ALTER TABLE draft_templates ADD COLUMN IF NOT EXISTS pool_gender TEXT NOT NULL DEFAULT 'men'`,
+	`ALTER TABLE players ADD COLUMN IF NOT EXISTS card_type TEXT NOT NULL DEFAULT 'standard'`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS ban_special_cards BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE draft_templates ADD COLUMN IF NOT EXISTS ban_special_cards BOOLEAN NOT NULL DEFAULT false`,
+	// Aliases belong to a player id in every dataset, like prices they have
+	// no foreign key on players
+	`CREATE TABLE IF NOT EXISTS player_aliases (
+		id SERIAL PRIMARY KEY,
+		player_id INTEGER NOT NULL,
+		alias TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS player_aliases_unique_idx ON player_aliases (player_id, lower(alias))`,
+	`CREATE TABLE IF NOT EXISTS fixtures (
+		id SERIAL PRIMARY KEY,
+		draft_id INTEGER NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+		round_number INTEGER NOT NULL,
+		home_team_id INTEGER NOT NULL REFERENCES draft_participants(id) ON DELETE CASCADE,
+		away_team_id INTEGER NOT NULL REFERENCES draft_participants(id) ON DELETE CASCADE,
+		match_id INTEGER REFERENCES matches(id) ON DELETE SET NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS fixtures_draft_idx ON fixtures (draft_id, round_number)`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS double_round_robin BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS tournament_format TEXT NOT NULL DEFAULT 'league'`,
+	`CREATE TABLE IF NOT EXISTS knockout_ties (
+		id SERIAL PRIMARY KEY,
+		draft_id INTEGER NOT NULL REFERENCES drafts(id) ON DELETE CASCADE,
+		round_number INTEGER NOT NULL,
+		slot INTEGER NOT NULL,
+		home_team_id INTEGER REFERENCES draft_participants(id) ON DELETE SET NULL,
+		away_team_id INTEGER REFERENCES draft_participants(id) ON DELETE SET NULL,
+		home_seed INTEGER,
+		away_seed INTEGER,
+		match_id INTEGER REFERENCES matches(id) ON DELETE SET NULL,
+		winner_id INTEGER REFERENCES draft_participants(id) ON DELETE SET NULL,
+		UNIQUE (draft_id, round_number, slot)
+	)`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS group_count INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS group_qualifiers INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE draft_participants ADD COLUMN IF NOT EXISTS tournament_group TEXT`,
+	`ALTER TABLE fixtures ADD COLUMN IF NOT EXISTS group_name TEXT`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS points_win INTEGER NOT NULL DEFAULT 3`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS points_draw INTEGER NOT NULL DEFAULT 1`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS points_loss INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS bonus_goals INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS bonus_close_loss BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'confirmed'`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS confirmed_by TEXT`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS dispute_reason TEXT`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS home_score_aet INTEGER`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS away_score_aet INTEGER`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS home_penalties INTEGER`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS away_penalties INTEGER`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS walkover BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS forfeit_goals INTEGER NOT NULL DEFAULT 3`,
+	`CREATE TABLE IF NOT EXISTS match_goals (
+		id SERIAL PRIMARY KEY,
+		match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+		participant_id INTEGER NOT NULL REFERENCES draft_participants(id) ON DELETE CASCADE,
+		player_id INTEGER NOT NULL,
+		minute INTEGER NOT NULL,
+		assist_player_id INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS match_goals_match_idx ON match_goals (match_id)`,
+	`CREATE TABLE IF NOT EXISTS ratings (
+		name_key TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		rating DOUBLE PRECISION NOT NULL,
+		games_played INTEGER NOT NULL DEFAULT 0,
+		wins INTEGER NOT NULL DEFAULT 0,
+		draws INTEGER NOT NULL DEFAULT 0,
+		losses INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE TABLE IF NOT EXISTS rating_changes (
+		match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+		name_key TEXT NOT NULL REFERENCES ratings(name_key) ON DELETE CASCADE,
+		delta DOUBLE PRECISION NOT NULL,
+		result TEXT NOT NULL,
+		PRIMARY KEY (match_id, name_key)
+	)`,
+	`CREATE TABLE IF NOT EXISTS leagues (
+		id SERIAL PRIMARY KEY,
+		code TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS league_id INTEGER REFERENCES leagues(id) ON DELETE SET NULL`,
+	`CREATE INDEX IF NOT EXISTS drafts_league_idx ON drafts (league_id)`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS home_yellow_cards INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS away_yellow_cards INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS home_red_cards INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE matches ADD COLUMN IF NOT EXISTS away_red_cards INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS min_gk INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE draft_templates ADD COLUMN IF NOT EXISTS min_gk INTEGER NOT NULL DEFAULT 0`,
+	// Secret handed to a template's creator for deleting it. Older templates
+	// have none and can only be deleted by the operator.
+	`ALTER TABLE draft_templates ADD COLUMN IF NOT EXISTS owner_token TEXT`,
+	// Packs were first created with zone-less timestamps, which made expiry
+	// depend on the session time zone. Converting a column that is already
+	// TIMESTAMPTZ is a no-op.
+	`ALTER TABLE draft_packs ALTER COLUMN dealt_at TYPE TIMESTAMPTZ`,
+	`ALTER TABLE draft_packs ALTER COLUMN expires_at TYPE TIMESTAMPTZ`,
+}
+
+// Migrate brings the schema up to date with what the server expects
+func Migrate(db *sqlx.DB) error {
+	for i, migration := range migrations {
+		if _, err := db.Exec(migration); err != nil {
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
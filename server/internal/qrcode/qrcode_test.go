@@ -0,0 +1,485 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The expected values below come from ISO/IEC 18004 rather than from the
+// encoder, and the round trip tests read symbols back with a decoder written
+// from the standard, so a mistake has to be made twice to go unnoticed
+
+// Level M data codewords and byte mode capacity per version (Tables 7 and 9)
+var (
+	specDataCodewords = [maxVersion + 1]int{0, 16, 28, 44, 64, 86, 108, 124, 154, 182, 216}
+	specByteCapacity  = [maxVersion + 1]int{0, 14, 26, 42, 62, 84, 106, 122, 152, 180, 213}
+)
+
+// Level M block structure for the versions decoded below (Table 9)
+var specBlocks = map[int][]struct{ count, total, data int }{
+	1:  {{1, 26, 16}},
+	7:  {{4, 49, 31}},
+	10: {{4, 69, 43}, {1, 70, 44}},
+}
+
+// Alignment pattern centres (Annex E)
+var specAlignment = map[int][]int{
+	1:  nil,
+	7:  {6, 22, 38},
+	10: {6, 28, 50},
+}
+
+// Format information for level M by mask (Annex C)
+var specFormatM = [8]string{
+	"101010000010010",
+	"101000100100101",
+	"101111001111100",
+	"101101101001011",
+	"100010111111001",
+	"100000011001110",
+	"100111110010111",
+	"100101010100000",
+}
+
+// Version information (Annex D)
+var specVersion = map[int]string{
+	7:  "000111110010010100",
+	10: "001010010011010011",
+}
+
+func TestReedSolomonReferenceVectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		data, ecc string
+	}{
+		// The 1-M worked example in Annex I
+		{"01234567", "10 20 0C 56 61 80 EC 11 EC 11 EC 11 EC 11 EC 11", "A5 24 D4 C1 ED 36 C7 87 2C 55"},
+		{"HELLO WORLD", "20 5B 0B 78 D1 72 DC 4D 43 40 EC 11 EC 11 EC 11", "C4 23 27 77 EB D7 E7 E2 5D 17"},
+	}
+
+	for _, tt := range tests {
+		got := reedSolomonRemainder(hexBytes(t, tt.data), reedSolomonDivisor(10))
+		if want := hexBytes(t, tt.ecc); !bytes.Equal(got, want) {
+			t.Errorf("%s: ecc % X, want % X", tt.name, got, want)
+		}
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	for v := 1; v <= maxVersion; v++ {
+		if got := numDataCodewords(v); got != specDataCodewords[v] {
+			t.Errorf("version %d: %d data codewords, want %d", v, got, specDataCodewords[v])
+		}
+
+		code, err := Encode(strings.Repeat("x", specByteCapacity[v]))
+		if err != nil {
+			t.Fatalf("version %d: %v", v, err)
+		}
+		if code.Size != v*4+17 {
+			t.Errorf("%d bytes: size %d, want version %d", specByteCapacity[v], code.Size, v)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("x", specByteCapacity[maxVersion]+1)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("over capacity: %v, want ErrTooLong", err)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	c := &Code{Size: 21, modules: newGrid(21), isFunc: newGrid(21)}
+	for mask, want := range specFormatM {
+		c.drawFormatBits(mask)
+		first, second := readFormat(c)
+		if first != want || second != want {
+			t.Errorf("mask %d: format %s and %s, want %s", mask, first, second, want)
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	for version, want := range specVersion {
+		size := version*4 + 17
+		c := &Code{Size: size, modules: newGrid(size), isFunc: newGrid(size)}
+		c.drawVersion(version)
+
+		// Bit 17 first: the top right block is read across its rows, the
+		// bottom left block down its columns
+		var topRight, bottomLeft strings.Builder
+		for i := 17; i >= 0; i-- {
+			topRight.WriteString(bit(c.modules[i/3][size-11+i%3]))
+			bottomLeft.WriteString(bit(c.modules[size-11+i%3][i/3]))
+		}
+		if topRight.String() != want || bottomLeft.String() != want {
+			t.Errorf("version %d: %s and %s, want %s", version, topRight.String(), bottomLeft.String(), want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		version int
+		text    string
+	}{
+		{1, "EAFC"},
+		{7, "https://draft.example/join/" + strings.Repeat("k7Q2", 23)},
+		{10, "https://draft.example/join/" + strings.Repeat("Zp-9_", 37) + "?"},
+	}
+
+	for _, tt := range tests {
+		text := tt.text
+		t.Run(fmt.Sprintf("version %d", tt.version), func(t *testing.T) {
+			code, err := Encode(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code.Size != tt.version*4+17 {
+				t.Fatalf("size %d, want version %d", code.Size, tt.version)
+			}
+
+			// Encode picks one mask, so swap in each of the others too
+			first, _ := readFormat(code)
+			chosen := slices.Index(specFormatM[:], first)
+			if chosen < 0 {
+				t.Fatalf("format %s is not level M", first)
+			}
+			code.applyMask(chosen)
+			for mask := range specFormatM {
+				code.applyMask(mask)
+				code.drawFormatBits(mask)
+				got, err := decode(code, tt.version)
+				if err != nil {
+					t.Fatalf("mask %d: %v", mask, err)
+				}
+				if got != text {
+					t.Errorf("mask %d: decoded %q, want %q", mask, got, text)
+				}
+				code.applyMask(mask)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	data, err := PNG("EAFC", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dim := (21 + 2*quietZone) * 3; img.Bounds().Dx() != dim || img.Bounds().Dy() != dim {
+		t.Errorf("image %v, want %dx%d", img.Bounds(), dim, dim)
+	}
+}
+
+func hexBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var out []byte
+	for _, field := range strings.Fields(s) {
+		var b byte
+		if _, err := fmt.Sscanf(field, "%02X", &b); err != nil {
+			t.Fatalf("bad hex %q", field)
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func bit(dark bool) string {
+	if dark {
+		return "1"
+	}
+	return "0"
+}
+
+// readFormat reads both copies of the format information, most significant
+// bit first
+func readFormat(c *Code) (string, string) {
+	at := func(x, y int) string { return bit(c.modules[y][x]) }
+
+	// Across row 8 left to right, then up column 8, skipping the timing
+	// pattern
+	var first strings.Builder
+	for x := 0; x <= 8; x++ {
+		if x != 6 {
+			first.WriteString(at(x, 8))
+		}
+	}
+	for y := 7; y >= 0; y-- {
+		if y != 6 && y != 8 {
+			first.WriteString(at(8, y))
+		}
+	}
+
+	// Up column 8 under the bottom left finder, then across row 8 beside the
+	// top right one
+	var second strings.Builder
+	for y := c.Size - 1; y >= c.Size-7; y-- {
+		second.WriteString(at(8, y))
+	}
+	for x := c.Size - 8; x < c.Size; x++ {
+		second.WriteString(at(x, 8))
+	}
+	return first.String(), second.String()
+}
+
+// functionModules marks the finders, separators, timing and alignment
+// patterns, and the format and version areas
+func functionModules(version, size int) [][]bool {
+	reserved := newGrid(size)
+	mark := func(x0, y0, w, h int) {
+		for y := y0; y < y0+h; y++ {
+			for x := x0; x < x0+w; x++ {
+				reserved[y][x] = true
+			}
+		}
+	}
+
+	mark(0, 0, 9, 9) // top left finder, separator and format
+	mark(size-8, 0, 8, 9)
+	mark(0, size-8, 9, 8)
+	mark(6, 0, 1, size)
+	mark(0, 6, size, 1)
+
+	centres := specAlignment[version]
+	last := len(centres) - 1
+	for i, y := range centres {
+		for j, x := range centres {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			mark(x-2, y-2, 5, 5)
+		}
+	}
+
+	if version >= 7 {
+		mark(size-11, 0, 3, 6)
+		mark(0, size-11, 6, 3)
+	}
+	return reserved
+}
+
+// checkPatterns compares the fixed patterns against what the standard draws
+func checkPatterns(c *Code, version int) error {
+	finder := func(x0, y0 int) error {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				if c.modules[y0+dy][x0+dx] != (ring != 2) {
+					return fmt.Errorf("finder at (%d,%d) wrong at (%d,%d)", x0, y0, x0+dx, y0+dy)
+				}
+			}
+		}
+		return nil
+	}
+	for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		if err := finder(corner[0], corner[1]); err != nil {
+			return err
+		}
+	}
+
+	for i := 8; i < c.Size-8; i++ {
+		if c.modules[6][i] != (i%2 == 0) || c.modules[i][6] != (i%2 == 0) {
+			return fmt.Errorf("timing pattern wrong at %d", i)
+		}
+	}
+
+	centres := specAlignment[version]
+	last := len(centres) - 1
+	for i, y := range centres {
+		for j, x := range centres {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					if c.modules[y+dy][x+dx] != (max(abs(dx), abs(dy)) != 1) {
+						return fmt.Errorf("alignment pattern at (%d,%d) wrong", x, y)
+					}
+				}
+			}
+		}
+	}
+
+	if !c.modules[c.Size-8][8] {
+		return errors.New("dark module missing")
+	}
+	return nil
+}
+
+// decode reads a level M byte mode symbol back to its text, checking every
+// block's Reed-Solomon syndromes on the way
+func decode(c *Code, version int) (string, error) {
+	if err := checkPatterns(c, version); err != nil {
+		return "", err
+	}
+
+	first, second := readFormat(c)
+	if first != second {
+		return "", fmt.Errorf("format copies differ: %s and %s", first, second)
+	}
+	mask := slices.Index(specFormatM[:], first)
+	if mask < 0 {
+		return "", fmt.Errorf("format %s is not level M", first)
+	}
+
+	masked := func(x, y int) bool {
+		i, j := y, x
+		switch mask {
+		case 0:
+			return (i+j)%2 == 0
+		case 1:
+			return i%2 == 0
+		case 2:
+			return j%3 == 0
+		case 3:
+			return (i+j)%3 == 0
+		case 4:
+			return (i/2+j/3)%2 == 0
+		case 5:
+			return (i*j)%2+(i*j)%3 == 0
+		case 6:
+			return ((i*j)%2+(i*j)%3)%2 == 0
+		default:
+			return ((i+j)%2+(i*j)%3)%2 == 0
+		}
+	}
+
+	// Read the codewords from the bottom right, two columns at a time,
+	// alternating up and down and stepping over the vertical timing pattern
+	reserved := functionModules(version, c.Size)
+	var stream []byte
+	var current byte
+	n := 0
+	upward := true
+	for right := c.Size - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for k := 0; k < c.Size; k++ {
+			y := k
+			if upward {
+				y = c.Size - 1 - k
+			}
+			for _, x := range []int{right, right - 1} {
+				if reserved[y][x] {
+					continue
+				}
+				current <<= 1
+				if c.modules[y][x] != masked(x, y) {
+					current |= 1
+				}
+				if n++; n%8 == 0 {
+					stream = append(stream, current)
+					current = 0
+				}
+			}
+		}
+		upward = !upward
+	}
+
+	// De-interleave: data codewords by block, then error correction codewords
+	var blocks [][]byte
+	var dataLens []int
+	for _, group := range specBlocks[version] {
+		for i := 0; i < group.count; i++ {
+			blocks = append(blocks, nil)
+			dataLens = append(dataLens, group.data)
+		}
+	}
+	eccLen := specBlocks[version][0].total - specBlocks[version][0].data
+	pos := 0
+	for i := 0; i < dataLens[len(dataLens)-1]; i++ {
+		for b := range blocks {
+			if i < dataLens[b] {
+				blocks[b] = append(blocks[b], stream[pos])
+				pos++
+			}
+		}
+	}
+	for i := 0; i < eccLen; i++ {
+		for b := range blocks {
+			blocks[b] = append(blocks[b], stream[pos])
+			pos++
+		}
+	}
+
+	var data []byte
+	for b, block := range blocks {
+		if !syndromesZero(block, eccLen) {
+			return "", fmt.Errorf("block %d fails its Reed-Solomon check", b)
+		}
+		data = append(data, block[:dataLens[b]]...)
+	}
+
+	// Byte mode: 0100, the character count, the bytes, then padding
+	bits := func(start, length int) int {
+		value := 0
+		for i := start; i < start+length; i++ {
+			value = value<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+		return value
+	}
+	if mode := bits(0, 4); mode != 0x4 {
+		return "", fmt.Errorf("mode %04b, want byte mode", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	count := bits(4, countBits)
+	start := 4 + countBits
+	if start+count*8 > len(data)*8 {
+		return "", fmt.Errorf("count %d overruns the data", count)
+	}
+	text := make([]byte, count)
+	for i := range text {
+		text[i] = byte(bits(start+i*8, 8))
+	}
+
+	end := start + count*8
+	if rest := len(data)*8 - end; bits(end, min(4, rest)) != 0 {
+		return "", errors.New("missing terminator")
+	}
+	for i, pad := (end+4+7)/8, byte(0xEC); i < len(data); i, pad = i+1, pad^0xEC^0x11 {
+		if data[i] != pad {
+			return "", fmt.Errorf("pad codeword %d is %02X, want %02X", i, data[i], pad)
+		}
+	}
+	return string(text), nil
+}
+
+// syndromesZero evaluates the block at the generator's roots, 2^0 to
+// 2^(eccLen-1), all of which a valid block has as roots too
+func syndromesZero(block []byte, eccLen int) bool {
+	var exp [255]byte
+	var log [256]int
+	for i, x := 0, 1; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = i
+		if x <<= 1; x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+
+	for r := 0; r < eccLen; r++ {
+		var s byte
+		for _, c := range block {
+			// Horner's rule: s = s*alpha^r + c
+			if s != 0 {
+				s = exp[(log[s]+r)%255]
+			}
+			s ^= c
+		}
+		if s != 0 {
+			return false
+		}
+	}
+	return true
+}